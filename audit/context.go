@@ -0,0 +1,36 @@
+// Package audit carries the metadata of the caller that is recorded with every audit event
+package audit
+
+import "context"
+
+// AnonymousActor is recorded when the request is not authenticated
+const AnonymousActor = "anonymous"
+
+// Meta describes who triggered a change and from where
+type Meta struct {
+	Actor     string
+	RequestID string
+	SourceIP  string
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the audit metadata
+func NewContext(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, contextKey{}, meta)
+}
+
+// FromContext returns the audit metadata stored in ctx, the actor defaults to AnonymousActor
+func FromContext(ctx context.Context) Meta {
+	meta := Meta{Actor: AnonymousActor}
+	if ctx == nil {
+		return meta
+	}
+	if m, ok := ctx.Value(contextKey{}).(Meta); ok {
+		meta = m
+	}
+	if meta.Actor == "" {
+		meta.Actor = AnonymousActor
+	}
+	return meta
+}
@@ -0,0 +1,28 @@
+// Package auth verifies the bearer tokens of API callers and carries the authenticated principal
+package auth
+
+import "context"
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Subject is the sub claim of the token
+	Subject string
+	// Claims holds every claim of the token, sub included
+	Claims map[string]interface{}
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the principal
+func NewContext(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext returns the principal stored in ctx, ok is false for unauthenticated requests
+func FromContext(ctx context.Context) (principal Principal, ok bool) {
+	if ctx == nil {
+		return principal, false
+	}
+	principal, ok = ctx.Value(contextKey{}).(Principal)
+	return principal, ok
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, badly signed, expired or meant for someone else
+var ErrInvalidToken = errors.New("invalid token")
+
+// Keys are the keys tokens may be signed with, an algorithm without a key is rejected
+type Keys struct {
+	// HMACSecret verifies HS256 tokens
+	HMACSecret []byte
+	// RSAKeys verify RS256 tokens by the kid of their header, a token without kid needs a single key
+	RSAKeys map[string]*rsa.PublicKey
+}
+
+// Verifier checks the signature and the registered claims of JWTs
+type Verifier struct {
+	keys   Keys
+	parser *jwt.Parser
+	// Issuer and Audience are the expected iss and aud claims, they are not checked when empty
+	Issuer   string
+	Audience string
+	// Leeway is the clock skew tolerated on exp, nbf and iat
+	Leeway time.Duration
+}
+
+func NewVerifier(keys Keys, issuer string, audience string, leeway time.Duration) (*Verifier, error) {
+	var methods []string
+	if len(keys.HMACSecret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(keys.RSAKeys) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("no key to verify tokens with, configure an HMAC secret or a JWKS file")
+	}
+	return &Verifier{
+		keys: keys,
+		// claims are validated in Verify, with the leeway
+		parser:   &jwt.Parser{ValidMethods: methods, UseJSONNumber: true, SkipClaimsValidation: true},
+		Issuer:   issuer,
+		Audience: audience,
+		Leeway:   leeway,
+	}, nil
+}
+
+// Verify returns the principal of a valid token. The token must expire and name its subject.
+func (v *Verifier) Verify(tokenString string) (Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(tokenString, claims, v.key); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	now, leeway := time.Now().Unix(), int64(v.Leeway/time.Second)
+	switch {
+	case !claims.VerifyExpiresAt(now-leeway, true):
+		return Principal{}, fmt.Errorf("%w: token is expired or has no exp claim", ErrInvalidToken)
+	case !claims.VerifyNotBefore(now+leeway, false):
+		return Principal{}, fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	case !claims.VerifyIssuedAt(now+leeway, false):
+		return Principal{}, fmt.Errorf("%w: token is issued in the future", ErrInvalidToken)
+	case v.Issuer != "" && !claims.VerifyIssuer(v.Issuer, true):
+		return Principal{}, fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	case v.Audience != "" && !claims.VerifyAudience(v.Audience, true):
+		return Principal{}, fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return Principal{}, fmt.Errorf("%w: token has no sub claim", ErrInvalidToken)
+	}
+	return Principal{Subject: subject, Claims: claims}, nil
+}
+
+// key picks the key for the algorithm of the token, ValidMethods already limits the algorithms to the configured ones
+func (v *Verifier) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return v.keys.HMACSecret, nil
+	case *jwt.SigningMethodRSA:
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" && len(v.keys.RSAKeys) == 1 {
+			for _, key := range v.keys.RSAKeys {
+				return key, nil
+			}
+		}
+		if key, ok := v.keys.RSAKeys[kid]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+}
+
+// BearerToken extracts the token of an "Authorization: Bearer <token>" header or metadata value
+func BearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
@@ -0,0 +1,109 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/rahul-024/fund-transfer-poc/auth"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+var secret = []byte("test-secret-0123456789abcdef")
+
+func claims(subject string, expiresIn time.Duration) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": subject,
+		"aud": "fund-transfer",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(expiresIn).Unix(),
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed
+}
+
+// jwks returns a JWKS document with the public key of private
+func jwks(kid string, private *rsa.PrivateKey) []byte {
+	n := base64.RawURLEncoding.EncodeToString(private.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(private.E)).Bytes())
+	return []byte(fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":%q,"use":"sig","alg":"RS256","n":%q,"e":%q},
+		{"kty":"EC","kid":"ec-1","crv":"P-256","x":"","y":""}]}`, kid, n, e))
+}
+
+func TestVerifyHS256(t *testing.T) {
+	verifier, err := auth.NewVerifier(auth.Keys{HMACSecret: secret}, "", "fund-transfer", 0)
+	assert.Equal(t, err, nil)
+
+	principal, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, secret, "", claims("alice", time.Minute)))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, principal.Subject, "alice")
+	assert.Equal(t, principal.Claims["aud"], "fund-transfer")
+
+	for name, token := range map[string]string{
+		"expired":      sign(t, jwt.SigningMethodHS256, secret, "", claims("alice", -time.Minute)),
+		"wrong secret": sign(t, jwt.SigningMethodHS256, []byte("another-secret-0123456789"), "", claims("alice", time.Minute)),
+		"no subject":   sign(t, jwt.SigningMethodHS256, secret, "", claims("", time.Minute)),
+		"no expiry":    sign(t, jwt.SigningMethodHS256, secret, "", jwt.MapClaims{"sub": "alice", "aud": "fund-transfer"}),
+		"audience":     sign(t, jwt.SigningMethodHS256, secret, "", jwt.MapClaims{"sub": "alice", "aud": "other", "exp": time.Now().Add(time.Minute).Unix()}),
+		"alg none":     sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", claims("alice", time.Minute)),
+		"malformed":    "not.a.token",
+	} {
+		if _, err := verifier.Verify(token); !errors.Is(err, auth.ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken for %s token, got %v", name, err)
+		}
+	}
+}
+
+func TestVerifyLeeway(t *testing.T) {
+	verifier, _ := auth.NewVerifier(auth.Keys{HMACSecret: secret}, "", "", time.Minute)
+	_, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, secret, "", claims("alice", -30*time.Second)))
+	assert.Equal(t, err, nil)
+}
+
+func TestVerifyRS256(t *testing.T) {
+	private, _ := rsa.GenerateKey(rand.Reader, 2048)
+	keys, err := auth.ParseJWKS(jwks("key-1", private))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(keys), 1)
+	verifier, err := auth.NewVerifier(auth.Keys{RSAKeys: keys}, "", "fund-transfer", 0)
+	assert.Equal(t, err, nil)
+
+	principal, err := verifier.Verify(sign(t, jwt.SigningMethodRS256, private, "key-1", claims("bob", time.Minute)))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, principal.Subject, "bob")
+	//a single key is used for tokens without kid
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodRS256, private, "", claims("bob", time.Minute)))
+	assert.Equal(t, err, nil)
+
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodRS256, other, "key-1", claims("bob", time.Minute)))
+	assert.Equal(t, errors.Is(err, auth.ErrInvalidToken), true)
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodRS256, private, "key-2", claims("bob", time.Minute)))
+	assert.Equal(t, errors.Is(err, auth.ErrInvalidToken), true)
+	//HS256 is not accepted without a secret, even when signed with the public key
+	_, err = verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte(fmt.Sprint(private.PublicKey)), "key-1", claims("bob", time.Minute)))
+	assert.Equal(t, errors.Is(err, auth.ErrInvalidToken), true)
+}
+
+func TestNewVerifierNeedsAKey(t *testing.T) {
+	_, err := auth.NewVerifier(auth.Keys{}, "", "", 0)
+	assert.NotEqual(t, err, nil)
+	_, err = auth.ParseJWKS([]byte(`{"keys":[{"kty":"RSA","kid":"k","n":"***","e":"AQAB"}]}`))
+	assert.NotEqual(t, err, nil)
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+)
+
+// Headers of a signed request
+const (
+	PartnerHeader   = "X-Partner-Id"
+	TimestampHeader = "X-Signature-Timestamp"
+	NonceHeader     = "X-Signature-Nonce"
+	SignatureHeader = "X-Signature"
+)
+
+const (
+	// maxNonceLength keeps the nonces storable
+	maxNonceLength = 128
+	// minSecretLength keeps partner secrets strong enough for HMAC-SHA256
+	minSecretLength = 16
+)
+
+var (
+	// ErrInvalidSignature is returned for requests of unknown partners, with a stale timestamp or a wrong signature
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrReplayedRequest is returned when the nonce of a correctly signed request was already used
+	ErrReplayedRequest = errors.New("replayed request")
+)
+
+// NonceStore remembers the nonces of signed requests for as long as their timestamp is accepted
+type NonceStore interface {
+	// UseNonce records the nonce of the partner, fresh is false when the partner already used it
+	UseNonce(ctx context.Context, partnerID string, nonce string, expiresAt time.Time) (fresh bool, err error)
+	// DeleteExpiredNonces forgets the nonces that expired before now and returns how many
+	DeleteExpiredNonces(ctx context.Context, now time.Time) (int64, error)
+}
+
+// SignedRequest holds the parts of a request covered by its signature
+type SignedRequest struct {
+	PartnerID string
+	Method    string
+	// URI is the path of the request with its query string
+	URI       string
+	Timestamp string
+	Nonce     string
+	Signature string
+	Body      []byte
+}
+
+// StringToSign returns the canonical form of a request: the method, the URI, the unix timestamp, the nonce
+// and the hex SHA-256 of the body, separated by newlines
+func StringToSign(method string, uri string, timestamp string, nonce string, body []byte) string {
+	sum := sha256.Sum256(body)
+	return strings.Join([]string{strings.ToUpper(method), uri, timestamp, nonce, hex.EncodeToString(sum[:])}, "\n")
+}
+
+// Sign returns the hex HMAC-SHA256 of the string to sign with the secret of the partner
+func Sign(secret []byte, stringToSign string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(stringToSign))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureVerifier checks the HMAC signatures partners send with their requests
+type SignatureVerifier struct {
+	secrets map[string][]byte
+	nonces  NonceStore
+	// MaxSkew is the age, or the lead, tolerated on the timestamps. Nonces are remembered as long.
+	MaxSkew time.Duration
+}
+
+func NewSignatureVerifier(secrets map[string][]byte, nonces NonceStore, maxSkew time.Duration) (*SignatureVerifier, error) {
+	if len(secrets) == 0 {
+		return nil, errors.New("no partner secret to verify signatures with")
+	}
+	for id, secret := range secrets {
+		if len(secret) < minSecretLength {
+			return nil, fmt.Errorf("secret of partner %q must have at least %d characters", id, minSecretLength)
+		}
+	}
+	if maxSkew <= 0 {
+		return nil, errors.New("the clock skew tolerated on signatures must be positive")
+	}
+	return &SignatureVerifier{secrets: secrets, nonces: nonces, MaxSkew: maxSkew}, nil
+}
+
+// Verify returns the id of the partner that signed the request. The nonce is only recorded once the
+// signature is valid, so that nobody can burn the nonces of a partner.
+func (v *SignatureVerifier) Verify(ctx context.Context, req SignedRequest) (string, error) {
+	secret, ok := v.secrets[req.PartnerID]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown partner %q", ErrInvalidSignature, req.PartnerID)
+	}
+	seconds, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: timestamp must be unix seconds", ErrInvalidSignature)
+	}
+	timestamp := time.Unix(seconds, 0)
+	if skew := time.Since(timestamp); skew > v.MaxSkew || skew < -v.MaxSkew {
+		return "", fmt.Errorf("%w: timestamp is off by more than %s", ErrInvalidSignature, v.MaxSkew)
+	}
+	if req.Nonce == "" || len(req.Nonce) > maxNonceLength {
+		return "", fmt.Errorf("%w: nonce must have 1 to %d characters", ErrInvalidSignature, maxNonceLength)
+	}
+	expected := Sign(secret, StringToSign(req.Method, req.URI, req.Timestamp, req.Nonce, req.Body))
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(req.Signature))) {
+		return "", fmt.Errorf("%w: signature does not match", ErrInvalidSignature)
+	}
+	fresh, err := v.nonces.UseNonce(ctx, req.PartnerID, req.Nonce, timestamp.Add(v.MaxSkew))
+	if err != nil {
+		return "", err
+	}
+	if !fresh {
+		return "", fmt.Errorf("%w: nonce %q was already used", ErrReplayedRequest, req.Nonce)
+	}
+	return req.PartnerID, nil
+}
+
+// CleanupNonces deletes the expired nonces every interval until ctx is done
+func CleanupNonces(ctx context.Context, nonces NonceStore, interval time.Duration) {
+	logger.Log.With("interval", interval).Info("nonce cleanup started")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("nonce cleanup stopped")
+			return
+		case <-ticker.C:
+			deleted, err := nonces.DeleteExpiredNonces(ctx, time.Now())
+			if err != nil {
+				logger.Log.With("error", err).Error("nonce cleanup failed")
+				continue
+			}
+			logger.Log.With("deleted", deleted).Debug("nonce cleanup done")
+		}
+	}
+}
+
+// LoadPartnerSecrets reads a JSON file mapping partner ids to their secrets
+func LoadPartnerSecrets(path string) (map[string][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var secrets map[string]string
+	if err = json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("invalid partner secrets: %w", err)
+	}
+	keys := make(map[string][]byte, len(secrets))
+	for id, secret := range secrets {
+		keys[id] = []byte(secret)
+	}
+	return keys, nil
+}
@@ -0,0 +1,90 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/auth"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+// nonces is a NonceStore in memory
+type nonces map[string]time.Time
+
+func (n nonces) UseNonce(_ context.Context, partnerID string, nonce string, expiresAt time.Time) (bool, error) {
+	key := partnerID + "/" + nonce
+	if _, ok := n[key]; ok {
+		return false, nil
+	}
+	n[key] = expiresAt
+	return true, nil
+}
+
+func (n nonces) DeleteExpiredNonces(_ context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	for key, expiresAt := range n {
+		if expiresAt.Before(now) {
+			delete(n, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func signed(secret []byte, timestamp time.Time, nonce string) auth.SignedRequest {
+	req := auth.SignedRequest{
+		PartnerID: "acme",
+		Method:    "POST",
+		URI:       "/api/v1/transfers/",
+		Timestamp: fmt.Sprint(timestamp.Unix()),
+		Nonce:     nonce,
+		Body:      []byte(`{"amount": 10}`),
+	}
+	req.Signature = auth.Sign(secret, auth.StringToSign(req.Method, req.URI, req.Timestamp, req.Nonce, req.Body))
+	return req
+}
+
+func TestVerifySignature(t *testing.T) {
+	store := nonces{}
+	verifier, err := auth.NewSignatureVerifier(map[string][]byte{"acme": secret}, store, time.Minute)
+	assert.Equal(t, err, nil)
+
+	partner, err := verifier.Verify(context.Background(), signed(secret, time.Now(), "n-1"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, partner, "acme")
+	_, err = verifier.Verify(context.Background(), signed(secret, time.Now(), "n-1"))
+	assert.Equal(t, errors.Is(err, auth.ErrReplayedRequest), true)
+
+	tampered := signed(secret, time.Now(), "n-2")
+	tampered.Body = []byte(`{"amount": 1000}`)
+	unknown := signed(secret, time.Now(), "n-3")
+	unknown.PartnerID = "globex"
+	for _, req := range []auth.SignedRequest{
+		tampered,
+		unknown,
+		signed([]byte("another-secret-0123456789"), time.Now(), "n-4"),
+		signed(secret, time.Now().Add(-2*time.Minute), "n-5"),
+		signed(secret, time.Now().Add(2*time.Minute), "n-6"),
+		signed(secret, time.Now(), ""),
+	} {
+		_, err = verifier.Verify(context.Background(), req)
+		assert.Equal(t, errors.Is(err, auth.ErrInvalidSignature), true)
+	}
+	//only the nonces of valid signatures are recorded
+	assert.Equal(t, len(store), 1)
+
+	deleted, _ := store.DeleteExpiredNonces(context.Background(), time.Now().Add(2*time.Minute))
+	assert.Equal(t, deleted, int64(1))
+}
+
+func TestNewSignatureVerifierNeedsStrongSecrets(t *testing.T) {
+	_, err := auth.NewSignatureVerifier(map[string][]byte{}, nonces{}, time.Minute)
+	assert.NotEqual(t, err, nil)
+	_, err = auth.NewSignatureVerifier(map[string][]byte{"acme": []byte("short")}, nonces{}, time.Minute)
+	assert.NotEqual(t, err, nil)
+	_, err = auth.NewSignatureVerifier(map[string][]byte{"acme": secret}, nonces{}, 0)
+	assert.NotEqual(t, err, nil)
+}
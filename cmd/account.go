@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"github.com/rahul-024/fund-transfer-poc/util"
+	"github.com/spf13/cobra"
+)
+
+func newAccountCommand(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "account",
+		Short: "Create and inspect accounts",
+	}
+	cmd.AddCommand(newAccountCreateCommand(a), newAccountShowCommand(a))
+	return cmd
+}
+
+func newAccountCreateCommand(a *app) *cobra.Command {
+	var (
+		owner, currency string
+		openingBalance  float64
+	)
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an account, optionally with an opening balance entry",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !util.IsSupportedCurrency(currency) {
+				return fmt.Errorf("currency %q is not supported", currency)
+			}
+			if openingBalance < 0 {
+				return errors.New("opening balance cannot be negative")
+			}
+			var account models.Account
+			err := a.inTx(cmd.Context(), func(ctx context.Context, as service.AccountService) (err error) {
+				account, err = openAccount(ctx, as, owner, currency, openingBalance)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd.OutOrStdout(), account)
+		},
+	}
+	cmd.Flags().StringVar(&owner, "owner", "", "owner of the account")
+	cmd.Flags().StringVar(&currency, "currency", "", "currency of the account (USD, EUR, CAD)")
+	cmd.Flags().Float64Var(&openingBalance, "opening-balance", 0, "credits the account with an OPENING_BALANCE entry")
+	cmd.MarkFlagRequired("owner")
+	cmd.MarkFlagRequired("currency")
+	return cmd
+}
+
+// openAccount saves the account and posts its opening balance, the returned account carries the new balance
+func openAccount(ctx context.Context, as service.AccountService, owner string, currency string, openingBalance float64) (models.Account, error) {
+	account, err := as.SaveAccount(ctx, models.Account{Owner: owner, Currency: currency})
+	if err != nil || openingBalance == 0 {
+		return account, err
+	}
+	_, err = as.AdjustBalance(ctx, account.Id, &request.BalanceAdjustmentRequest{
+		Amount:     openingBalance,
+		ReasonCode: util.OpeningBalance,
+	})
+	account.Balance += openingBalance
+	return account, err
+}
+
+// accountDetails is what account show prints
+type accountDetails struct {
+	Account   models.Account    `json:"account"`
+	Entries   []models.Entry    `json:"entries"`
+	Transfers []models.Transfer `json:"transfers"`
+}
+
+func newAccountShowCommand(a *app) *cobra.Command {
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "show ID",
+		Short: "Print an account with its latest entries and transfers",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid account id %q", args[0])
+			}
+			as, ctx := a.accounts(), cliContext(cmd.Context())
+			details := accountDetails{}
+			if details.Account, err = as.GetAccountById(ctx, id); err != nil {
+				return err
+			}
+			entries, err := as.GetRecentEntries(ctx, []int{id}, limit, 0)
+			if err != nil {
+				return err
+			}
+			transfers, err := as.GetRecentTransfers(ctx, []int{id}, limit, 0)
+			if err != nil {
+				return err
+			}
+			details.Entries, details.Transfers = entries[id], transfers[id]
+			return printJSON(cmd.OutOrStdout(), details)
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 10, "number of entries and transfers to print")
+	return cmd
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mock "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+// run executes the command line against a mocked service and database, without loading a profile
+func run(t *testing.T, a *app, args ...string) (string, error) {
+	a.configure = func(string) error { return nil }
+	var out bytes.Buffer
+	root := newRootCommand(a)
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs(args)
+	err := root.Execute()
+	return out.String(), err
+}
+
+func setup(t *testing.T) (*app, *mock.MockAccountService) {
+	mockCtrl := gomock.NewController(t)
+	mockAccountService := mock.NewMockAccountService(mockCtrl)
+	return &app{accountService: mockAccountService}, mockAccountService
+}
+
+func TestAccountCreate(t *testing.T) {
+	a, mockAccountService := setup(t)
+	// the account and its opening balance are created in one transaction
+	mockAccountService.EXPECT().RunInTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(service.AccountService) error) error {
+			return fn(mockAccountService)
+		})
+	mockAccountService.EXPECT().SaveAccount(gomock.Any(), models.Account{Owner: "Rahul", Currency: "USD"}).
+		Return(models.Account{Id: 4, Owner: "Rahul", Currency: "USD", Status: "ACTIVE"}, nil)
+	mockAccountService.EXPECT().AdjustBalance(gomock.Any(), 4, &request.BalanceAdjustmentRequest{Amount: 50, ReasonCode: "OPENING_BALANCE"}).
+		Return(models.Entry{Id: 1, AccountID: 4, Amount: 50}, nil)
+	out, err := run(t, a, "account", "create", "--owner", "Rahul", "--currency", "USD", "--opening-balance", "50")
+	assert.Equal(t, err, nil)
+	var account models.Account
+	json.Unmarshal([]byte(out), &account)
+	assert.Equal(t, account.Id, 4)
+	assert.Equal(t, account.Balance, float64(50))
+
+	//Failure case: unsupported currency
+	_, err = run(t, a, "account", "create", "--owner", "Rahul", "--currency", "XYZ")
+	assert.NotEqual(t, err, nil)
+
+}
+
+func TestTransfer(t *testing.T) {
+	a, mockAccountService := setup(t)
+	mockAccountService.EXPECT().
+		CreateTransfer(gomock.Any(), &request.TransferRequest{FromAccountID: 1, ToAccountID: 2, Amount: 12.5, Currency: "USD"}).
+		Return(models.Transfer{Id: 9, FromAccountID: 1, ToAccountID: 2, Amount: 12.5}, nil)
+	out, err := run(t, a, "transfer", "--from", "1", "--to", "2", "--amount", "12.5", "--currency", "USD")
+	assert.Equal(t, err, nil)
+	var transfer models.Transfer
+	json.Unmarshal([]byte(out), &transfer)
+	assert.Equal(t, transfer.Id, 9)
+
+	//Failure case: same account
+	_, err = run(t, a, "transfer", "--from", "1", "--to", "1", "--amount", "5")
+	assert.NotEqual(t, err, nil)
+
+}
+
+func TestReconcile(t *testing.T) {
+	a, mockAccountService := setup(t)
+	mockAccountService.EXPECT().Reconcile(gomock.Any()).Return(nil, nil)
+	out, err := run(t, a, "reconcile")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, out, "all account balances match their entries\n")
+
+	//Failure case: mismatching accounts make the command fail
+	mockAccountService.EXPECT().Reconcile(gomock.Any()).
+		Return([]models.BalanceMismatch{{AccountID: 3, Balance: 100, LedgerBalance: 80}}, nil)
+	_, err = run(t, a, "reconcile")
+	assert.Equal(t, err.Error(), "1 account(s) do not match their entries")
+}
+
+func TestMigrateDownNeedsStepsOrAll(t *testing.T) {
+	_, err := run(t, &app{}, "migrate", "down")
+	assert.Equal(t, err.Error(), "pass either the number of migrations to roll back or --all")
+
+	_, err = run(t, &app{}, "migrate", "down", "2", "--all")
+	assert.Equal(t, err.Error(), "pass either the number of migrations to roll back or --all")
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/rahul-024/fund-transfer-poc/config"
+	"github.com/rahul-024/fund-transfer-poc/db/migration"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/spf13/cobra"
+)
+
+// newMigrate reads the migrations embedded for the dialect of the datasource,
+// or the ones at DbMigrationPath when a profile sets it to try out migrations without rebuilding.
+// SQLite is migrated on the connection of the app so that in-memory databases survive the migration.
+func (a *app) newMigrate(appConfig *config.AppConfig) (*migrate.Migrate, error) {
+	var sqlDB *sql.DB
+	if appConfig.Datasource.DbType == "sqlite" {
+		var err error
+		if sqlDB, err = a.database().DB(); err != nil {
+			return nil, err
+		}
+	}
+	m, err := migration.New(appConfig.Datasource, appConfig.DbMigrationPath, sqlDB)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create new migrate instance: %w", err)
+	}
+	return m, nil
+}
+
+// migrateUp applies all pending migrations
+func (a *app) migrateUp(appConfig *config.AppConfig) error {
+	m, err := a.newMigrate(appConfig)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	if err = m.Up(); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("failed to run migrate up: %w", err)
+	}
+	logger.Log.Info("db migrated successfully")
+	return nil
+}
+
+// verifySchemaVersion fails unless the database is at the version of the newest embedded migration,
+// so that a server does not run against a schema it was not built for
+func (a *app) verifySchemaVersion(appConfig *config.AppConfig) error {
+	expected, err := migration.LatestVersion(appConfig.Datasource.DbType)
+	if err != nil {
+		return err
+	}
+	m, err := a.newMigrate(appConfig)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	version, dirty, err := m.Version()
+	if err == migrate.ErrNilVersion {
+		return fmt.Errorf("database has no schema, this binary expects version %d: run fund-transfer migrate up", expected)
+	}
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("database schema version %d is dirty: fix it and run fund-transfer migrate force", version)
+	}
+	if version != expected {
+		return fmt.Errorf("database schema version %d does not match version %d expected by this binary", version, expected)
+	}
+	return nil
+}
+
+func newMigrateCommand(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply, roll back or inspect the database migrations",
+	}
+	cmd.AddCommand(newMigrateUpCommand(a), newMigrateDownCommand(a), newMigrateVersionCommand(a), newMigrateForceCommand(a))
+	return cmd
+}
+
+func newMigrateUpCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "up [N]",
+		Short: "Apply all pending migrations, or only the next N",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return runMigration(cmd, a, func(m *migrate.Migrate) error { return m.Up() })
+			}
+			steps, err := parseSteps(args[0])
+			if err != nil {
+				return err
+			}
+			return runMigration(cmd, a, func(m *migrate.Migrate) error { return m.Steps(steps) })
+		},
+	}
+}
+
+func newMigrateDownCommand(a *app) *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "down N | --all",
+		Short: "Roll back the last N migrations, or all of them with --all",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) == 1) {
+				return errors.New("pass either the number of migrations to roll back or --all")
+			}
+			if all {
+				return runMigration(cmd, a, func(m *migrate.Migrate) error { return m.Down() })
+			}
+			steps, err := parseSteps(args[0])
+			if err != nil {
+				return err
+			}
+			return runMigration(cmd, a, func(m *migrate.Migrate) error { return m.Steps(-steps) })
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "roll back every migration, this drops all tables")
+	return cmd
+}
+
+func newMigrateVersionCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the current schema version",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigration(cmd, a, func(*migrate.Migrate) error { return nil })
+		},
+	}
+}
+
+func newMigrateForceCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "force VERSION",
+		Short: "Set the schema version without running migrations, to recover from a dirty state",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid version %q", args[0])
+			}
+			return runMigration(cmd, a, func(m *migrate.Migrate) error { return m.Force(version) })
+		},
+	}
+}
+
+func parseSteps(arg string) (int, error) {
+	steps, err := strconv.Atoi(arg)
+	if err != nil || steps < 1 {
+		return 0, fmt.Errorf("invalid number of migrations %q", arg)
+	}
+	return steps, nil
+}
+
+// runMigration runs fn and prints the schema version it left the database at
+func runMigration(cmd *cobra.Command, a *app, fn func(*migrate.Migrate) error) error {
+	m, err := a.newMigrate(&config.AppConf)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	if err = fn(m); err != nil && err != migrate.ErrNoChange {
+		return err
+	}
+	version, dirty, err := m.Version()
+	if err == migrate.ErrNilVersion {
+		fmt.Fprintln(cmd.OutOrStdout(), "no migration applied")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	expected, err := migration.LatestVersion(config.AppConf.Datasource.DbType)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "version %d, dirty: %t, expected by this binary: %d\n", version, dirty, expected)
+	return nil
+}
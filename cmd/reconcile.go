@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newReconcileCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reconcile",
+		Short: "Check that every account balance equals the sum of its ledger entries",
+		Long: "Check that every account balance equals the sum of its ledger entries.\n" +
+			"Mismatching accounts are printed and the command exits with a non zero status, nothing is corrected.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mismatches, err := a.accounts().Reconcile(cliContext(cmd.Context()))
+			if err != nil {
+				return err
+			}
+			if len(mismatches) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "all account balances match their entries")
+				return nil
+			}
+			if err = printJSON(cmd.OutOrStdout(), mismatches); err != nil {
+				return err
+			}
+			return fmt.Errorf("%d account(s) do not match their entries", len(mismatches))
+		},
+	}
+}
@@ -0,0 +1,128 @@
+// Package cmd holds the fund-transfer command line: the server and the maintenance commands for ops
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/user"
+
+	"github.com/pkg/errors"
+	"github.com/rahul-024/fund-transfer-poc/audit"
+	"github.com/rahul-024/fund-transfer-poc/auth"
+	"github.com/rahul-024/fund-transfer-poc/config"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	logFactory "github.com/rahul-024/fund-transfer-poc/loggerfactory"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// app holds what the commands share, the database and the services are created on first use
+// so that commands like migrate do not depend on them
+type app struct {
+	// configure loads the profile and the logger before any command runs
+	configure      func(profile string) error
+	db             *gorm.DB
+	accountService service.AccountService
+}
+
+// Execute runs the command line and exits with a non zero status when the command fails
+func Execute() {
+	if err := NewRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// NewRootCommand builds the fund-transfer command with all its subcommands
+func NewRootCommand() *cobra.Command {
+	return newRootCommand(&app{configure: configure})
+}
+
+func newRootCommand(a *app) *cobra.Command {
+	var profile string
+	root := &cobra.Command{
+		Use:          "fund-transfer",
+		Short:        "Fund transfer service and its maintenance commands",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return a.configure(profile)
+		},
+	}
+	root.PersistentFlags().StringVar(&profile, "profile", config.Profile(),
+		"configuration profile read from profiles/<profile>.yaml, defaults to ENV_PROFILE")
+	root.AddCommand(
+		newServeCommand(a),
+		newMigrateCommand(a),
+		newAccountCommand(a),
+		newTransferCommand(a),
+		newReconcileCommand(a),
+		newSeedCommand(a),
+	)
+	return root
+}
+
+func configure(profile string) error {
+	if err := config.LoadConfig(profile); err != nil {
+		return err
+	}
+	if err := loadLogger(config.AppConf.Log); err != nil {
+		return err
+	}
+	logger.Log.With("profile", profile, "logger", config.AppConf.Log.Code).Info("configuration loaded")
+	return nil
+}
+
+// loads the logger
+func loadLogger(lc config.LogConfig) error {
+	loggerType := lc.Code
+	err := logFactory.GetLogFactoryBuilder(loggerType).Build(&lc)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	return nil
+}
+
+func (a *app) database() *gorm.DB {
+	if a.db == nil {
+		a.db = config.ConnectDatabase(&config.AppConf)
+	}
+	return a.db
+}
+
+func (a *app) accounts() service.AccountService {
+	if a.accountService == nil {
+		db := a.database()
+		a.accountService = service.NewAccountService(config.NewUnitOfWork(db), config.NewAccountRepository(db),
+			config.NewTransferRepository(db), config.NewEntryRepository(db), repository.NewAuditRepository(db), repository.NewOutboxRepository(db),
+			service.NewRolePolicy(), config.TransferIsolation())
+	}
+	return a.accountService
+}
+
+// inTx runs fn with the account service bound to a transaction that is committed when fn returns no error.
+// Changes made from the command line are made as the admin and audited with the operating system user as actor.
+func (a *app) inTx(ctx context.Context, fn func(context.Context, service.AccountService) error) error {
+	ctx = cliContext(ctx)
+	return a.accounts().RunInTx(ctx, func(as service.AccountService) error {
+		return fn(ctx, as)
+	})
+}
+
+// cliContext returns ctx with the system principal of the commands, named after the operating system user
+func cliContext(ctx context.Context) context.Context {
+	actor := "cli"
+	if u, err := user.Current(); err == nil {
+		actor = "cli:" + u.Username
+	}
+	ctx = auth.NewContext(ctx, service.SystemPrincipal(actor))
+	return audit.NewContext(ctx, audit.Meta{Actor: actor})
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+
+	"errors"
+	"fmt"
+
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"github.com/rahul-024/fund-transfer-poc/util"
+	"github.com/spf13/cobra"
+)
+
+var seedCurrencies = []string{util.USD, util.EUR, util.CAD}
+
+func newSeedCommand(a *app) *cobra.Command {
+	var (
+		count          int
+		openingBalance float64
+	)
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Create demo accounts with opening balances, for local and test environments",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if count < 1 {
+				return errors.New("accounts must be at least 1")
+			}
+			accounts := make([]models.Account, 0, count)
+			err := a.inTx(cmd.Context(), func(ctx context.Context, as service.AccountService) error {
+				for i := 0; i < count; i++ {
+					account, err := openAccount(ctx, as, fmt.Sprintf("seed-owner-%d", i+1),
+						seedCurrencies[i%len(seedCurrencies)], openingBalance)
+					if err != nil {
+						return err
+					}
+					accounts = append(accounts, account)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd.OutOrStdout(), accounts)
+		},
+	}
+	cmd.Flags().IntVar(&count, "accounts", 10, "number of accounts to create")
+	cmd.Flags().Float64Var(&openingBalance, "opening-balance", 1000, "opening balance of each account")
+	return cmd
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/rahul-024/fund-transfer-poc/auth"
+	"github.com/rahul-024/fund-transfer-poc/config"
+	"github.com/rahul-024/fund-transfer-poc/grpcapi"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/outbox"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"github.com/rahul-024/fund-transfer-poc/webhook"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+func newServeCommand(a *app) *cobra.Command {
+	var runMigrations bool
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Start the REST and gRPC servers and the background workers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config.WatchConfig()
+			if runMigrations || config.AppConf.AutoMigrate {
+				if err := a.migrateUp(&config.AppConf); err != nil {
+					return err
+				}
+			}
+			if err := a.verifySchemaVersion(&config.AppConf); err != nil {
+				return err
+			}
+			db := a.database()
+			// SIGTERM stops the servers and the background workers, the process exits once they are done
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			var workers sync.WaitGroup
+			runOutboxRelay(ctx, &workers, &config.AppConf, db)
+			runNonceCleanup(ctx, &workers, &config.AppConf, db)
+			if err := runGrpcServer(ctx, &config.AppConf, db, a); err != nil {
+				return err
+			}
+			err := runGinServer(ctx, &config.AppConf, db)
+			stop()
+			workers.Wait()
+			logger.Log.Info("server stopped")
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&runMigrations, "migrate", false, "apply pending migrations before starting, also enabled by autoMigrate in the profile")
+	return cmd
+}
+
+func runGinServer(ctx context.Context, appConfig *config.AppConfig, db *gorm.DB) error {
+	server, err := config.NewServer(db)
+	if err != nil {
+		logger.Log.With("error", err).Error("cannot create server")
+		return err
+	}
+
+	err = server.Start(ctx, appConfig.ServerConfig.HttpServerAddress)
+	if err != nil {
+		logger.Log.With("error", err).Error("cannot start server")
+	}
+	return err
+}
+
+// runGrpcServer serves the gRPC API in the background on its own port, sharing the service layer and the
+// authentication with the REST API
+func runGrpcServer(ctx context.Context, appConfig *config.AppConfig, db *gorm.DB, a *app) error {
+	address := appConfig.ServerConfig.GrpcServerAddress
+	if address == "" {
+		return nil
+	}
+	anonymous := service.SystemPrincipal(config.AnonymousSubject)
+	authenticator := &grpcapi.Authenticator{Anonymous: &anonymous}
+	if appConfig.Auth.Enabled {
+		verifier, err := config.NewVerifier(appConfig.Auth)
+		if err != nil {
+			logger.Log.With("error", err).Error("cannot create grpc authentication")
+			return err
+		}
+		authenticator = &grpcapi.Authenticator{
+			Verifier: verifier,
+			APIKeys:  service.NewAPIKeyService(repository.NewAPIKeyRepository(db), service.NewRolePolicy()),
+		}
+	}
+	server := grpcapi.NewServer(a.accounts(), authenticator)
+	go func() {
+		if err := grpcapi.Serve(ctx, server, address); err != nil {
+			logger.Log.With("error", err).Fatal("cannot start grpc server")
+		}
+	}()
+	return nil
+}
+
+// runOutboxRelay starts draining the outbox in the background when enabled in the profile, until ctx is cancelled
+func runOutboxRelay(ctx context.Context, workers *sync.WaitGroup, appConfig *config.AppConfig, db *gorm.DB) {
+	oc := appConfig.Outbox
+	if !oc.RelayEnabled {
+		return
+	}
+	sink, err := outbox.NewSink(outbox.SinkConfig{
+		Code:     oc.Sink,
+		FilePath: oc.FilePath,
+		HttpUrl:  oc.HttpUrl,
+		Timeout:  oc.HttpTimeout,
+	})
+	if err != nil {
+		logger.Log.With("error", err).Fatal("cannot create outbox sink")
+	}
+	wc := appConfig.Webhook
+	if wc.Enabled {
+		webhookRepository := repository.NewWebhookRepository(db)
+		sink = outbox.NewMultiSink(sink, webhook.NewSink(webhookRepository, repository.NewAccountRepository(db)))
+		dispatcher := webhook.NewDispatcher(webhookRepository, webhook.NewClient(wc.Timeout), webhook.DispatcherConfig{
+			MaxAttempts:    wc.MaxAttempts,
+			InitialBackoff: wc.InitialBackoff,
+			MaxBackoff:     wc.MaxBackoff,
+			PollInterval:   wc.PollInterval,
+			BatchSize:      wc.BatchSize,
+			Lease:          wc.Lease,
+		})
+		runWorker(workers, func() { dispatcher.Run(ctx) })
+	}
+	relay := outbox.NewRelay(repository.NewOutboxRepository(db), sink, outbox.RelayConfig{
+		BatchSize:    oc.BatchSize,
+		PollInterval: oc.PollInterval,
+		MaxAttempts:  oc.MaxAttempts,
+	})
+	runWorker(workers, func() { relay.Run(ctx) })
+}
+
+// runNonceCleanup deletes the expired nonces of signed requests in the background when signing is enabled
+func runNonceCleanup(ctx context.Context, workers *sync.WaitGroup, appConfig *config.AppConfig, db *gorm.DB) {
+	sc := appConfig.Signing
+	if !sc.Enabled || sc.NonceCleanupInterval <= 0 {
+		return
+	}
+	runWorker(workers, func() { auth.CleanupNonces(ctx, repository.NewNonceRepository(db), sc.NonceCleanupInterval) })
+}
+
+// runWorker runs a background worker, the serve command waits for it before exiting
+func runWorker(workers *sync.WaitGroup, run func()) {
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		run()
+	}()
+}
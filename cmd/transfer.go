@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/spf13/cobra"
+)
+
+func newTransferCommand(a *app) *cobra.Command {
+	var req request.TransferRequest
+	cmd := &cobra.Command{
+		Use:   "transfer",
+		Short: "Move money between two accounts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if req.Amount <= 0 {
+				return errors.New("amount must be positive")
+			}
+			if req.FromAccountID == req.ToAccountID {
+				return errors.New("cannot transfer to the same account")
+			}
+			transfer, err := a.accounts().CreateTransfer(cliContext(cmd.Context()), &req)
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd.OutOrStdout(), transfer)
+		},
+	}
+	cmd.Flags().IntVar(&req.FromAccountID, "from", 0, "id of the debited account")
+	cmd.Flags().IntVar(&req.ToAccountID, "to", 0, "id of the credited account")
+	cmd.Flags().Float64Var(&req.Amount, "amount", 0, "amount to transfer")
+	cmd.Flags().StringVar(&req.Currency, "currency", "", "currency of the amount")
+	cmd.MarkFlagRequired("from")
+	cmd.MarkFlagRequired("to")
+	cmd.MarkFlagRequired("amount")
+	return cmd
+}
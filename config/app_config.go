@@ -1,14 +1,28 @@
 package config
 
+import "time"
+
 var AppConf = AppConfig{}
 
 type AppConfig struct {
-	DbMigrationPath string       `mapstructure:"dbMigrationPath"`
-	Datasource      Datasource   `mapstructure:"datasource"`
-	ServerConfig    ServerConfig `mapstructure:"serverConfig"`
-	ZapConfig       LogConfig    `mapstructure:"zapConfig"`
-	LorusConfig     LogConfig    `mapstructure:"logrusConfig"`
-	Log             LogConfig    `mapstructure:"logConfig"`
+	// source URL of the migrations, e.g. file://db/migration/postgres, the ones embedded for the dbType are used when empty
+	DbMigrationPath string     `mapstructure:"dbMigrationPath"`
+	AutoMigrate     bool       `mapstructure:"autoMigrate"`
+	Datasource      Datasource `mapstructure:"datasource"`
+	// implementation of the account, transfer and entry repositories: gorm, the default, or memory to keep accounts,
+	// transfers and entries in memory for demos while the other tables stay in the datasource
+	AccountRepository string            `mapstructure:"accountRepository"`
+	ServerConfig      ServerConfig      `mapstructure:"serverConfig"`
+	ZapConfig         LogConfig         `mapstructure:"zapConfig"`
+	LorusConfig       LogConfig         `mapstructure:"logrusConfig"`
+	ZerologConfig     LogConfig         `mapstructure:"zerologConfig"`
+	Log               LogConfig         `mapstructure:"logConfig"`
+	Outbox            OutboxConfig      `mapstructure:"outboxConfig"`
+	Webhook           WebhookConfig     `mapstructure:"webhookConfig"`
+	Transaction       TransactionConfig `mapstructure:"transactionConfig"`
+	Auth              AuthConfig        `mapstructure:"authConfig"`
+	Signing           SigningConfig     `mapstructure:"signingConfig"`
+	RateLimit         RateLimitConfig   `mapstructure:"rateLimitConfig"`
 }
 
 type Datasource struct {
@@ -18,11 +32,12 @@ type Datasource struct {
 
 type ServerConfig struct {
 	HttpServerAddress string `mapstructure:"httpServerAddress"`
+	// the gRPC API is not started when empty
+	GrpcServerAddress string `mapstructure:"grpcServerAddress"`
 }
 
 // LogConfig represents logger handler
-// Logger has many parameters can be set or changed. Currently, only three are listed here. Can add more into it to
-// fits your needs.
+// Logger has many parameters can be set or changed, every library honours the ones listed here.
 type LogConfig struct {
 	// log library name
 	Code string `mapstructure:"code"`
@@ -30,4 +45,130 @@ type LogConfig struct {
 	Level string `mapstructure:"level"`
 	// show caller in log message
 	EnableCaller bool `mapstructure:"enableCaller"`
+	// encoding of the lines: console, the default, or json
+	Encoding string `mapstructure:"encoding"`
+	// where the lines are written: stdout, stderr or file paths, stdout when empty
+	Outputs []string `mapstructure:"outputs"`
+	// rotation of the file outputs
+	Rotation LogRotationConfig `mapstructure:"rotation"`
+	// sampling of the repeated lines
+	Sampling LogSamplingConfig `mapstructure:"sampling"`
+}
+
+// LogRotationConfig rotates the log files by size and by time, the files are only appended to when both are 0
+type LogRotationConfig struct {
+	// rotate a file once it grows past this size in megabytes, 0 disables the size-based rotation
+	MaxSizeMB int `mapstructure:"maxSizeMB"`
+	// rotate the files this often whatever their size, 0 disables the time-based rotation
+	Interval time.Duration `mapstructure:"interval"`
+	// number of rotated files to keep, 0 keeps all of them
+	MaxBackups int `mapstructure:"maxBackups"`
+	// days a rotated file is kept, 0 keeps them whatever their age
+	MaxAgeDays int `mapstructure:"maxAgeDays"`
+	// gzip the rotated files
+	Compress bool `mapstructure:"compress"`
+}
+
+// LogSamplingConfig caps the lines repeated in a burst: each tick, the first Initial lines of a level and message
+// are written, then every Thereafter-th. Sampling is disabled when Initial is 0.
+type LogSamplingConfig struct {
+	Initial    int           `mapstructure:"initial"`
+	Thereafter int           `mapstructure:"thereafter"`
+	Tick       time.Duration `mapstructure:"tick"`
+}
+
+// OutboxConfig configures the relay that drains the outbox table to a sink
+type OutboxConfig struct {
+	// start the relay goroutine with the server, only one instance per database should run it
+	RelayEnabled bool `mapstructure:"relayEnabled"`
+	// sink code: stdout, file or http
+	Sink         string        `mapstructure:"sink"`
+	FilePath     string        `mapstructure:"filePath"`
+	HttpUrl      string        `mapstructure:"httpUrl"`
+	HttpTimeout  time.Duration `mapstructure:"httpTimeout"`
+	PollInterval time.Duration `mapstructure:"pollInterval"`
+	BatchSize    int           `mapstructure:"batchSize"`
+	// deliveries of an event before it is dead-lettered, 0 retries forever
+	MaxAttempts int `mapstructure:"maxAttempts"`
+}
+
+// TransactionConfig configures the transactions of the account service, transfers and balance adjustments included
+type TransactionConfig struct {
+	// isolation of the transfers and balance adjustments: read committed, repeatable read or serializable,
+	// the default of the database when empty. The other transactions always run with the default.
+	IsolationLevel string `mapstructure:"isolationLevel"`
+	// runs of a transaction aborted by a serialization failure or a deadlock, the first one included
+	MaxAttempts    int           `mapstructure:"maxAttempts"`
+	InitialBackoff time.Duration `mapstructure:"initialBackoff"`
+	MaxBackoff     time.Duration `mapstructure:"maxBackoff"`
+}
+
+// AuthConfig configures the JWT bearer authentication of the REST API, /docs and /health stay open
+type AuthConfig struct {
+	// the API is anonymous when disabled
+	Enabled bool `mapstructure:"enabled"`
+	// shared secret of HS256 tokens, HS256 is rejected when empty
+	HmacSecret string `mapstructure:"hmacSecret"`
+	// local JWKS file with the public keys of RS256 tokens, RS256 is rejected when empty
+	JwksFile string `mapstructure:"jwksFile"`
+	// expected iss and aud claims, not checked when empty
+	Issuer   string `mapstructure:"issuer"`
+	Audience string `mapstructure:"audience"`
+	// clock skew tolerated on exp, nbf and iat
+	Leeway time.Duration `mapstructure:"leeway"`
+}
+
+// SigningConfig configures the HMAC signatures partners send with the transfers they initiate. A partner
+// transfers out of the accounts it owns only, the accounts of owner partner:<id>.
+type SigningConfig struct {
+	// authenticate signed transfer requests as their partner, unsigned ones go through the other authentication
+	Enabled bool `mapstructure:"enabled"`
+	// partner ids and secrets, added to the ones of the secrets file
+	Partners []PartnerConfig `mapstructure:"partners"`
+	// JSON file mapping partner ids to their secrets, for the secrets that must not be in the profile
+	SecretsFile string `mapstructure:"secretsFile"`
+	// skew tolerated on the signature timestamp, nonces are remembered as long
+	MaxClockSkew time.Duration `mapstructure:"maxClockSkew"`
+	// interval of the deletion of expired nonces
+	NonceCleanupInterval time.Duration `mapstructure:"nonceCleanupInterval"`
+}
+
+type PartnerConfig struct {
+	Id     string `mapstructure:"id"`
+	Secret string `mapstructure:"secret"`
+}
+
+// RateLimitConfig configures the token buckets limiting the requests of each client to each route group
+type RateLimitConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// store code of the buckets, memory limits each instance on its own
+	Store string `mapstructure:"store"`
+	// limit of the route groups without their own limit
+	Default RateLimitRule `mapstructure:"default"`
+	// limits by route group: accounts, transfers, audit, webhooks, api-keys, admin and graphql
+	Groups map[string]RateLimitRule `mapstructure:"groups"`
+	// limits by IP address taken before the authentication, by route group, the groups without one are only
+	// limited once authenticated
+	IPGroups map[string]RateLimitRule `mapstructure:"ipGroups"`
+}
+
+// RateLimitRule refills requests every per into a bucket holding up to burst requests
+type RateLimitRule struct {
+	Requests int           `mapstructure:"requests"`
+	Per      time.Duration `mapstructure:"per"`
+	Burst    int           `mapstructure:"burst"`
+}
+
+// WebhookConfig configures the fan-out of outbox events to webhook subscriptions and their delivery
+type WebhookConfig struct {
+	// adds the webhook sink to the outbox relay and starts the dispatcher
+	Enabled        bool          `mapstructure:"enabled"`
+	MaxAttempts    int           `mapstructure:"maxAttempts"`
+	InitialBackoff time.Duration `mapstructure:"initialBackoff"`
+	MaxBackoff     time.Duration `mapstructure:"maxBackoff"`
+	PollInterval   time.Duration `mapstructure:"pollInterval"`
+	Timeout        time.Duration `mapstructure:"timeout"`
+	BatchSize      int           `mapstructure:"batchSize"`
+	// hides the deliveries claimed by a dispatcher from the other instances, it must exceed batchSize * timeout
+	Lease time.Duration `mapstructure:"lease"`
 }
@@ -2,6 +2,13 @@ package config
 
 // constant for logger code, it needs to match log code (logConfig)in configuration
 const (
-	LOGRUS string = "logrus"
-	ZAP    string = "zap"
+	LOGRUS  string = "logrus"
+	ZAP     string = "zap"
+	ZEROLOG string = "zerolog"
+)
+
+// constant for log encoding, it needs to match encoding in logConfig
+const (
+	CONSOLE string = "console"
+	JSON    string = "json"
 )
@@ -1,38 +1,39 @@
 package config
 
 import (
-	"log"
-	"os"
-	"time"
+	"database/sql"
+	"sync"
 
+	"github.com/glebarez/sqlite"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"github.com/rahul-024/fund-transfer-poc/service"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
 var err error
 
 func ConnectDatabase(appConfig *AppConfig) (db *gorm.DB) {
-	newLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
-		logger.Config{
-			SlowThreshold:             time.Second, // Slow SQL threshold
-			LogLevel:                  logger.Info, // Log level
-			IgnoreRecordNotFoundError: true,        // Ignore ErrRecordNotFound error for logger
-			Colorful:                  true,        // enable color
-		},
-	)
 	dsn := appConfig.Datasource.Dsn
 	switch appConfig.Datasource.DbType {
 	case "postgres":
 		DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-			Logger: newLogger,
+			Logger: newGormLogger(),
 		})
 
 	case "mysql":
-		DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+		DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
+			Logger: newGormLogger(),
+		})
+
+	case "sqlite":
+		// dsn is a file name or file::memory:?cache=shared, connections of an in-memory
+		// database only share it with the shared cache
+		DB, err = gorm.Open(sqlite.Open(dsn), &gorm.Config{
+			Logger: newGormLogger(),
+		})
 	}
 	if err != nil {
 		panic("Failed to connect to database")
@@ -42,3 +43,59 @@ func ConnectDatabase(appConfig *AppConfig) (db *gorm.DB) {
 	}
 	return DB
 }
+
+var memoryStore *repository.MemoryStore
+var memoryStoreOnce sync.Once
+
+// ledgerStore returns the store of the in-memory repositories. It is created once, so that the REST and
+// gRPC servers share its data like a database.
+func ledgerStore(db *gorm.DB) *repository.MemoryStore {
+	memoryStoreOnce.Do(func() {
+		memoryStore = repository.NewMemoryStore(db)
+	})
+	return memoryStore
+}
+
+// NewAccountRepository returns the account repository selected by accountRepository in the profile
+func NewAccountRepository(db *gorm.DB) repository.AccountRepository {
+	if AppConf.AccountRepository != "memory" {
+		return repository.NewAccountRepository(db)
+	}
+	return repository.NewMemoryAccountRepository(ledgerStore(db))
+}
+
+// NewTransferRepository returns the transfer repository selected by accountRepository in the profile
+func NewTransferRepository(db *gorm.DB) repository.TransferRepository {
+	if AppConf.AccountRepository != "memory" {
+		return repository.NewTransferRepository(db)
+	}
+	return repository.NewMemoryTransferRepository(ledgerStore(db))
+}
+
+// NewEntryRepository returns the entry repository selected by accountRepository in the profile
+func NewEntryRepository(db *gorm.DB) repository.EntryRepository {
+	if AppConf.AccountRepository != "memory" {
+		return repository.NewEntryRepository(db)
+	}
+	return repository.NewMemoryEntryRepository(ledgerStore(db))
+}
+
+// NewUnitOfWork returns the unit of work of the account service with the retry policy of the profile
+func NewUnitOfWork(db *gorm.DB) service.UnitOfWork {
+	tc := AppConf.Transaction
+	return service.NewUnitOfWork(db, service.RetryPolicy{
+		MaxAttempts:    tc.MaxAttempts,
+		InitialBackoff: tc.InitialBackoff,
+		MaxBackoff:     tc.MaxBackoff,
+	})
+}
+
+// TransferIsolation returns the isolation level of the profile for the transfers and balance adjustments,
+// an unknown isolation level is a configuration error like an unreachable database
+func TransferIsolation() sql.IsolationLevel {
+	isolation, err := service.ParseIsolationLevel(AppConf.Transaction.IsolationLevel)
+	if err != nil {
+		panic(err)
+	}
+	return isolation
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	appLogger "github.com/rahul-024/fund-transfer-poc/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// slowQueryThreshold is the duration from which statements are logged as warnings
+const slowQueryThreshold = time.Second
+
+// gormLogger writes the lines of GORM through logger.Log, so that they share the format and level of the app.
+// Statements are logged at debug level with the request-scoped fields of their context, slow ones as warnings.
+type gormLogger struct {
+	level logger.LogLevel
+}
+
+func newGormLogger() logger.Interface {
+	return gormLogger{level: logger.Info}
+}
+
+func (l gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	l.level = level
+	return l
+}
+
+func (l gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		appLogger.FromContext(ctx).Infof(msg, data...)
+	}
+}
+
+func (l gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		appLogger.FromContext(ctx).Warnf(msg, data...)
+	}
+}
+
+func (l gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		appLogger.FromContext(ctx).Errorf(msg, data...)
+	}
+}
+
+func (l gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	statement := func() appLogger.Logger {
+		sql, rows := fc()
+		return appLogger.FromContext(ctx).With("sql", sql, "rows", rows,
+			"elapsed_ms", float64(elapsed.Nanoseconds())/1e6, "source", utils.FileWithLineNum())
+	}
+	switch {
+	// a missing record is an answer the callers handle, not a failure
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		statement().With("error", err).Error("sql statement failed")
+	case elapsed > slowQueryThreshold && l.level >= logger.Warn:
+		statement().Warn(fmt.Sprintf("slow sql statement, over %s", slowQueryThreshold))
+	case l.level >= logger.Info:
+		statement().Debug("sql statement")
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/spf13/viper"
+)
+
+// DefaultProfile is used when neither the --profile flag nor ENV_PROFILE is set
+const DefaultProfile = "local"
+
+// Profile returns the profile selected by the ENV_PROFILE environment variable
+func Profile() string {
+	profile := os.Getenv("ENV_PROFILE")
+	if len(profile) <= 0 {
+		profile = DefaultProfile
+	}
+	return profile
+}
+
+// LoadConfig reads profiles/<profile>.yaml into AppConf
+func LoadConfig(profile string) error {
+	viper.AddConfigPath("./profiles")
+	viper.SetConfigName(profile)
+	viper.SetConfigType("yaml")
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+	return viper.Unmarshal(&AppConf)
+}
+
+// WatchConfig reloads AppConf whenever the profile file changes and applies the log level of the profile,
+// long running commands call it after LoadConfig
+func WatchConfig() {
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log := logger.Log.With("file", e.Name)
+		log.Info("config file changed")
+		var err error
+		err = viper.ReadInConfig()
+		if err != nil {
+			log.With("error", err).Error("config file reload failed")
+			return
+		}
+		err = viper.Unmarshal(&AppConf)
+		if err != nil {
+			log.With("error", err).Error("config file reload failed")
+			return
+		}
+		// the logger is not rebuilt, only its level follows the profile
+		if err = logger.SetLevel(AppConf.Log.Level); err != nil {
+			log.With("level", AppConf.Log.Level, "error", err).Error("changing the log level failed")
+		}
+	})
+	viper.WatchConfig()
+}
@@ -1,12 +1,20 @@
 package config
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator"
+	"github.com/rahul-024/fund-transfer-poc/auth"
 	_ "github.com/rahul-024/fund-transfer-poc/docs"
+	"github.com/rahul-024/fund-transfer-poc/graph"
 	controller "github.com/rahul-024/fund-transfer-poc/handler"
 	"github.com/rahul-024/fund-transfer-poc/middleware"
+	"github.com/rahul-024/fund-transfer-poc/ratelimit"
 	"github.com/rahul-024/fund-transfer-poc/repository"
 	"github.com/rahul-024/fund-transfer-poc/service"
 	"github.com/rahul-024/fund-transfer-poc/util"
@@ -15,6 +23,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// shutdownTimeout bounds the wait for the requests in flight when the server stops
+const shutdownTimeout = 10 * time.Second
+
+// AnonymousSubject is the subject of the principal the requests run as when authentication is disabled
+const AnonymousSubject = "anonymous"
+
 // Server serves HTTP requests for our banking service.
 type Server struct {
 	router *gin.Engine
@@ -26,35 +40,227 @@ func NewServer(db *gorm.DB) (*Server, error) {
 	}
 
 	server := &Server{}
-	server.setupRouter(db)
+	if err := server.setupRouter(db); err != nil {
+		return nil, err
+	}
 	return server, nil
 }
 
-func (server *Server) setupRouter(db *gorm.DB) {
+// newAuthMiddleware returns the API key and JWT authentication of the API, or a middleware running every
+// request as the anonymous admin when authentication is disabled
+func newAuthMiddleware(ac AuthConfig, apiKeys service.APIKeyService) (gin.HandlerFunc, error) {
+	if !ac.Enabled {
+		return middleware.AnonymousMiddleware(service.SystemPrincipal(AnonymousSubject)), nil
+	}
+	verifier, err := NewVerifier(ac)
+	if err != nil {
+		return nil, err
+	}
+	return middleware.APIKeyAuthMiddleware(apiKeys, middleware.JWTAuthMiddleware(verifier)), nil
+}
+
+// NewVerifier returns the verifier of the bearer tokens with the keys of the profile, shared by the REST and
+// gRPC APIs
+func NewVerifier(ac AuthConfig) (*auth.Verifier, error) {
+	keys := auth.Keys{HMACSecret: []byte(ac.HmacSecret)}
+	if ac.JwksFile != "" {
+		var err error
+		if keys.RSAKeys, err = auth.LoadJWKS(ac.JwksFile); err != nil {
+			return nil, fmt.Errorf("loading JWKS %s: %w", ac.JwksFile, err)
+		}
+	}
+	return auth.NewVerifier(keys, ac.Issuer, ac.Audience, ac.Leeway)
+}
+
+// newSignatureVerifier returns the verifier of the partner signatures with the secrets of the profile and of
+// the secrets file, nil when signing is disabled
+func newSignatureVerifier(sc SigningConfig, db *gorm.DB) (*auth.SignatureVerifier, error) {
+	if !sc.Enabled {
+		return nil, nil
+	}
+	secrets := map[string][]byte{}
+	if sc.SecretsFile != "" {
+		var err error
+		if secrets, err = auth.LoadPartnerSecrets(sc.SecretsFile); err != nil {
+			return nil, fmt.Errorf("loading partner secrets %s: %w", sc.SecretsFile, err)
+		}
+	}
+	for _, partner := range sc.Partners {
+		secrets[partner.Id] = []byte(partner.Secret)
+	}
+	return auth.NewSignatureVerifier(secrets, repository.NewNonceRepository(db), sc.MaxClockSkew)
+}
+
+// newRateLimiter returns the rate limiting middlewares of a route group, by caller after the authentication and
+// by IP address before it, or middlewares letting every request through when rate limiting is disabled
+func newRateLimiter(rc RateLimitConfig) (func(group string) gin.HandlerFunc, func(group string) gin.HandlerFunc, error) {
+	pass := func(string) gin.HandlerFunc { return func(c *gin.Context) { c.Next() } }
+	if !rc.Enabled {
+		return pass, pass, nil
+	}
+	store, err := ratelimit.NewStore(rc.Store)
+	if err != nil {
+		return nil, nil, err
+	}
+	limits, err := rateLimits(rc.Groups)
+	if err != nil {
+		return nil, nil, err
+	}
+	ipLimits, err := rateLimits(rc.IPGroups)
+	if err != nil {
+		return nil, nil, err
+	}
+	fallback := ratelimit.Limit{Requests: rc.Default.Requests, Per: rc.Default.Per, Burst: rc.Default.Burst}
+	if err = fallback.Valid(); err != nil {
+		return nil, nil, fmt.Errorf("default rate limit: %w", err)
+	}
+	byCaller := func(group string) gin.HandlerFunc {
+		limit, ok := limits[group]
+		if !ok {
+			limit = fallback
+		}
+		return middleware.RateLimitMiddleware(store, group, limit)
+	}
+	byIP := func(group string) gin.HandlerFunc {
+		limit, ok := ipLimits[group]
+		if !ok {
+			return pass(group)
+		}
+		return middleware.IPRateLimitMiddleware(store, group, limit)
+	}
+	return byCaller, byIP, nil
+}
+
+// rateLimits returns the valid limits of the route groups
+func rateLimits(rules map[string]RateLimitRule) (map[string]ratelimit.Limit, error) {
+	limits := make(map[string]ratelimit.Limit, len(rules))
+	for group, rule := range rules {
+		limit := ratelimit.Limit{Requests: rule.Requests, Per: rule.Per, Burst: rule.Burst}
+		if err := limit.Valid(); err != nil {
+			return nil, fmt.Errorf("rate limit of %s: %w", group, err)
+		}
+		limits[group] = limit
+	}
+	return limits, nil
+}
+
+func (server *Server) setupRouter(db *gorm.DB) error {
+	policy := service.NewRolePolicy()
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(db), policy)
+	authenticate, err := newAuthMiddleware(AppConf.Auth, apiKeyService)
+	if err != nil {
+		return err
+	}
+	verifier, err := newSignatureVerifier(AppConf.Signing, db)
+	if err != nil {
+		return err
+	}
+	authenticateTransfer := authenticate
+	if verifier != nil {
+		authenticateTransfer = middleware.SignedRequestMiddleware(verifier, authenticate)
+	}
+	limit, limitIP, err := newRateLimiter(AppConf.RateLimit)
+	if err != nil {
+		return err
+	}
 	router := gin.Default()
 	router.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "UP"})
+	})
+	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.AuditContextMiddleware())
+	router.Use(middleware.ErrorMiddleware())
+	router.NoRoute(func(c *gin.Context) {
+		middleware.AbortWithProblem(c, http.StatusNotFound, "ROUTE_NOT_FOUND", "no route for "+c.Request.URL.Path)
+	})
 	var (
-		accountRepository = repository.NewAccountRepository(db)
-		accountService    = service.NewAccountService(accountRepository)
+		accountRepository  = NewAccountRepository(db)
+		transferRepository = NewTransferRepository(db)
+		entryRepository    = NewEntryRepository(db)
+		auditRepository    = repository.NewAuditRepository(db)
+		outboxRepository   = repository.NewOutboxRepository(db)
+		accountService     = service.NewAccountService(NewUnitOfWork(db), accountRepository,
+			transferRepository, entryRepository, auditRepository, outboxRepository, policy, TransferIsolation())
+		webhookRepository = repository.NewWebhookRepository(db)
+		auditService      = service.NewAuditService(auditRepository, policy)
+		webhookService    = service.NewWebhookService(webhookRepository, policy)
 		accountHandler    = controller.NewAccountHandler(accountService)
+		auditHandler      = controller.NewAuditHandler(auditService)
+		webhookHandler    = controller.NewWebhookHandler(webhookService)
+		apiKeyHandler     = controller.NewAPIKeyHandler(apiKeyService)
+		logLevelHandler   = controller.NewLogLevelHandler(service.NewLogLevelService(policy))
+		graphqlHandler    = controller.NewGraphqlHandler(graph.NewSchema(accountService))
 	)
 
-	accounts := router.Group("/api/v1/accounts")
+	accounts := router.Group("/api/v1/accounts", limitIP("accounts"), authenticate, limit("accounts"))
 	{
 		accounts.POST("/", accountHandler.CreateAccount)
 		accounts.GET("/", accountHandler.GetAccounts)
 		accounts.GET("/:id", accountHandler.GetAccountById)
 		accounts.DELETE("/:id", accountHandler.DeleteAccountById)
 		accounts.PUT("/:id", accountHandler.UpdateAccountById)
+		accounts.PATCH("/:id", accountHandler.PatchAccountById)
+		accounts.POST("/:id/adjustments", accountHandler.AdjustBalance)
+		accounts.PUT("/:id/limits", accountHandler.SetTransferLimit)
 	}
 
-	transfers := router.Group("/api/v1/transfers")
+	router.GET("/api/v1/audit", limitIP("audit"), authenticate, limit("audit"), auditHandler.GetAuditEvents)
+
+	webhooks := router.Group("/api/v1/webhooks", limitIP("webhooks"), authenticate, limit("webhooks"))
 	{
-		transfers.POST("/", middleware.DBTransactionMiddleware(db), accountHandler.SaveTransfer)
+		webhooks.POST("/", webhookHandler.CreateSubscription)
+		webhooks.GET("/", webhookHandler.GetSubscriptions)
+		webhooks.GET("/:id", webhookHandler.GetSubscriptionById)
+		webhooks.DELETE("/:id", webhookHandler.DeleteSubscriptionById)
+		webhooks.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+		webhooks.GET("/:id/deliveries/:deliveryId/attempts", webhookHandler.GetDeliveryAttempts)
+		webhooks.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
 	}
+
+	apiKeys := router.Group("/api/v1/api-keys", limitIP("api-keys"), authenticate, limit("api-keys"))
+	{
+		apiKeys.POST("/", apiKeyHandler.CreateAPIKey)
+		apiKeys.GET("/", apiKeyHandler.GetAPIKeys)
+		apiKeys.DELETE("/:id", apiKeyHandler.RevokeAPIKeyById)
+	}
+
+	admin := router.Group("/api/v1/admin", limitIP("admin"), authenticate, limit("admin"))
+	{
+		admin.GET("/log-level", logLevelHandler.GetLogLevel)
+		admin.PUT("/log-level", logLevelHandler.SetLogLevel)
+		admin.DELETE("/log-level", logLevelHandler.ResetLogLevel)
+	}
+
+	// the signatures and nonces of the transfers are checked after the limit by IP address
+	transfers := router.Group("/api/v1/transfers", limitIP("transfers"), authenticateTransfer, limit("transfers"))
+	{
+		transfers.POST("/", accountHandler.SaveTransfer)
+	}
+
+	router.POST("/graphql", limitIP("graphql"), authenticate, limit("graphql"), graphqlHandler.Query)
 	server.router = router
+	return nil
 }
 
-func (server *Server) Start(address string) error {
-	return server.router.Run(address)
+// Handler returns the router so that the server can be mounted on another listener or an httptest server
+func (server *Server) Handler() http.Handler {
+	return server.router
+}
+
+// Start serves the API on address until ctx is cancelled, then waits for the requests in flight
+func (server *Server) Start(ctx context.Context, address string) error {
+	httpServer := &http.Server{Addr: address, Handler: server.router}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.ListenAndServe()
+	}()
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	}
 }
@@ -0,0 +1,556 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/rahul-024/fund-transfer-poc/auth"
+	"github.com/rahul-024/fund-transfer-poc/config"
+	"github.com/rahul-024/fund-transfer-poc/db/migration"
+	logFactory "github.com/rahul-024/fund-transfer-poc/loggerfactory"
+	"github.com/rahul-024/fund-transfer-poc/middleware"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/util"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+// newTestServer starts the server on a migrated in-memory sqlite database private to the test
+func newTestServer(t *testing.T) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	lc := config.LogConfig{Code: "zap", Level: "error"}
+	if err := logFactory.GetLogFactoryBuilder(lc.Code).Build(&lc); err != nil {
+		t.Fatalf("Failed to build logger: %v", err)
+	}
+	ds := config.Datasource{DbType: "sqlite", Dsn: fmt.Sprintf("file:%s?mode=memory&cache=shared&_pragma=foreign_keys(1)", t.Name())}
+	db := config.ConnectDatabase(&config.AppConfig{Datasource: ds})
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	m, err := migration.New(ds, "", sqlDB)
+	if err != nil {
+		t.Fatalf("Failed to create migrate instance: %v", err)
+	}
+	if err = m.Up(); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	server, err := config.NewServer(db)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// call sends body as JSON and decodes the data of the response into out
+func call(t *testing.T, method string, url string, body interface{}, out interface{}) int {
+	var reader bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&reader).Encode(body)
+	}
+	req, _ := http.NewRequest(method, url, &reader)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer res.Body.Close()
+	if out != nil {
+		json.NewDecoder(res.Body).Decode(&struct {
+			Data interface{} `json:"data"`
+		}{Data: out})
+	}
+	return res.StatusCode
+}
+
+func TestTransferEndToEnd(t *testing.T) {
+	testTransfer(t, newTestServer(t))
+}
+
+// TestTransferEndToEndInMemory keeps accounts, transfers and entries in memory and the audit and outbox in sqlite
+func TestTransferEndToEndInMemory(t *testing.T) {
+	config.AppConf.AccountRepository = "memory"
+	defer func() { config.AppConf.AccountRepository = "" }()
+	testTransfer(t, newTestServer(t))
+}
+
+func testTransfer(t *testing.T, ts *httptest.Server) {
+	var alice, bob models.Account
+	assert.Equal(t, call(t, http.MethodPost, ts.URL+"/api/v1/accounts/", map[string]string{"owner": "alice", "currency": "USD"}, &alice), http.StatusCreated)
+	assert.Equal(t, call(t, http.MethodPost, ts.URL+"/api/v1/accounts/", map[string]string{"owner": "bob", "currency": "USD"}, &bob), http.StatusCreated)
+	assert.NotEqual(t, alice.Id, bob.Id)
+
+	var entry models.Entry
+	status := call(t, http.MethodPost, fmt.Sprintf("%s/api/v1/accounts/%d/adjustments", ts.URL, alice.Id),
+		map[string]interface{}{"amount": 100, "reason_code": util.OpeningBalance}, &entry)
+	assert.Equal(t, status, http.StatusCreated)
+	assert.Equal(t, entry.AccountID, alice.Id)
+
+	var transfer models.Transfer
+	status = call(t, http.MethodPost, ts.URL+"/api/v1/transfers/",
+		map[string]interface{}{"from_account_id": alice.Id, "to_account_id": bob.Id, "amount": 40, "currency": "USD"}, &transfer)
+	assert.Equal(t, status, http.StatusCreated)
+	assert.NotEqual(t, transfer.Id, 0)
+
+	assert.Equal(t, call(t, http.MethodGet, fmt.Sprintf("%s/api/v1/accounts/%d", ts.URL, alice.Id), nil, &alice), http.StatusOK)
+	assert.Equal(t, alice.Balance, float64(60))
+	assert.Equal(t, call(t, http.MethodGet, fmt.Sprintf("%s/api/v1/accounts/%d", ts.URL, bob.Id), nil, &bob), http.StatusOK)
+	assert.Equal(t, bob.Balance, float64(40))
+
+	var accounts []models.Account
+	assert.Equal(t, call(t, http.MethodGet, ts.URL+"/api/v1/accounts/?owner=bob", nil, &accounts), http.StatusOK)
+	assert.Equal(t, len(accounts), 1)
+	assert.Equal(t, accounts[0].Id, bob.Id)
+}
+
+func TestAdjustmentCannotOverdraw(t *testing.T) {
+	ts := newTestServer(t)
+
+	var alice models.Account
+	call(t, http.MethodPost, ts.URL+"/api/v1/accounts/", map[string]string{"owner": "alice", "currency": "USD"}, &alice)
+	adjustments := fmt.Sprintf("%s/api/v1/accounts/%d/adjustments", ts.URL, alice.Id)
+
+	status := call(t, http.MethodPost, adjustments, map[string]interface{}{"amount": 10, "reason_code": util.OpeningBalance}, nil)
+	assert.Equal(t, status, http.StatusCreated)
+	status = call(t, http.MethodPost, adjustments, map[string]interface{}{"amount": -20, "reason_code": util.Correction}, nil)
+	assert.Equal(t, status, http.StatusUnprocessableEntity)
+
+	call(t, http.MethodGet, fmt.Sprintf("%s/api/v1/accounts/%d", ts.URL, alice.Id), nil, &alice)
+	assert.Equal(t, alice.Balance, float64(10))
+}
+
+func TestErrorsAreProblems(t *testing.T) {
+	ts := newTestServer(t)
+
+	var alice, bob models.Account
+	call(t, http.MethodPost, ts.URL+"/api/v1/accounts/", map[string]string{"owner": "alice", "currency": "USD"}, &alice)
+	call(t, http.MethodPost, ts.URL+"/api/v1/accounts/", map[string]string{"owner": "bob", "currency": "USD"}, &bob)
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		body   interface{}
+		status int
+		code   string
+	}{
+		{http.MethodGet, "/api/v1/accounts/999", nil, http.StatusNotFound, "ACCOUNT_NOT_FOUND"},
+		{http.MethodGet, "/api/v1/accounts/abc", nil, http.StatusBadRequest, "INVALID_REQUEST"},
+		{http.MethodPost, "/api/v1/accounts/", map[string]string{"owner": "carol", "currency": "XYZ"}, http.StatusBadRequest, "INVALID_REQUEST"},
+		{http.MethodPut, fmt.Sprintf("/api/v1/accounts/%d", bob.Id), map[string]string{"owner": "bob", "currency": "XYZ"},
+			http.StatusBadRequest, "INVALID_REQUEST"},
+		{http.MethodPost, "/api/v1/transfers/", map[string]interface{}{"from_account_id": alice.Id, "to_account_id": bob.Id, "amount": 40},
+			http.StatusUnprocessableEntity, "INSUFFICIENT_FUNDS"},
+		{http.MethodPost, "/api/v1/transfers/", map[string]interface{}{"from_account_id": alice.Id, "to_account_id": 999, "amount": 40},
+			http.StatusNotFound, "ACCOUNT_NOT_FOUND"},
+		{http.MethodGet, "/api/v1/nowhere", nil, http.StatusNotFound, "ROUTE_NOT_FOUND"},
+	} {
+		var body bytes.Buffer
+		if tc.body != nil {
+			json.NewEncoder(&body).Encode(tc.body)
+		}
+		req, _ := http.NewRequest(tc.method, ts.URL+tc.path, &body)
+		req.Header.Set("Content-Type", "application/json")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", tc.method, tc.path, err)
+		}
+		var problem middleware.Problem
+		json.NewDecoder(res.Body).Decode(&problem)
+		res.Body.Close()
+		assert.Equal(t, res.StatusCode, tc.status)
+		assert.Equal(t, res.Header.Get("Content-Type"), middleware.ProblemContentType)
+		assert.Equal(t, problem.Status, tc.status)
+		assert.Equal(t, problem.Code, tc.code)
+	}
+}
+
+func TestBearerAuthentication(t *testing.T) {
+	config.AppConf.Auth = config.AuthConfig{Enabled: true, HmacSecret: "test-secret-0123456789abcdef", Audience: "fund-transfer"}
+	defer func() { config.AppConf.Auth = config.AuthConfig{} }()
+	ts := newTestServer(t)
+
+	send := func(method string, path string, token string) *http.Response {
+		req, _ := http.NewRequest(method, ts.URL+path, bytes.NewBufferString(`{"owner": "alice", "currency": "USD"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		t.Cleanup(func() { res.Body.Close() })
+		return res
+	}
+	sign := func(claims jwt.MapClaims) string {
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.AppConf.Auth.HmacSecret))
+		return token
+	}
+	valid := sign(jwt.MapClaims{"sub": "alice", "aud": "fund-transfer", "roles": "teller", "exp": time.Now().Add(time.Minute).Unix()})
+
+	for _, token := range []string{"", "garbage",
+		sign(jwt.MapClaims{"sub": "alice", "aud": "fund-transfer", "exp": time.Now().Add(-time.Minute).Unix()}),
+		sign(jwt.MapClaims{"sub": "alice", "aud": "other", "exp": time.Now().Add(time.Minute).Unix()}),
+	} {
+		res := send(http.MethodPost, "/api/v1/accounts/", token)
+		var problem middleware.Problem
+		json.NewDecoder(res.Body).Decode(&problem)
+		assert.Equal(t, res.StatusCode, http.StatusUnauthorized)
+		assert.Equal(t, res.Header.Get("WWW-Authenticate"), `Bearer realm="fund-transfer"`)
+		assert.Equal(t, problem.Code, "UNAUTHENTICATED")
+	}
+	assert.Equal(t, send(http.MethodGet, "/health", "").StatusCode, http.StatusOK)
+	assert.Equal(t, send(http.MethodPost, "/api/v1/accounts/", valid).StatusCode, http.StatusCreated)
+
+	//the subject of the token is the actor of the audit events
+	res := send(http.MethodGet, "/api/v1/audit?actor=alice", valid)
+	var events []models.AuditEvent
+	json.NewDecoder(res.Body).Decode(&struct {
+		Data interface{} `json:"data"`
+	}{Data: &events})
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.Equal(t, len(events), 1)
+	assert.Equal(t, events[0].Actor, "alice")
+}
+
+func TestAuthorization(t *testing.T) {
+	config.AppConf.Auth = config.AuthConfig{Enabled: true, HmacSecret: "test-secret-0123456789abcdef"}
+	defer func() { config.AppConf.Auth = config.AuthConfig{} }()
+	ts := newTestServer(t)
+
+	token := func(subject string, role string) string {
+		claims := jwt.MapClaims{"sub": subject, "roles": []string{role}, "exp": time.Now().Add(time.Minute).Unix()}
+		signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.AppConf.Auth.HmacSecret))
+		return signed
+	}
+	var (
+		alice  = token("alice", "customer")
+		teller = token("tina", "teller")
+		admin  = token("ada", "admin")
+	)
+	send := func(token string, method string, path string, body interface{}) int {
+		var reader bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&reader).Encode(body)
+		}
+		req, _ := http.NewRequest(method, ts.URL+path, &reader)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+	// accounts 1 and 2 belong to alice and bob, account 3 is opened by alice below
+	assert.Equal(t, send(teller, http.MethodPost, "/api/v1/accounts/", map[string]string{"owner": "alice", "currency": "USD"}), http.StatusCreated)
+	assert.Equal(t, send(teller, http.MethodPost, "/api/v1/accounts/", map[string]string{"owner": "bob", "currency": "USD"}), http.StatusCreated)
+	assert.Equal(t, send(teller, http.MethodPost, "/api/v1/accounts/1/adjustments", map[string]interface{}{"amount": 100, "reason_code": util.OpeningBalance}), http.StatusCreated)
+
+	for _, tc := range []struct {
+		token  string
+		method string
+		path   string
+		body   interface{}
+		status int
+	}{
+		{alice, http.MethodPost, "/api/v1/accounts/", map[string]string{"owner": "alice", "currency": "USD"}, http.StatusCreated},
+		{alice, http.MethodPost, "/api/v1/accounts/", map[string]string{"owner": "bob", "currency": "USD"}, http.StatusForbidden},
+		{alice, http.MethodGet, "/api/v1/accounts/1", nil, http.StatusOK},
+		{alice, http.MethodGet, "/api/v1/accounts/2", nil, http.StatusForbidden},
+		{alice, http.MethodGet, "/api/v1/accounts/", nil, http.StatusOK},
+		{alice, http.MethodGet, "/api/v1/accounts/?owner=bob", nil, http.StatusForbidden},
+		{alice, http.MethodPatch, "/api/v1/accounts/1", map[string]string{"owner": "carol"}, http.StatusForbidden},
+		{alice, http.MethodPost, "/api/v1/accounts/1/adjustments", map[string]interface{}{"amount": 5, "reason_code": util.Correction}, http.StatusForbidden},
+		{alice, http.MethodPost, "/api/v1/transfers/", map[string]interface{}{"from_account_id": 1, "to_account_id": 2, "amount": 10}, http.StatusCreated},
+		{alice, http.MethodPost, "/api/v1/transfers/", map[string]interface{}{"from_account_id": 2, "to_account_id": 1, "amount": 1}, http.StatusForbidden},
+		{alice, http.MethodDelete, "/api/v1/accounts/1", nil, http.StatusForbidden},
+		{alice, http.MethodGet, "/api/v1/audit", nil, http.StatusForbidden},
+		{alice, http.MethodGet, "/api/v1/webhooks/", nil, http.StatusForbidden},
+		{alice, http.MethodGet, "/api/v1/admin/log-level", nil, http.StatusForbidden},
+		{alice, http.MethodPut, "/api/v1/accounts/1/limits", map[string]interface{}{"limit": 1000}, http.StatusForbidden},
+
+		{teller, http.MethodGet, "/api/v1/accounts/2", nil, http.StatusOK},
+		{teller, http.MethodGet, "/api/v1/accounts/?owner=bob", nil, http.StatusOK},
+		{teller, http.MethodPatch, "/api/v1/accounts/3", map[string]string{"owner": "carol"}, http.StatusOK},
+		{teller, http.MethodPost, "/api/v1/accounts/2/adjustments", map[string]interface{}{"amount": 5, "reason_code": util.Correction}, http.StatusCreated},
+		{teller, http.MethodPost, "/api/v1/transfers/", map[string]interface{}{"from_account_id": 2, "to_account_id": 1, "amount": 1}, http.StatusCreated},
+		{teller, http.MethodDelete, "/api/v1/accounts/3", nil, http.StatusForbidden},
+		{teller, http.MethodGet, "/api/v1/audit", nil, http.StatusOK},
+		{teller, http.MethodGet, "/api/v1/webhooks/", nil, http.StatusForbidden},
+		{teller, http.MethodPut, "/api/v1/admin/log-level", map[string]string{"level": "debug"}, http.StatusForbidden},
+		{teller, http.MethodPut, "/api/v1/accounts/1/limits", map[string]interface{}{"limit": 50}, http.StatusForbidden},
+
+		{admin, http.MethodGet, "/api/v1/accounts/2", nil, http.StatusOK},
+		{admin, http.MethodPost, "/api/v1/transfers/", map[string]interface{}{"from_account_id": 2, "to_account_id": 1, "amount": 1}, http.StatusCreated},
+		{admin, http.MethodGet, "/api/v1/audit", nil, http.StatusOK},
+		{admin, http.MethodGet, "/api/v1/webhooks/", nil, http.StatusOK},
+		{admin, http.MethodGet, "/api/v1/admin/log-level", nil, http.StatusOK},
+		{admin, http.MethodDelete, "/api/v1/accounts/3", nil, http.StatusOK},
+		{admin, http.MethodPut, "/api/v1/accounts/1/limits", map[string]interface{}{"limit": -1}, http.StatusBadRequest},
+		{admin, http.MethodPut, "/api/v1/accounts/1/limits", map[string]interface{}{"limit": 50}, http.StatusOK},
+		{alice, http.MethodPost, "/api/v1/transfers/", map[string]interface{}{"from_account_id": 1, "to_account_id": 2, "amount": 60}, http.StatusUnprocessableEntity},
+		{alice, http.MethodPost, "/api/v1/transfers/", map[string]interface{}{"from_account_id": 1, "to_account_id": 2, "amount": 50}, http.StatusCreated},
+	} {
+		if status := send(tc.token, tc.method, tc.path, tc.body); status != tc.status {
+			t.Errorf("Expected %d for %s %s, got %d", tc.status, tc.method, tc.path, status)
+		}
+	}
+
+	//customers only see their own accounts
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/accounts/", nil)
+	req.Header.Set("Authorization", "Bearer "+alice)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Listing accounts failed: %v", err)
+	}
+	defer res.Body.Close()
+	var page models.AccountPage
+	json.NewDecoder(res.Body).Decode(&page)
+	assert.Equal(t, len(page.Data), 1)
+	assert.Equal(t, page.Data[0].Owner, "alice")
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	config.AppConf.Auth = config.AuthConfig{Enabled: true, HmacSecret: "test-secret-0123456789abcdef"}
+	defer func() { config.AppConf.Auth = config.AuthConfig{} }()
+	ts := newTestServer(t)
+
+	claims := jwt.MapClaims{"sub": "ada", "roles": "admin", "exp": time.Now().Add(time.Minute).Unix()}
+	admin, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.AppConf.Auth.HmacSecret))
+	send := func(header string, credentials string, method string, path string, body interface{}, out interface{}) int {
+		var reader bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&reader).Encode(body)
+		}
+		req, _ := http.NewRequest(method, ts.URL+path, &reader)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(header, credentials)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		defer res.Body.Close()
+		if out != nil {
+			json.NewDecoder(res.Body).Decode(&struct {
+				Data interface{} `json:"data"`
+			}{Data: out})
+		}
+		return res.StatusCode
+	}
+	bearer := "Bearer " + admin
+
+	var key models.APIKey
+	status := send("Authorization", bearer, http.MethodPost, "/api/v1/api-keys/",
+		map[string]interface{}{"name": "batch", "scopes": []string{"accounts:read"}, "owner": "alice"}, &key)
+	assert.Equal(t, status, http.StatusCreated)
+	assert.NotEqual(t, key.Key, "")
+	status = send("Authorization", bearer, http.MethodPost, "/api/v1/api-keys/",
+		map[string]interface{}{"name": "batch", "scopes": []string{"accounts:delete"}, "owner": "alice"}, nil)
+	assert.Equal(t, status, http.StatusBadRequest)
+	assert.Equal(t, send("Authorization", bearer, http.MethodPost, "/api/v1/accounts/", map[string]string{"owner": "alice", "currency": "USD"}, nil), http.StatusCreated)
+	assert.Equal(t, send("Authorization", bearer, http.MethodPost, "/api/v1/accounts/", map[string]string{"owner": "bob", "currency": "USD"}, nil), http.StatusCreated)
+
+	//the key is granted its scopes only, on the accounts of its owner
+	assert.Equal(t, send(middleware.APIKeyHeader, key.Key, http.MethodGet, "/api/v1/accounts/1", nil, nil), http.StatusOK)
+	assert.Equal(t, send(middleware.APIKeyHeader, key.Key, http.MethodGet, "/api/v1/accounts/2", nil, nil), http.StatusForbidden)
+	assert.Equal(t, send(middleware.APIKeyHeader, key.Key, http.MethodPost, "/api/v1/accounts/", map[string]string{"owner": "bob", "currency": "USD"}, nil), http.StatusForbidden)
+	assert.Equal(t, send(middleware.APIKeyHeader, key.Key, http.MethodGet, "/api/v1/api-keys/", nil, nil), http.StatusForbidden)
+	assert.Equal(t, send(middleware.APIKeyHeader, "ftk_unknown", http.MethodGet, "/api/v1/accounts/1", nil, nil), http.StatusUnauthorized)
+
+	var keys []models.APIKey
+	assert.Equal(t, send("Authorization", bearer, http.MethodGet, "/api/v1/api-keys/", nil, &keys), http.StatusOK)
+	assert.Equal(t, len(keys), 1)
+	assert.Equal(t, keys[0].Key, "")
+	assert.Equal(t, keys[0].LastUsedAt != nil, true)
+
+	assert.Equal(t, send("Authorization", bearer, http.MethodDelete, fmt.Sprintf("/api/v1/api-keys/%d", key.Id), nil, nil), http.StatusOK)
+	assert.Equal(t, send(middleware.APIKeyHeader, key.Key, http.MethodGet, "/api/v1/accounts/1", nil, nil), http.StatusUnauthorized)
+	assert.Equal(t, send("Authorization", bearer, http.MethodDelete, "/api/v1/api-keys/99", nil, nil), http.StatusNotFound)
+}
+
+func TestSignedTransfers(t *testing.T) {
+	config.AppConf.Signing = config.SigningConfig{Enabled: true, MaxClockSkew: time.Minute,
+		Partners: []config.PartnerConfig{{Id: "acme", Secret: "acme-secret-0123456789"}}}
+	defer func() { config.AppConf.Signing = config.SigningConfig{} }()
+	ts := newTestServer(t)
+
+	var acme, bob models.Account
+	call(t, http.MethodPost, ts.URL+"/api/v1/accounts/", map[string]string{"owner": "partner:acme", "currency": "USD"}, &acme)
+	call(t, http.MethodPost, ts.URL+"/api/v1/accounts/", map[string]string{"owner": "bob", "currency": "USD"}, &bob)
+	call(t, http.MethodPost, fmt.Sprintf("%s/api/v1/accounts/%d/adjustments", ts.URL, acme.Id),
+		map[string]interface{}{"amount": 100, "reason_code": util.OpeningBalance}, nil)
+
+	body := []byte(fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, acme.Id, bob.Id))
+	send := func(secret string, timestamp time.Time, nonce string) (int, string) {
+		unix := fmt.Sprint(timestamp.Unix())
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/api/v1/transfers/", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(auth.PartnerHeader, "acme")
+		req.Header.Set(auth.TimestampHeader, unix)
+		req.Header.Set(auth.NonceHeader, nonce)
+		req.Header.Set(auth.SignatureHeader, auth.Sign([]byte(secret), auth.StringToSign(http.MethodPost, "/api/v1/transfers/", unix, nonce, body)))
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /api/v1/transfers/ failed: %v", err)
+		}
+		defer res.Body.Close()
+		var problem middleware.Problem
+		json.NewDecoder(res.Body).Decode(&problem)
+		return res.StatusCode, problem.Code
+	}
+	secret := config.AppConf.Signing.Partners[0].Secret
+
+	status, _ := send(secret, time.Now(), "n-1")
+	assert.Equal(t, status, http.StatusCreated)
+	for _, rejected := range []struct {
+		secret    string
+		timestamp time.Time
+		nonce     string
+	}{
+		{secret, time.Now(), "n-1"},
+		{secret, time.Now().Add(-2 * time.Minute), "n-2"},
+		{"wrong-secret-0123456789", time.Now(), "n-3"},
+	} {
+		status, code := send(rejected.secret, rejected.timestamp, rejected.nonce)
+		assert.Equal(t, status, http.StatusUnauthorized)
+		assert.Equal(t, code, "INVALID_SIGNATURE")
+	}
+	//the nonce of a rejected signature stays usable
+	status, _ = send(secret, time.Now(), "n-3")
+	assert.Equal(t, status, http.StatusCreated)
+
+	//a partner only debits the accounts it owns
+	body = []byte(fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, bob.Id, acme.Id))
+	status, code := send(secret, time.Now(), "n-4")
+	assert.Equal(t, status, http.StatusForbidden)
+	assert.Equal(t, code, "FORBIDDEN")
+
+	//the partner is the actor of the audit events
+	var events []models.AuditEvent
+	assert.Equal(t, call(t, http.MethodGet, ts.URL+"/api/v1/audit?actor=partner:acme", nil, &events), http.StatusOK)
+	assert.Equal(t, len(events) > 0, true)
+}
+
+func TestRateLimiting(t *testing.T) {
+	config.AppConf.RateLimit = config.RateLimitConfig{Enabled: true,
+		Default: config.RateLimitRule{Requests: 100, Per: time.Second, Burst: 100},
+		Groups:  map[string]config.RateLimitRule{"transfers": {Requests: 1, Per: time.Minute, Burst: 2}}}
+	defer func() { config.AppConf.RateLimit = config.RateLimitConfig{} }()
+	ts := newTestServer(t)
+
+	transfer := func() *http.Response {
+		res, err := http.Post(ts.URL+"/api/v1/transfers/", "application/json", bytes.NewBufferString(`{}`))
+		if err != nil {
+			t.Fatalf("POST /api/v1/transfers/ failed: %v", err)
+		}
+		t.Cleanup(func() { res.Body.Close() })
+		return res
+	}
+	res := transfer()
+	assert.Equal(t, res.StatusCode, http.StatusBadRequest)
+	assert.Equal(t, res.Header.Get("RateLimit-Limit"), "2")
+	assert.Equal(t, res.Header.Get("RateLimit-Remaining"), "1")
+	assert.Equal(t, transfer().StatusCode, http.StatusBadRequest)
+
+	res = transfer()
+	var problem middleware.Problem
+	json.NewDecoder(res.Body).Decode(&problem)
+	assert.Equal(t, res.StatusCode, http.StatusTooManyRequests)
+	assert.Equal(t, problem.Code, "RATE_LIMITED")
+	assert.Equal(t, res.Header.Get("RateLimit-Remaining"), "0")
+	assert.Equal(t, res.Header.Get("Retry-After"), "60")
+
+	//the other route groups have their own limit
+	assert.Equal(t, call(t, http.MethodGet, ts.URL+"/api/v1/accounts/", nil, nil), http.StatusOK)
+}
+
+func TestRateLimitingBeforeAuthentication(t *testing.T) {
+	config.AppConf.Auth = config.AuthConfig{Enabled: true, HmacSecret: "test-secret-0123456789abcdef"}
+	config.AppConf.RateLimit = config.RateLimitConfig{Enabled: true,
+		Default:  config.RateLimitRule{Requests: 100, Per: time.Second, Burst: 100},
+		IPGroups: map[string]config.RateLimitRule{"transfers": {Requests: 1, Per: time.Minute, Burst: 2}}}
+	defer func() {
+		config.AppConf.Auth = config.AuthConfig{}
+		config.AppConf.RateLimit = config.RateLimitConfig{}
+	}()
+	ts := newTestServer(t)
+
+	transfer := func() *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/api/v1/transfers/", bytes.NewBufferString(`{}`))
+		req.Header.Set("Authorization", "Bearer garbage")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /api/v1/transfers/ failed: %v", err)
+		}
+		t.Cleanup(func() { res.Body.Close() })
+		return res
+	}
+	//requests with bad credentials count against the limit of their address
+	assert.Equal(t, transfer().StatusCode, http.StatusUnauthorized)
+	assert.Equal(t, transfer().StatusCode, http.StatusUnauthorized)
+	res := transfer()
+	assert.Equal(t, res.StatusCode, http.StatusTooManyRequests)
+	assert.Equal(t, res.Header.Get("Retry-After"), "60")
+
+	//the route groups without a limit by address are only limited once authenticated
+	assert.Equal(t, call(t, http.MethodGet, ts.URL+"/api/v1/accounts/", nil, nil), http.StatusUnauthorized)
+}
+
+func TestRequestID(t *testing.T) {
+	ts := newTestServer(t)
+
+	send := func(requestID string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/api/v1/accounts/", bytes.NewBufferString(`{"owner": "alice", "currency": "USD"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if requestID != "" {
+			req.Header.Set(middleware.RequestIDHeader, requestID)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /api/v1/accounts/ failed: %v", err)
+		}
+		t.Cleanup(func() { res.Body.Close() })
+		return res
+	}
+	//the id of the client is echoed, a missing or invalid one is replaced
+	assert.Equal(t, send("req-42").Header.Get(middleware.RequestIDHeader), "req-42")
+	assert.Equal(t, len(send("").Header.Get(middleware.RequestIDHeader)), 32)
+	assert.Equal(t, len(send("bad id").Header.Get(middleware.RequestIDHeader)), 32)
+
+	//and recorded with the audit events of the request
+	var events []models.AuditEvent
+	assert.Equal(t, call(t, http.MethodGet, ts.URL+"/api/v1/audit?request_id=req-42", nil, &events), http.StatusOK)
+	assert.Equal(t, len(events), 1)
+	assert.Equal(t, events[0].RequestID, "req-42")
+}
+
+func TestLogLevel(t *testing.T) {
+	ts := newTestServer(t)
+	url := ts.URL + "/api/v1/admin/log-level"
+
+	var level models.LogLevel
+	assert.Equal(t, call(t, http.MethodGet, url, nil, &level), http.StatusOK)
+	assert.Equal(t, level, models.LogLevel{Level: "error", ConfiguredLevel: "error"})
+
+	//the override is in effect until its TTL expires
+	assert.Equal(t, call(t, http.MethodPut, url, map[string]interface{}{"level": "debug", "ttl_seconds": 60}, &level), http.StatusOK)
+	assert.Equal(t, level.Level, "debug")
+	assert.Equal(t, level.ConfiguredLevel, "error")
+	assert.Equal(t, level.OverrideUntil != nil && level.OverrideUntil.After(time.Now().Add(50*time.Second)), true)
+	assert.Equal(t, call(t, http.MethodPut, url, map[string]interface{}{"level": "verbose"}, nil), http.StatusBadRequest)
+	assert.Equal(t, call(t, http.MethodPut, url, map[string]interface{}{"level": "info", "ttl_seconds": 86401}, nil), http.StatusBadRequest)
+
+	//and can be ended before
+	level = models.LogLevel{}
+	assert.Equal(t, call(t, http.MethodDelete, url, nil, &level), http.StatusOK)
+	assert.Equal(t, level, models.LogLevel{Level: "error", ConfiguredLevel: "error"})
+}
@@ -0,0 +1,176 @@
+// Package migratesqlite is a golang-migrate database driver for SQLite on top of the pure Go driver used by gorm.
+// The sqlite driver shipped with golang-migrate registers modernc.org/sqlite under the same database/sql name
+// as github.com/glebarez/go-sqlite, so both cannot be linked into one binary.
+package migratesqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	_ "github.com/glebarez/go-sqlite"
+	"github.com/golang-migrate/migrate/v4/database"
+)
+
+// MigrationsTable holds the schema version, as for the other golang-migrate drivers
+const MigrationsTable = "schema_migrations"
+
+func init() {
+	database.Register("sqlite", &Sqlite{})
+}
+
+// Sqlite implements database.Driver
+type Sqlite struct {
+	db *sql.DB
+	// ownsDB is false for instances created with WithInstance, whose database is closed by its owner
+	ownsDB   bool
+	isLocked atomic.Bool
+}
+
+// WithInstance migrates an already open database, which is required for in-memory databases
+// that only exist as long as their connection
+func WithInstance(db *sql.DB) (database.Driver, error) {
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+	s := &Sqlite{db: db}
+	if err := s.ensureVersionTable(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Open accepts sqlite://<dsn> where dsn is passed on to the sqlite driver unchanged
+func (s *Sqlite) Open(url string) (database.Driver, error) {
+	db, err := sql.Open("sqlite", strings.TrimPrefix(url, "sqlite://"))
+	if err != nil {
+		return nil, err
+	}
+	driver, err := WithInstance(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	driver.(*Sqlite).ownsDB = true
+	return driver, nil
+}
+
+func (s *Sqlite) ensureVersionTable() error {
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`, MigrationsTable)
+	if _, err := s.db.Exec(query); err != nil {
+		return &database.Error{OrigErr: err, Query: []byte(query)}
+	}
+	return nil
+}
+
+func (s *Sqlite) Close() error {
+	if !s.ownsDB {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Lock only guards against concurrent use of this instance, SQLite itself serializes writers
+func (s *Sqlite) Lock() error {
+	if !s.isLocked.CompareAndSwap(false, true) {
+		return database.ErrLocked
+	}
+	return nil
+}
+
+func (s *Sqlite) Unlock() error {
+	if !s.isLocked.CompareAndSwap(true, false) {
+		return database.ErrNotLocked
+	}
+	return nil
+}
+
+// Run executes a migration file in a transaction, the file may contain several statements
+func (s *Sqlite) Run(migration io.Reader) error {
+	query, err := io.ReadAll(migration)
+	if err != nil {
+		return err
+	}
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(string(query)); err != nil {
+			return &database.Error{OrigErr: err, Query: query}
+		}
+		return nil
+	})
+}
+
+func (s *Sqlite) SetVersion(version int, dirty bool) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		query := "DELETE FROM " + MigrationsTable
+		if _, err := tx.Exec(query); err != nil {
+			return &database.Error{OrigErr: err, Query: []byte(query)}
+		}
+		// a failed down migration of the first migration is recorded as a dirty nil version
+		if version >= 0 || (version == database.NilVersion && dirty) {
+			query = fmt.Sprintf(`INSERT INTO %s (version, dirty) VALUES (?, ?)`, MigrationsTable)
+			if _, err := tx.Exec(query, version, dirty); err != nil {
+				return &database.Error{OrigErr: err, Query: []byte(query)}
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Sqlite) Version() (version int, dirty bool, err error) {
+	query := "SELECT version, dirty FROM " + MigrationsTable + " LIMIT 1"
+	err = s.db.QueryRow(query).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.NilVersion, false, nil
+	}
+	if err != nil {
+		return 0, false, &database.Error{OrigErr: err, Query: []byte(query)}
+	}
+	return version, dirty, nil
+}
+
+// Drop removes every table, including the version table
+func (s *Sqlite) Drop() error {
+	query := `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'`
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return &database.Error{OrigErr: err, Query: []byte(query)}
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err = rows.Scan(&table); err != nil {
+			rows.Close()
+			return err
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		query = fmt.Sprintf(`DROP TABLE IF EXISTS "%s"`, table)
+		if _, err = s.db.Exec(query); err != nil {
+			return &database.Error{OrigErr: err, Query: []byte(query)}
+		}
+	}
+	return nil
+}
+
+func (s *Sqlite) inTx(fn func(*sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return &database.Error{OrigErr: err, Err: "transaction start failed"}
+	}
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return &database.Error{OrigErr: err, Err: "transaction commit failed"}
+	}
+	return nil
+}
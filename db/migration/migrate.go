@@ -0,0 +1,74 @@
+package migration
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/mysql"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/rahul-024/fund-transfer-poc/config"
+	"github.com/rahul-024/fund-transfer-poc/db/migratesqlite"
+)
+
+// New returns a migrate instance for the datasource reading the migrations embedded for its dbType,
+// or the ones at sourceURL when set. SQLite is migrated through db, the connection pool gorm uses,
+// because an in-memory database only lives as long as its connections. It is ignored for the other types.
+func New(ds config.Datasource, sourceURL string, db *sql.DB) (*migrate.Migrate, error) {
+	if ds.DbType == "sqlite" {
+		driver, err := migratesqlite.WithInstance(db)
+		if err != nil {
+			return nil, err
+		}
+		if sourceURL != "" {
+			return migrate.NewWithDatabaseInstance(sourceURL, "sqlite", driver)
+		}
+		source, err := iofsSource(ds.DbType)
+		if err != nil {
+			return nil, err
+		}
+		return migrate.NewWithInstance("iofs", source, "sqlite", driver)
+	}
+
+	databaseURL, err := DatabaseURL(ds)
+	if err != nil {
+		return nil, err
+	}
+	if sourceURL != "" {
+		return migrate.New(sourceURL, databaseURL)
+	}
+	source, err := iofsSource(ds.DbType)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.NewWithSourceInstance("iofs", source, databaseURL)
+}
+
+func iofsSource(dbType string) (source.Driver, error) {
+	files, err := Files(dbType)
+	if err != nil {
+		return nil, err
+	}
+	return iofs.New(files, ".")
+}
+
+// DatabaseURL turns the datasource into the URL golang-migrate selects its driver by.
+// The mysql dsn of gorm has no scheme, the migrate driver adds multiStatements to it by itself.
+func DatabaseURL(ds config.Datasource) (string, error) {
+	switch ds.DbType {
+	case "postgres":
+		return ds.Dsn, nil
+	case "mysql":
+		if strings.HasPrefix(ds.Dsn, "mysql://") {
+			return ds.Dsn, nil
+		}
+		return "mysql://" + ds.Dsn, nil
+	case "sqlite":
+		return "sqlite://" + ds.Dsn, nil
+	}
+	return "", fmt.Errorf("migrations are not supported for database type %q", ds.DbType)
+}
@@ -0,0 +1,52 @@
+// Package migration embeds the SQL migrations of every supported database, one folder per dialect,
+// so that the binary can migrate a database without the files on disk
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+//go:embed postgres/*.sql mysql/*.sql sqlite/*.sql
+var files embed.FS
+
+// Files returns the migrations of the dialect, dbType is the dbType of the datasource
+func Files(dbType string) (fs.FS, error) {
+	entries, err := fs.ReadDir(files, dbType)
+	if err != nil || len(entries) == 0 {
+		return nil, fmt.Errorf("no migrations for database type %q", dbType)
+	}
+	return fs.Sub(files, dbType)
+}
+
+// LatestVersion returns the version of the newest migration of the dialect,
+// which is the schema version this binary expects once all migrations are applied
+func LatestVersion(dbType string) (uint, error) {
+	dir, err := Files(dbType)
+	if err != nil {
+		return 0, err
+	}
+	entries, err := fs.ReadDir(dir, ".")
+	if err != nil {
+		return 0, err
+	}
+	var latest uint64
+	for _, entry := range entries {
+		prefix, _, found := strings.Cut(path.Base(entry.Name()), "_")
+		if !found {
+			continue
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("migration %s does not start with a version: %w", entry.Name(), err)
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+	return uint(latest), nil
+}
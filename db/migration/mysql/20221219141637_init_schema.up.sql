@@ -0,0 +1,22 @@
+CREATE TABLE `accounts` (
+  `id` bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `currency` varchar(255) NOT NULL,
+  `owner` varchar(255) NOT NULL,
+  `balance` double NOT NULL,
+  `created_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+);
+
+CREATE TABLE `entries` (
+  `id` bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `account_id` bigint NOT NULL,
+  `amount` bigint NOT NULL,
+  `created_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+);
+
+CREATE TABLE `transfers` (
+  `id` bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `from_account_id` bigint NOT NULL,
+  `to_account_id` bigint NOT NULL,
+  `amount` bigint NOT NULL,
+  `created_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+);
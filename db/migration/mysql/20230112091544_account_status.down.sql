@@ -0,0 +1,5 @@
+DROP INDEX `accounts_created_at_id_idx` ON `accounts`;
+
+DROP INDEX `accounts_owner_idx` ON `accounts`;
+
+ALTER TABLE `accounts` DROP COLUMN `status`;
@@ -0,0 +1,21 @@
+CREATE TABLE `audit_events` (
+  `id` bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `actor` varchar(255) NOT NULL,
+  `request_id` varchar(255) NOT NULL DEFAULT '',
+  `operation` varchar(255) NOT NULL,
+  `entity_type` varchar(255) NOT NULL,
+  `entity_id` bigint NOT NULL,
+  `before` json,
+  `after` json,
+  `source_ip` varchar(255) NOT NULL DEFAULT '',
+  `created_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  INDEX `audit_events_entity_type_entity_id_idx` (`entity_type`, `entity_id`),
+  INDEX `audit_events_actor_idx` (`actor`),
+  INDEX `audit_events_created_at_idx` (`created_at`)
+);
+
+CREATE TRIGGER `audit_events_append_only_update` BEFORE UPDATE ON `audit_events`
+  FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_events is append-only';
+
+CREATE TRIGGER `audit_events_append_only_delete` BEFORE DELETE ON `audit_events`
+  FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_events is append-only';
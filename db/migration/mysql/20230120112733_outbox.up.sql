@@ -0,0 +1,14 @@
+CREATE TABLE `outbox` (
+  `id` bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `event_type` varchar(255) NOT NULL,
+  `schema_version` int NOT NULL,
+  `aggregate_type` varchar(255) NOT NULL,
+  `aggregate_id` bigint NOT NULL,
+  `payload` json NOT NULL,
+  `attempts` int NOT NULL DEFAULT 0,
+  `last_error` text NOT NULL,
+  `published_at` datetime(6),
+  `created_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  -- MySQL has no partial indexes, the pending events are found through published_at IS NULL
+  INDEX `outbox_pending_idx` (`published_at`, `id`)
+);
@@ -0,0 +1,38 @@
+CREATE TABLE `webhook_subscriptions` (
+  `id` bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `url` varchar(2048) NOT NULL,
+  `event_types` text NOT NULL,
+  `secret` varchar(255) NOT NULL,
+  `active` boolean NOT NULL DEFAULT true,
+  `created_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+);
+
+CREATE TABLE `webhook_deliveries` (
+  `id` bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `subscription_id` bigint NOT NULL,
+  `outbox_event_id` bigint NOT NULL,
+  `event_type` varchar(255) NOT NULL,
+  `payload` json NOT NULL,
+  `status` varchar(255) NOT NULL,
+  `attempts` int NOT NULL DEFAULT 0,
+  `next_attempt_at` datetime(6) NOT NULL,
+  `last_status_code` int NOT NULL DEFAULT 0,
+  `last_error` text NOT NULL,
+  `delivered_at` datetime(6),
+  `created_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  UNIQUE (`subscription_id`, `outbox_event_id`),
+  -- MySQL has no partial indexes, the due deliveries are found through the status
+  INDEX `webhook_deliveries_due_idx` (`status`, `next_attempt_at`),
+  FOREIGN KEY (`subscription_id`) REFERENCES `webhook_subscriptions` (`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE `webhook_delivery_attempts` (
+  `id` bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `delivery_id` bigint NOT NULL,
+  `status_code` int NOT NULL DEFAULT 0,
+  `error` text NOT NULL,
+  `duration_ms` bigint NOT NULL,
+  `created_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  INDEX `webhook_delivery_attempts_delivery_id_idx` (`delivery_id`),
+  FOREIGN KEY (`delivery_id`) REFERENCES `webhook_deliveries` (`id`) ON DELETE CASCADE
+);
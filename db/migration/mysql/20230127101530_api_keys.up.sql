@@ -0,0 +1,11 @@
+CREATE TABLE `api_keys` (
+  `id` bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `name` varchar(255) NOT NULL,
+  `prefix` varchar(255) NOT NULL,
+  `key_hash` varchar(64) NOT NULL UNIQUE,
+  `scopes` text NOT NULL,
+  `created_by` varchar(255) NOT NULL DEFAULT '',
+  `last_used_at` datetime(6),
+  `revoked_at` datetime(6),
+  `created_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+);
@@ -0,0 +1,9 @@
+CREATE TABLE `request_nonces` (
+  `id` bigint NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `partner_id` varchar(255) NOT NULL,
+  `nonce` varchar(128) NOT NULL,
+  `expires_at` datetime(6) NOT NULL,
+  `created_at` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  UNIQUE (`partner_id`, `nonce`),
+  INDEX `request_nonces_expires_at_idx` (`expires_at`)
+);
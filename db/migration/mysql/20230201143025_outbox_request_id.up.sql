@@ -0,0 +1 @@
+ALTER TABLE `outbox` ADD COLUMN `request_id` varchar(255) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- largest amount of one transfer out of the account, 0 for no limit
+ALTER TABLE `accounts` ADD COLUMN `transfer_limit` double NOT NULL DEFAULT 0;
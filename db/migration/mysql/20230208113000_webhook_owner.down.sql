@@ -0,0 +1,2 @@
+ALTER TABLE `outbox` DROP COLUMN `owner`;
+ALTER TABLE `webhook_subscriptions` DROP COLUMN `owner`;
@@ -0,0 +1 @@
+ALTER TABLE "entries" ADD COLUMN "reason_code" varchar NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'ACTIVE';
+
+CREATE INDEX ON "accounts" ("owner");
+
+CREATE INDEX ON "accounts" ("created_at", "id");
@@ -0,0 +1,14 @@
+CREATE TABLE "outbox" (
+  "id" bigserial PRIMARY KEY,
+  "event_type" varchar NOT NULL,
+  "schema_version" int NOT NULL,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" bigint NOT NULL,
+  "payload" jsonb NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "published_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "outbox_pending_idx" ON "outbox" ("id") WHERE "published_at" IS NULL;
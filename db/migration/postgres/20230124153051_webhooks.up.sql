@@ -0,0 +1,37 @@
+CREATE TABLE "webhook_subscriptions" (
+  "id" bigserial PRIMARY KEY,
+  "url" varchar NOT NULL,
+  "event_types" text NOT NULL,
+  "secret" varchar NOT NULL,
+  "active" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial PRIMARY KEY,
+  "subscription_id" bigint NOT NULL REFERENCES "webhook_subscriptions" ("id") ON DELETE CASCADE,
+  "outbox_event_id" bigint NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" varchar NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "next_attempt_at" timestamptz NOT NULL,
+  "last_status_code" int NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "delivered_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("subscription_id", "outbox_event_id")
+);
+
+CREATE INDEX "webhook_deliveries_due_idx" ON "webhook_deliveries" ("next_attempt_at") WHERE "status" = 'PENDING';
+
+CREATE TABLE "webhook_delivery_attempts" (
+  "id" bigserial PRIMARY KEY,
+  "delivery_id" bigint NOT NULL REFERENCES "webhook_deliveries" ("id") ON DELETE CASCADE,
+  "status_code" int NOT NULL DEFAULT 0,
+  "error" varchar NOT NULL DEFAULT '',
+  "duration_ms" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "webhook_delivery_attempts" ("delivery_id");
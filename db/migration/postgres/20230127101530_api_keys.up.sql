@@ -0,0 +1,11 @@
+CREATE TABLE "api_keys" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "prefix" varchar NOT NULL,
+  "key_hash" varchar NOT NULL UNIQUE,
+  "scopes" text NOT NULL,
+  "created_by" varchar NOT NULL DEFAULT '',
+  "last_used_at" timestamptz,
+  "revoked_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
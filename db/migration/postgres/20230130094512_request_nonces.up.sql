@@ -0,0 +1,10 @@
+CREATE TABLE "request_nonces" (
+  "id" bigserial PRIMARY KEY,
+  "partner_id" varchar NOT NULL,
+  "nonce" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("partner_id", "nonce")
+);
+
+CREATE INDEX ON "request_nonces" ("expires_at");
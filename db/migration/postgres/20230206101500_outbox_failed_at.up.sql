@@ -0,0 +1,5 @@
+ALTER TABLE "outbox" ADD COLUMN "failed_at" timestamptz;
+
+-- dead-lettered events are no longer pending
+DROP INDEX IF EXISTS "outbox_pending_idx";
+CREATE INDEX "outbox_pending_idx" ON "outbox" ("id") WHERE "published_at" IS NULL AND "failed_at" IS NULL;
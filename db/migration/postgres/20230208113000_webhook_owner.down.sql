@@ -0,0 +1,2 @@
+ALTER TABLE "outbox" DROP COLUMN IF EXISTS "owner";
+ALTER TABLE "webhook_subscriptions" DROP COLUMN IF EXISTS "owner";
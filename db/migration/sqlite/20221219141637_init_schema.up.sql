@@ -0,0 +1,22 @@
+CREATE TABLE "accounts" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "currency" varchar NOT NULL,
+  "owner" varchar NOT NULL,
+  "balance" float NOT NULL,
+  "created_at" datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE "entries" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "created_at" datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE "transfers" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "created_at" datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
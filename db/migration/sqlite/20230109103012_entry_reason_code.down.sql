@@ -0,0 +1 @@
+ALTER TABLE "entries" DROP COLUMN "reason_code";
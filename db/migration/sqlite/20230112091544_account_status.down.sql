@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "accounts_created_at_id_idx";
+
+DROP INDEX IF EXISTS "accounts_owner_idx";
+
+ALTER TABLE "accounts" DROP COLUMN "status";
@@ -0,0 +1,5 @@
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'ACTIVE';
+
+CREATE INDEX "accounts_owner_idx" ON "accounts" ("owner");
+
+CREATE INDEX "accounts_created_at_id_idx" ON "accounts" ("created_at", "id");
@@ -0,0 +1,28 @@
+CREATE TABLE "audit_events" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "actor" varchar NOT NULL,
+  "request_id" varchar NOT NULL DEFAULT '',
+  "operation" varchar NOT NULL,
+  "entity_type" varchar NOT NULL,
+  "entity_id" bigint NOT NULL,
+  "before" text,
+  "after" text,
+  "source_ip" varchar NOT NULL DEFAULT '',
+  "created_at" datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "audit_events_entity_type_entity_id_idx" ON "audit_events" ("entity_type", "entity_id");
+
+CREATE INDEX "audit_events_actor_idx" ON "audit_events" ("actor");
+
+CREATE INDEX "audit_events_created_at_idx" ON "audit_events" ("created_at");
+
+CREATE TRIGGER "audit_events_append_only_update" BEFORE UPDATE ON "audit_events"
+BEGIN
+  SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+CREATE TRIGGER "audit_events_append_only_delete" BEFORE DELETE ON "audit_events"
+BEGIN
+  SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
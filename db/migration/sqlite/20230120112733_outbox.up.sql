@@ -0,0 +1,14 @@
+CREATE TABLE "outbox" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "event_type" varchar NOT NULL,
+  "schema_version" int NOT NULL,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" bigint NOT NULL,
+  "payload" text NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "published_at" datetime,
+  "created_at" datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "outbox_pending_idx" ON "outbox" ("id") WHERE "published_at" IS NULL;
@@ -0,0 +1,37 @@
+CREATE TABLE "webhook_subscriptions" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "url" varchar NOT NULL,
+  "event_types" text NOT NULL,
+  "secret" varchar NOT NULL,
+  "active" boolean NOT NULL DEFAULT true,
+  "created_at" datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE "webhook_deliveries" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "subscription_id" bigint NOT NULL REFERENCES "webhook_subscriptions" ("id") ON DELETE CASCADE,
+  "outbox_event_id" bigint NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" text NOT NULL,
+  "status" varchar NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "next_attempt_at" datetime NOT NULL,
+  "last_status_code" int NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "delivered_at" datetime,
+  "created_at" datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE ("subscription_id", "outbox_event_id")
+);
+
+CREATE INDEX "webhook_deliveries_due_idx" ON "webhook_deliveries" ("next_attempt_at") WHERE "status" = 'PENDING';
+
+CREATE TABLE "webhook_delivery_attempts" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "delivery_id" bigint NOT NULL REFERENCES "webhook_deliveries" ("id") ON DELETE CASCADE,
+  "status_code" int NOT NULL DEFAULT 0,
+  "error" varchar NOT NULL DEFAULT '',
+  "duration_ms" bigint NOT NULL,
+  "created_at" datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "webhook_delivery_attempts_delivery_id_idx" ON "webhook_delivery_attempts" ("delivery_id");
@@ -0,0 +1,11 @@
+CREATE TABLE "api_keys" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "name" varchar NOT NULL,
+  "prefix" varchar NOT NULL,
+  "key_hash" varchar NOT NULL UNIQUE,
+  "scopes" text NOT NULL,
+  "created_by" varchar NOT NULL DEFAULT '',
+  "last_used_at" datetime,
+  "revoked_at" datetime,
+  "created_at" datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
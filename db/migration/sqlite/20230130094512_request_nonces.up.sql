@@ -0,0 +1,10 @@
+CREATE TABLE "request_nonces" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "partner_id" varchar NOT NULL,
+  "nonce" varchar NOT NULL,
+  "expires_at" datetime NOT NULL,
+  "created_at" datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE ("partner_id", "nonce")
+);
+
+CREATE INDEX "request_nonces_expires_at_idx" ON "request_nonces" ("expires_at");
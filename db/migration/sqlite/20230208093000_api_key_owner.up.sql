@@ -0,0 +1,3 @@
+-- keys without the teller role act on the accounts of their owner only
+ALTER TABLE "api_keys" ADD COLUMN "owner" varchar NOT NULL DEFAULT '';
+ALTER TABLE "api_keys" ADD COLUMN "role" varchar NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- subscriptions with an owner only receive the events of the accounts of that owner
+ALTER TABLE "webhook_subscriptions" ADD COLUMN "owner" varchar NOT NULL DEFAULT '';
+ALTER TABLE "outbox" ADD COLUMN "owner" varchar NOT NULL DEFAULT '';
@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/dataloader/v7"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/service"
+)
+
+// batchWait is how long a loader collects keys before running its batch query
+const batchWait = 5 * time.Millisecond
+
+// relationArgs are the pagination arguments of a relation, each distinct combination gets its own loader
+// because the limit is applied per account inside the batch query
+type relationArgs struct {
+	first  int
+	before int
+}
+
+// loaders batch the lookups made while resolving one request so that a list of accounts
+// costs one query per relation instead of one per account
+type loaders struct {
+	accountService service.AccountService
+	accounts       *dataloader.Loader[int, models.Account]
+
+	mu        sync.Mutex
+	transfers map[relationArgs]*dataloader.Loader[int, []models.Transfer]
+	entries   map[relationArgs]*dataloader.Loader[int, []models.Entry]
+}
+
+type loadersKey struct{}
+
+func newLoaders(accountService service.AccountService) *loaders {
+	l := &loaders{
+		accountService: accountService,
+		transfers:      map[relationArgs]*dataloader.Loader[int, []models.Transfer]{},
+		entries:        map[relationArgs]*dataloader.Loader[int, []models.Entry]{},
+	}
+	l.accounts = dataloader.NewBatchedLoader(l.loadAccounts, dataloader.WithWait[int, models.Account](batchWait))
+	return l
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+func (l *loaders) loadAccounts(ctx context.Context, ids []int) []*dataloader.Result[models.Account] {
+	results := make([]*dataloader.Result[models.Account], len(ids))
+	accounts, err := l.accountService.GetAccountsByIds(ctx, ids)
+	byID := make(map[int]models.Account, len(accounts))
+	for _, account := range accounts {
+		byID[account.Id] = account
+	}
+	for i, id := range ids {
+		account, ok := byID[id]
+		switch {
+		case err != nil:
+			results[i] = &dataloader.Result[models.Account]{Error: err}
+		case !ok:
+			results[i] = &dataloader.Result[models.Account]{Error: service.ErrAccountNotFound}
+		default:
+			results[i] = &dataloader.Result[models.Account]{Data: account}
+		}
+	}
+	return results
+}
+
+func (l *loaders) transferLoader(args relationArgs) *dataloader.Loader[int, []models.Transfer] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if loader, ok := l.transfers[args]; ok {
+		return loader
+	}
+	loader := dataloader.NewBatchedLoader(func(ctx context.Context, ids []int) []*dataloader.Result[[]models.Transfer] {
+		grouped, err := l.accountService.GetRecentTransfers(ctx, ids, args.first, args.before)
+		results := make([]*dataloader.Result[[]models.Transfer], len(ids))
+		for i, id := range ids {
+			results[i] = &dataloader.Result[[]models.Transfer]{Data: grouped[id], Error: err}
+		}
+		return results
+	}, dataloader.WithWait[int, []models.Transfer](batchWait))
+	l.transfers[args] = loader
+	return loader
+}
+
+func (l *loaders) entryLoader(args relationArgs) *dataloader.Loader[int, []models.Entry] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if loader, ok := l.entries[args]; ok {
+		return loader
+	}
+	loader := dataloader.NewBatchedLoader(func(ctx context.Context, ids []int) []*dataloader.Result[[]models.Entry] {
+		grouped, err := l.accountService.GetRecentEntries(ctx, ids, args.first, args.before)
+		results := make([]*dataloader.Result[[]models.Entry], len(ids))
+		for i, id := range ids {
+			results[i] = &dataloader.Result[[]models.Entry]{Data: grouped[id], Error: err}
+		}
+		return results
+	}, dataloader.WithWait[int, []models.Entry](batchWait))
+	l.entries[args] = loader
+	return loader
+}
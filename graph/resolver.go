@@ -0,0 +1,147 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/service"
+)
+
+// maxFirst caps the page size of lists, as for the REST listing
+const maxFirst = 100
+
+var errInvalidArgument = errors.New("invalid argument")
+
+type resolver struct {
+	accountService service.AccountService
+}
+
+func parseID(id graphql.ID) (int, error) {
+	value, err := strconv.Atoi(string(id))
+	if err != nil {
+		return 0, fmt.Errorf("%w: id %q is not a number", errInvalidArgument, id)
+	}
+	return value, nil
+}
+
+func parseFirst(first int32) (int, error) {
+	if first < 1 || first > maxFirst {
+		return 0, fmt.Errorf("%w: first must be between 1 and %d", errInvalidArgument, maxFirst)
+	}
+	return int(first), nil
+}
+
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func (r *resolver) Account(ctx context.Context, args struct{ ID graphql.ID }) (*accountResolver, error) {
+	logger.FromContext(ctx).Info("In func() Account :: GRAPHQL LAYER")
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	account, err := loadersFrom(ctx).accounts.Load(ctx, id)()
+	if errors.Is(err, service.ErrAccountNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, publicError(err)
+	}
+	return &accountResolver{account: account}, nil
+}
+
+type accountsArgs struct {
+	First        int32
+	After        *string
+	Owner        *string
+	Currency     *string
+	Status       *string
+	Sort         *string
+	IncludeTotal bool
+}
+
+func (r *resolver) Accounts(ctx context.Context, args accountsArgs) (*accountConnectionResolver, error) {
+	logger.FromContext(ctx).Info("In func() Accounts :: GRAPHQL LAYER")
+	first, err := parseFirst(args.First)
+	if err != nil {
+		return nil, err
+	}
+	page, err := r.accountService.GetAll(ctx, &request.ListAccountsRequest{
+		Cursor:       value(args.After),
+		PageSize:     first,
+		Owner:        value(args.Owner),
+		Currency:     value(args.Currency),
+		Status:       value(args.Status),
+		Sort:         value(args.Sort),
+		IncludeTotal: args.IncludeTotal,
+	})
+	if err != nil {
+		return nil, publicError(err)
+	}
+	l := loadersFrom(ctx)
+	connection := &accountConnectionResolver{page: page}
+	for _, account := range page.Data {
+		// later lookups of these accounts through a transfer or an entry are served from the loader cache
+		l.accounts.Prime(ctx, account.Id, account)
+		connection.nodes = append(connection.nodes, &accountResolver{account: account})
+	}
+	return connection, nil
+}
+
+func (r *resolver) Transfer(ctx context.Context, args struct{ ID graphql.ID }) (*transferResolver, error) {
+	logger.FromContext(ctx).Info("In func() Transfer :: GRAPHQL LAYER")
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	transfer, err := r.accountService.GetTransferById(ctx, id)
+	if errors.Is(err, service.ErrTransferNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, publicError(err)
+	}
+	return &transferResolver{transfer: transfer}, nil
+}
+
+type transferInput struct {
+	FromAccountID graphql.ID
+	ToAccountID   graphql.ID
+	Amount        float64
+	Currency      string
+}
+
+// CreateTransfer runs the transfer through the same transactional service method as the REST and gRPC APIs
+func (r *resolver) CreateTransfer(ctx context.Context, args struct{ Input transferInput }) (*transferResolver, error) {
+	logger.FromContext(ctx).Info("In func() CreateTransfer :: GRAPHQL LAYER")
+	from, err := parseID(args.Input.FromAccountID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseID(args.Input.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+	transfer, err := r.accountService.CreateTransfer(ctx, &request.TransferRequest{
+		FromAccountID: from,
+		ToAccountID:   to,
+		Amount:        args.Input.Amount,
+		Currency:      args.Input.Currency,
+	})
+	if err != nil {
+		return nil, publicError(err)
+	}
+	// the balances changed, so the accounts must not be served from the cache afterwards
+	l := loadersFrom(ctx)
+	l.accounts.Clear(ctx, from).Clear(ctx, to)
+	return &transferResolver{transfer: transfer}, nil
+}
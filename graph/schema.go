@@ -0,0 +1,73 @@
+// Package graph serves accounts, transfers and entries through a GraphQL schema on top of the service layer
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/service"
+)
+
+//go:embed schema.graphql
+var schemaDefinition string
+
+// maxDepth limits how deep account -> transfers -> account chains can be nested in one query
+const maxDepth = 8
+
+var errInternal = errors.New("internal error")
+
+// Schema executes GraphQL requests, each request gets its own batch loaders
+type Schema struct {
+	schema         *graphql.Schema
+	accountService service.AccountService
+}
+
+// NewSchema parses the schema and binds it to the resolvers.
+// Mutations run in the transactions of the service, like for REST.
+func NewSchema(accountService service.AccountService) *Schema {
+	root := &resolver{accountService: accountService}
+	return &Schema{
+		schema:         graphql.MustParseSchema(schemaDefinition, root, graphql.MaxDepth(maxDepth)),
+		accountService: accountService,
+	}
+}
+
+// Exec runs a query or mutation
+func (s *Schema) Exec(ctx context.Context, query string, operationName string, variables map[string]interface{}) *graphql.Response {
+	ctx = withLoaders(ctx, newLoaders(s.accountService))
+	return s.schema.Exec(ctx, query, operationName, variables)
+}
+
+// publicError keeps domain and validation errors and hides the others behind a generic message
+func publicError(err error) error {
+	var domainErr *service.Error
+	switch {
+	case errors.As(err, &domainErr):
+		return codedError{err: err, code: domainErr.Code}
+	case errors.Is(err, errInvalidArgument):
+		return err
+	}
+	logger.Log.With("error", err).Error("graphql resolver failed")
+	return errInternal
+}
+
+// codedError exposes the stable code of a domain error in the extensions of the GraphQL error
+type codedError struct {
+	err  error
+	code string
+}
+
+func (e codedError) Error() string {
+	return e.err.Error()
+}
+
+func (e codedError) Unwrap() error {
+	return e.err
+}
+
+func (e codedError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
@@ -0,0 +1,137 @@
+package graph_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/graph"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mock "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func setup(t *testing.T) (*graph.Schema, *mock.MockAccountService, *mock.MockLogger) {
+	mockCtrl := gomock.NewController(t)
+	mockAccountService := mock.NewMockAccountService(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	return graph.NewSchema(mockAccountService), mockAccountService, mockLogger
+}
+
+func TestAccountsWithRelationsAreBatched(t *testing.T) {
+	schema, mockAccountService, mockLogger := setup(t)
+	mockLogger.EXPECT().Info("In func() Accounts :: GRAPHQL LAYER")
+	mockAccountService.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return(models.AccountPage{
+		Data: []models.Account{
+			{Id: 1, Owner: "Rahul", Currency: "USD"},
+			{Id: 2, Owner: "Ravi", Currency: "USD"},
+			{Id: 3, Owner: "Anu", Currency: "USD"},
+		},
+		NextCursor: "abc",
+	}, nil)
+	// one query per relation for all three accounts, the related accounts 1 and 2 come from the primed cache
+	mockAccountService.EXPECT().GetRecentTransfers(gomock.Any(), gomock.InAnyOrder([]int{1, 2, 3}), 5, 0).
+		Return(map[int][]models.Transfer{
+			1: {{Id: 10, FromAccountID: 1, ToAccountID: 2, Amount: 5}},
+			2: {{Id: 10, FromAccountID: 1, ToAccountID: 2, Amount: 5}, {Id: 9, FromAccountID: 4, ToAccountID: 2, Amount: 1}},
+		}, nil).Times(1)
+	mockAccountService.EXPECT().GetRecentEntries(gomock.Any(), gomock.InAnyOrder([]int{1, 2, 3}), 10, 0).
+		Return(map[int][]models.Entry{1: {{Id: 20, AccountID: 1, Amount: -5}}}, nil).Times(1)
+	mockAccountService.EXPECT().GetAccountsByIds(gomock.Any(), []int{4}).Return(nil, nil).Times(1)
+
+	response := schema.Exec(context.Background(), `{
+		accounts(first: 3) {
+			nextCursor
+			nodes {
+				owner
+				transfers(first: 5) { id fromAccount { owner } toAccount { owner } }
+				entries { amount }
+			}
+		}
+	}`, "", nil)
+	assert.Equal(t, len(response.Errors), 0)
+
+	var data struct {
+		Accounts struct {
+			NextCursor string
+			Nodes      []struct {
+				Owner     string
+				Transfers []struct {
+					ID          string
+					FromAccount *struct{ Owner string }
+					ToAccount   *struct{ Owner string }
+				}
+				Entries []struct{ Amount float64 }
+			}
+		}
+	}
+	json.Unmarshal(response.Data, &data)
+	assert.Equal(t, data.Accounts.NextCursor, "abc")
+	assert.Equal(t, len(data.Accounts.Nodes), 3)
+	assert.Equal(t, data.Accounts.Nodes[0].Transfers[0].ToAccount.Owner, "Ravi")
+	// account 4 does not exist anymore
+	assert.Equal(t, data.Accounts.Nodes[1].Transfers[1].FromAccount == nil, true)
+	assert.Equal(t, data.Accounts.Nodes[0].Entries[0].Amount, float64(-5))
+	assert.Equal(t, len(data.Accounts.Nodes[2].Transfers), 0)
+}
+
+func TestAccount(t *testing.T) {
+	schema, mockAccountService, mockLogger := setup(t)
+	mockLogger.EXPECT().Info("In func() Account :: GRAPHQL LAYER")
+	mockAccountService.EXPECT().GetAccountsByIds(gomock.Any(), []int{7}).Return(nil, nil)
+	response := schema.Exec(context.Background(), `{ account(id: "7") { id } }`, "", nil)
+	assert.Equal(t, len(response.Errors), 0)
+	assert.Equal(t, string(response.Data), `{"account":null}`)
+
+	//Failure case: database errors are not leaked
+	mockLogger.EXPECT().Info("In func() Account :: GRAPHQL LAYER")
+	mockLogger.EXPECT().With("error", gomock.Any()).Return(mockLogger)
+	mockLogger.EXPECT().Error("graphql resolver failed")
+	mockAccountService.EXPECT().GetAccountsByIds(gomock.Any(), []int{8}).Return(nil, errors.New("connection refused"))
+	response = schema.Exec(context.Background(), `{ account(id: "8") { id } }`, "", nil)
+	assert.Equal(t, len(response.Errors), 1)
+	assert.Equal(t, response.Errors[0].Message, "internal error")
+}
+
+func TestCreateTransfer(t *testing.T) {
+	schema, mockAccountService, mockLogger := setup(t)
+	const mutation = `mutation($input: TransferInput!) { createTransfer(input: $input) { id amount } }`
+
+	mockLogger.EXPECT().Info("In func() CreateTransfer :: GRAPHQL LAYER")
+	mockAccountService.EXPECT().
+		CreateTransfer(gomock.Any(), &request.TransferRequest{FromAccountID: 1, ToAccountID: 2, Amount: 25, Currency: "USD"}).
+		Return(models.Transfer{Id: 3, FromAccountID: 1, ToAccountID: 2, Amount: 25}, nil)
+	response := schema.Exec(context.Background(), mutation, "", map[string]interface{}{
+		"input": map[string]interface{}{"fromAccountId": "1", "toAccountId": "2", "amount": 25, "currency": "USD"},
+	})
+	assert.Equal(t, len(response.Errors), 0)
+	assert.Equal(t, string(response.Data), `{"createTransfer":{"id":"3","amount":25}}`)
+
+	//Failure case: a failing transfer is reported without its cause
+	mockLogger.EXPECT().Info("In func() CreateTransfer :: GRAPHQL LAYER")
+	mockLogger.EXPECT().With("error", gomock.Any()).Return(mockLogger)
+	mockLogger.EXPECT().Error("graphql resolver failed")
+	mockAccountService.EXPECT().CreateTransfer(gomock.Any(), gomock.Any()).Return(models.Transfer{}, errors.New("deadlock detected"))
+	response = schema.Exec(context.Background(), mutation, "", map[string]interface{}{
+		"input": map[string]interface{}{"fromAccountId": "1", "toAccountId": "2", "amount": 25, "currency": "USD"},
+	})
+	assert.Equal(t, len(response.Errors), 1)
+
+	//Failure case: rejected by the service, with its code
+	mockLogger.EXPECT().Info("In func() CreateTransfer :: GRAPHQL LAYER")
+	mockAccountService.EXPECT().CreateTransfer(gomock.Any(), gomock.Any()).
+		Return(models.Transfer{}, fmt.Errorf("%w: cannot transfer to the same account", service.ErrInvalidTransfer))
+	response = schema.Exec(context.Background(), mutation, "", map[string]interface{}{
+		"input": map[string]interface{}{"fromAccountId": "1", "toAccountId": "1", "amount": 25, "currency": "USD"},
+	})
+	assert.Equal(t, response.Errors[0].Message, "invalid transfer: cannot transfer to the same account")
+	assert.Equal(t, response.Errors[0].Extensions["code"], "INVALID_TRANSFER")
+
+}
@@ -0,0 +1,177 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/service"
+)
+
+type accountResolver struct {
+	account models.Account
+}
+
+func (r *accountResolver) ID() graphql.ID {
+	return graphql.ID(strconv.Itoa(r.account.Id))
+}
+
+func (r *accountResolver) Owner() string {
+	return r.account.Owner
+}
+
+func (r *accountResolver) Currency() string {
+	return r.account.Currency
+}
+
+func (r *accountResolver) Balance() float64 {
+	return r.account.Balance
+}
+
+func (r *accountResolver) Status() string {
+	return r.account.Status
+}
+
+func (r *accountResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.account.CreatedAt}
+}
+
+type relationPageArgs struct {
+	First  int32
+	Before *graphql.ID
+}
+
+func (a relationPageArgs) parse() (relationArgs, error) {
+	first, err := parseFirst(a.First)
+	if err != nil {
+		return relationArgs{}, err
+	}
+	args := relationArgs{first: first}
+	if a.Before != nil {
+		if args.before, err = parseID(*a.Before); err != nil {
+			return relationArgs{}, err
+		}
+	}
+	return args, nil
+}
+
+func (r *accountResolver) Transfers(ctx context.Context, args relationPageArgs) ([]*transferResolver, error) {
+	page, err := args.parse()
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := loadersFrom(ctx).transferLoader(page).Load(ctx, r.account.Id)()
+	if err != nil {
+		return nil, publicError(err)
+	}
+	resolvers := make([]*transferResolver, len(transfers))
+	for i, transfer := range transfers {
+		resolvers[i] = &transferResolver{transfer: transfer}
+	}
+	return resolvers, nil
+}
+
+func (r *accountResolver) Entries(ctx context.Context, args relationPageArgs) ([]*entryResolver, error) {
+	page, err := args.parse()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := loadersFrom(ctx).entryLoader(page).Load(ctx, r.account.Id)()
+	if err != nil {
+		return nil, publicError(err)
+	}
+	resolvers := make([]*entryResolver, len(entries))
+	for i, entry := range entries {
+		resolvers[i] = &entryResolver{entry: entry}
+	}
+	return resolvers, nil
+}
+
+type accountConnectionResolver struct {
+	page  models.AccountPage
+	nodes []*accountResolver
+}
+
+func (r *accountConnectionResolver) Nodes() []*accountResolver {
+	return r.nodes
+}
+
+func (r *accountConnectionResolver) NextCursor() *string {
+	if r.page.NextCursor == "" {
+		return nil
+	}
+	return &r.page.NextCursor
+}
+
+func (r *accountConnectionResolver) TotalCount() *int32 {
+	if r.page.Total == nil {
+		return nil
+	}
+	total := int32(*r.page.Total)
+	return &total
+}
+
+type transferResolver struct {
+	transfer models.Transfer
+}
+
+func (r *transferResolver) ID() graphql.ID {
+	return graphql.ID(strconv.Itoa(r.transfer.Id))
+}
+
+func (r *transferResolver) Amount() float64 {
+	return r.transfer.Amount
+}
+
+func (r *transferResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.transfer.CreatedAt}
+}
+
+func (r *transferResolver) FromAccount(ctx context.Context) (*accountResolver, error) {
+	return loadAccount(ctx, r.transfer.FromAccountID)
+}
+
+func (r *transferResolver) ToAccount(ctx context.Context) (*accountResolver, error) {
+	return loadAccount(ctx, r.transfer.ToAccountID)
+}
+
+type entryResolver struct {
+	entry models.Entry
+}
+
+func (r *entryResolver) ID() graphql.ID {
+	return graphql.ID(strconv.Itoa(r.entry.Id))
+}
+
+func (r *entryResolver) Amount() float64 {
+	return r.entry.Amount
+}
+
+func (r *entryResolver) ReasonCode() *string {
+	if r.entry.ReasonCode == "" {
+		return nil
+	}
+	return &r.entry.ReasonCode
+}
+
+func (r *entryResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.entry.CreatedAt}
+}
+
+func (r *entryResolver) Account(ctx context.Context) (*accountResolver, error) {
+	return loadAccount(ctx, r.entry.AccountID)
+}
+
+// loadAccount resolves a related account through the batch loader, deleted accounts resolve to null
+func loadAccount(ctx context.Context, id int) (*accountResolver, error) {
+	account, err := loadersFrom(ctx).accounts.Load(ctx, id)()
+	if errors.Is(err, service.ErrAccountNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, publicError(err)
+	}
+	return &accountResolver{account: account}, nil
+}
@@ -0,0 +1,135 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	pb "github.com/rahul-024/fund-transfer-poc/proto/fundtransfer/v1"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"github.com/rahul-024/fund-transfer-poc/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type accountServer struct {
+	pb.UnimplementedAccountServiceServer
+	accountService service.AccountService
+}
+
+func (s *accountServer) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	logger.FromContext(ctx).Info("In func() CreateAccount :: GRPC LAYER")
+	if req.GetOwner() == "" {
+		return nil, status.Error(codes.InvalidArgument, "owner is required")
+	}
+	if !util.IsSupportedCurrency(req.GetCurrency()) {
+		return nil, status.Errorf(codes.InvalidArgument, "currency %q is not supported", req.GetCurrency())
+	}
+	account := models.Account{Owner: req.GetOwner(), Currency: req.GetCurrency()}
+	account, err := s.accountService.SaveAccount(ctx, account)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CreateAccountResponse{Account: toProtoAccount(account)}, nil
+}
+
+func (s *accountServer) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	logger.FromContext(ctx).Info("In func() GetAccount :: GRPC LAYER")
+	account, err := s.accountService.GetAccountById(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.GetAccountResponse{Account: toProtoAccount(account)}, nil
+}
+
+func (s *accountServer) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	logger.FromContext(ctx).Info("In func() ListAccounts :: GRPC LAYER")
+	if req.GetPageSize() < 0 || req.GetPageSize() > 100 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must be between 1 and 100")
+	}
+	page, err := s.accountService.GetAll(ctx, &request.ListAccountsRequest{
+		Cursor:       req.GetCursor(),
+		PageSize:     int(req.GetPageSize()),
+		Owner:        req.GetOwner(),
+		Currency:     req.GetCurrency(),
+		Status:       req.GetStatus(),
+		Sort:         req.GetSort(),
+		IncludeTotal: req.GetIncludeTotal(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &pb.ListAccountsResponse{NextCursor: page.NextCursor}
+	for _, account := range page.Data {
+		res.Accounts = append(res.Accounts, toProtoAccount(account))
+	}
+	if page.Total != nil {
+		res.Total = *page.Total
+	}
+	return res, nil
+}
+
+// UpdateAccount goes through the merge patch of the service so both APIs share the allowlist and its checks
+func (s *accountServer) UpdateAccount(ctx context.Context, req *pb.UpdateAccountRequest) (*pb.UpdateAccountResponse, error) {
+	logger.FromContext(ctx).Info("In func() UpdateAccount :: GRPC LAYER")
+	patch := map[string]json.RawMessage{}
+	if req.Owner != nil {
+		patch["owner"], _ = json.Marshal(req.GetOwner())
+	}
+	if req.Currency != nil {
+		patch["currency"], _ = json.Marshal(req.GetCurrency())
+	}
+	var account models.Account
+	// the account is read in the transaction of the patch so that the checks see the row being changed
+	err := s.accountService.RunInTx(ctx, func(as service.AccountService) (err error) {
+		if account, err = as.GetAccountById(ctx, int(req.GetId())); err != nil {
+			return err
+		}
+		account, err = as.PatchAccountById(ctx, account, patch)
+		return err
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.UpdateAccountResponse{Account: toProtoAccount(account)}, nil
+}
+
+func (s *accountServer) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	logger.FromContext(ctx).Info("In func() DeleteAccount :: GRPC LAYER")
+	err := s.accountService.DeleteAccountById(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.DeleteAccountResponse{}, nil
+}
+
+func (s *accountServer) AdjustBalance(ctx context.Context, req *pb.AdjustBalanceRequest) (*pb.AdjustBalanceResponse, error) {
+	logger.FromContext(ctx).Info("In func() AdjustBalance :: GRPC LAYER")
+	entry, err := s.accountService.AdjustBalance(ctx, int(req.GetAccountId()), &request.BalanceAdjustmentRequest{
+		Amount:     req.GetAmount(),
+		ReasonCode: req.GetReasonCode(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.AdjustBalanceResponse{Entry: toProtoEntry(entry)}, nil
+}
+
+func (s *accountServer) ListEntries(req *pb.ListEntriesRequest, stream pb.AccountService_ListEntriesServer) error {
+	logger.FromContext(stream.Context()).Info("In func() ListEntries :: GRPC LAYER")
+	entries, err := s.accountService.GetEntries(stream.Context(), int(req.GetAccountId()))
+	if err != nil {
+		return toStatus(err)
+	}
+	for _, entry := range entries {
+		if err = stream.Context().Err(); err != nil {
+			return toStatus(err)
+		}
+		if err = stream.Send(&pb.ListEntriesResponse{Entry: toProtoEntry(entry)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
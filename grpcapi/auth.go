@@ -0,0 +1,103 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rahul-024/fund-transfer-poc/audit"
+	"github.com/rahul-024/fund-transfer-poc/auth"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// metadata keys of the credentials, the gRPC counterparts of the Authorization and X-API-Key headers
+const (
+	authorizationKey = "authorization"
+	apiKeyKey        = "x-api-key"
+)
+
+// Authenticator authenticates the calls like the REST API does: with the API key of the x-api-key metadata
+// when present, with the bearer token of the authorization metadata otherwise
+type Authenticator struct {
+	Verifier *auth.Verifier
+	APIKeys  service.APIKeyService
+	// Anonymous is the principal of every call when set, no credentials are checked. It is meant for servers
+	// running with authentication disabled.
+	Anonymous *auth.Principal
+}
+
+// authenticate stores the principal of the call in ctx for the service layer, and its subject as the actor
+// of the audit events
+func (a *Authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	if a.Anonymous != nil {
+		return auth.NewContext(ctx, *a.Anonymous), nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	var principal auth.Principal
+	if key := firstValue(md, apiKeyKey); key != "" {
+		var err error
+		principal, err = a.APIKeys.Authenticate(ctx, key)
+		if errors.Is(err, service.ErrInvalidAPIKey) {
+			logger.FromContext(ctx).With("error", err).Debug("rejected API key")
+			return ctx, status.Error(codes.Unauthenticated, "invalid API key")
+		}
+		if err != nil {
+			return ctx, toStatus(err)
+		}
+	} else {
+		token, ok := auth.BearerToken(firstValue(md, authorizationKey))
+		if !ok {
+			return ctx, status.Error(codes.Unauthenticated, "missing bearer token")
+		}
+		var err error
+		if principal, err = a.Verifier.Verify(token); err != nil {
+			logger.FromContext(ctx).With("error", err).Debug("rejected bearer token")
+			return ctx, status.Error(codes.Unauthenticated, err.Error())
+		}
+	}
+	ctx = auth.NewContext(ctx, principal)
+	// the audit metadata is stored before the caller is known
+	meta := audit.FromContext(ctx)
+	meta.Actor = principal.Subject
+	return audit.NewContext(ctx, meta), nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// authUnaryInterceptor rejects the calls that cannot be authenticated. Every call goes through when a is nil,
+// without a principal the policy then denies it.
+func authUnaryInterceptor(a *Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if a == nil {
+			return handler(ctx, req)
+		}
+		ctx, err := a.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authStreamInterceptor is the stream counterpart of authUnaryInterceptor
+func authStreamInterceptor(a *Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if a == nil {
+			return handler(srv, ss)
+		}
+		ctx, err := a.authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &auditServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
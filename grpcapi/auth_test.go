@@ -0,0 +1,170 @@
+package grpcapi_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/audit"
+	"github.com/rahul-024/fund-transfer-poc/auth"
+	"github.com/rahul-024/fund-transfer-poc/grpcapi"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mock "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	pb "github.com/rahul-024/fund-transfer-poc/proto/fundtransfer/v1"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gopkg.in/go-playground/assert.v1"
+	"gorm.io/gorm"
+)
+
+var secret = []byte("test-secret-0123456789abcdef")
+
+// setupAuth dials a server authenticating the calls with HS256 tokens signed with secret and with API keys
+func setupAuth(t *testing.T) (*mock.MockAccountService, *mock.MockAPIKeyService, *mock.MockLogger, pb.AccountServiceClient) {
+	mockAccountService, mockLogger, _ := setup(t)
+	mockAPIKeyService := mock.NewMockAPIKeyService(gomock.NewController(t))
+	verifier, err := auth.NewVerifier(auth.Keys{HMACSecret: secret}, "", "", 0)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	conn := dial(t, mockAccountService, &grpcapi.Authenticator{Verifier: verifier, APIKeys: mockAPIKeyService})
+	return mockAccountService, mockAPIKeyService, mockLogger, pb.NewAccountServiceClient(conn)
+}
+
+func bearer(t *testing.T, subject string) context.Context {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": subject, "exp": time.Now().Add(time.Minute).Unix()})
+	signed, err := token.SignedString(secret)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+signed)
+}
+
+func TestAuthenticationRejectsAnonymousCalls(t *testing.T) {
+	_, _, mockLogger, client := setupAuth(t)
+
+	//the service is never reached
+	_, err := client.DeleteAccount(context.Background(), &pb.DeleteAccountRequest{Id: 1})
+	assert.Equal(t, status.Code(err), codes.Unauthenticated)
+
+	mockLogger.EXPECT().With("error", gomock.Any()).Return(mockLogger)
+	mockLogger.EXPECT().Debug("rejected bearer token")
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer not-a-token")
+	_, err = client.DeleteAccount(ctx, &pb.DeleteAccountRequest{Id: 1})
+	assert.Equal(t, status.Code(err), codes.Unauthenticated)
+
+	stream, err := client.ListEntries(context.Background(), &pb.ListEntriesRequest{AccountId: 1})
+	assert.Equal(t, err, nil)
+	_, err = stream.Recv()
+	assert.Equal(t, status.Code(err), codes.Unauthenticated)
+}
+
+func TestAuthenticationWithBearerToken(t *testing.T) {
+	mockAccountService, _, mockLogger, client := setupAuth(t)
+
+	mockLogger.EXPECT().Info("In func() GetAccount :: GRPC LAYER")
+	mockAccountService.EXPECT().GetAccountById(gomock.Any(), 1).
+		DoAndReturn(func(ctx context.Context, id int) (models.Account, error) {
+			principal, ok := auth.FromContext(ctx)
+			assert.Equal(t, ok, true)
+			assert.Equal(t, principal.Subject, "alice")
+			assert.Equal(t, audit.FromContext(ctx).Actor, "alice")
+			return models.Account{Id: 1, Owner: "alice"}, nil
+		})
+	_, err := client.GetAccount(bearer(t, "alice"), &pb.GetAccountRequest{Id: 1})
+	assert.Equal(t, err, nil)
+}
+
+func TestAuthenticationWithAPIKey(t *testing.T) {
+	mockAccountService, mockAPIKeyService, mockLogger, client := setupAuth(t)
+
+	mockAPIKeyService.EXPECT().Authenticate(gomock.Any(), "ftk_valid").
+		Return(auth.Principal{Subject: "apikey:ftk_vali", Claims: map[string]interface{}{service.ScopesClaim: []string{service.ScopeAccountsRead}}}, nil)
+	mockLogger.EXPECT().Info("In func() GetAccount :: GRPC LAYER")
+	mockAccountService.EXPECT().GetAccountById(gomock.Any(), 1).
+		DoAndReturn(func(ctx context.Context, id int) (models.Account, error) {
+			principal, _ := auth.FromContext(ctx)
+			assert.Equal(t, principal.Subject, "apikey:ftk_vali")
+			return models.Account{Id: 1}, nil
+		})
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "ftk_valid")
+	_, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: 1})
+	assert.Equal(t, err, nil)
+
+	//a key is never mistaken for a bearer token
+	mockAPIKeyService.EXPECT().Authenticate(gomock.Any(), "ftk_revoked").Return(auth.Principal{}, service.ErrInvalidAPIKey)
+	mockLogger.EXPECT().With("error", gomock.Any()).Return(mockLogger)
+	mockLogger.EXPECT().Debug("rejected API key")
+	ctx = metadata.AppendToOutgoingContext(bearer(t, "alice"), "x-api-key", "ftk_revoked")
+	_, err = client.GetAccount(ctx, &pb.GetAccountRequest{Id: 1})
+	assert.Equal(t, status.Code(err), codes.Unauthenticated)
+}
+
+func TestAuthenticationDisabled(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockAccountService := mock.NewMockAccountService(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With(logger.RequestIDField, requestID).Return(mockLogger).AnyTimes()
+	anonymous := service.SystemPrincipal("anonymous")
+	client := pb.NewAccountServiceClient(dial(t, mockAccountService, &grpcapi.Authenticator{Anonymous: &anonymous}))
+
+	//no credentials are needed, the calls run as the anonymous principal
+	mockLogger.EXPECT().Info("In func() DeleteAccount :: GRPC LAYER")
+	mockAccountService.EXPECT().DeleteAccountById(gomock.Any(), 1).
+		DoAndReturn(func(ctx context.Context, id int) error {
+			principal, ok := auth.FromContext(ctx)
+			assert.Equal(t, ok, true)
+			assert.Equal(t, principal.Subject, "anonymous")
+			return nil
+		})
+	_, err := client.DeleteAccount(context.Background(), &pb.DeleteAccountRequest{Id: 1})
+	assert.Equal(t, err, nil)
+}
+
+// TestPolicyRejectsCallsWithoutPrincipal serves the account service and its policy without authenticator:
+// the calls carry no principal and are rejected before anything changes
+func TestPolicyRejectsCallsWithoutPrincipal(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With(gomock.Any()).Return(mockLogger).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
+	mockUnitOfWork.EXPECT().RunInTx(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *sql.TxOptions, fn func(*gorm.DB) error) error {
+			return fn(nil)
+		}).Times(2)
+	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockAccountRepo.EXPECT().WithTrx(gomock.Any()).Return(mockAccountRepo).AnyTimes()
+	mockTransferRepo.EXPECT().WithTrx(gomock.Any()).Return(mockTransferRepo).AnyTimes()
+	mockEntryRepo.EXPECT().WithTrx(gomock.Any()).Return(mockEntryRepo).AnyTimes()
+	mockAuditRepo.EXPECT().WithTrx(gomock.Any()).Return(mockAuditRepo).AnyTimes()
+	mockOutboxRepo.EXPECT().WithTrx(gomock.Any()).Return(mockOutboxRepo).AnyTimes()
+	accountService := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo,
+		mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelDefault)
+	conn := dial(t, accountService, nil)
+
+	//the account is not deleted
+	_, err := pb.NewAccountServiceClient(conn).DeleteAccount(context.Background(), &pb.DeleteAccountRequest{Id: 1})
+	assert.Equal(t, status.Code(err), codes.Unauthenticated)
+
+	//the accounts are read to check the transfer, no money moves
+	mockAccountRepo.EXPECT().GetAccountsByIds(gomock.Any(), []int{1, 2}).Return([]models.Account{
+		{Id: 1, Owner: "alice", Currency: "USD", Balance: 100},
+		{Id: 2, Owner: "bob", Currency: "USD"},
+	}, nil)
+	_, err = pb.NewTransferServiceClient(conn).CreateTransfer(context.Background(),
+		&pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 2, Amount: 10, Currency: "USD"})
+	assert.Equal(t, status.Code(err), codes.Unauthenticated)
+}
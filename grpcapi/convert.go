@@ -0,0 +1,38 @@
+package grpcapi
+
+import (
+	"github.com/rahul-024/fund-transfer-poc/models"
+	pb "github.com/rahul-024/fund-transfer-poc/proto/fundtransfer/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toProtoAccount(account models.Account) *pb.Account {
+	return &pb.Account{
+		Id:        int64(account.Id),
+		Owner:     account.Owner,
+		Currency:  account.Currency,
+		Balance:   account.Balance,
+		Status:    account.Status,
+		CreatedAt: timestamppb.New(account.CreatedAt),
+	}
+}
+
+func toProtoEntry(entry models.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:         int64(entry.Id),
+		AccountId:  int64(entry.AccountID),
+		Amount:     entry.Amount,
+		ReasonCode: entry.ReasonCode,
+		CreatedAt:  timestamppb.New(entry.CreatedAt),
+	}
+}
+
+func toProtoTransfer(transfer models.Transfer) *pb.Transfer {
+	return &pb.Transfer{
+		Id:            int64(transfer.Id),
+		FromAccountId: int64(transfer.FromAccountID),
+		ToAccountId:   int64(transfer.ToAccountID),
+		Amount:        transfer.Amount,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+	}
+}
@@ -0,0 +1,123 @@
+// Package grpcapi serves the account and transfer use cases over gRPC, next to the Gin REST API
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/rahul-024/fund-transfer-poc/audit"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	pb "github.com/rahul-024/fund-transfer-poc/proto/fundtransfer/v1"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDKey is the metadata key carrying the request id, the gRPC counterpart of X-Request-ID
+const requestIDKey = "x-request-id"
+
+// NewServer returns a gRPC server with the account and transfer services registered.
+// Mutations run in the transactions of the service, like for REST. The calls carry no principal when
+// authenticator is nil and the policy of the service denies them.
+func NewServer(accountService service.AccountService, authenticator *Authenticator) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(auditUnaryInterceptor, authUnaryInterceptor(authenticator)),
+		grpc.ChainStreamInterceptor(auditStreamInterceptor, authStreamInterceptor(authenticator)),
+	)
+	pb.RegisterAccountServiceServer(server, &accountServer{accountService: accountService})
+	pb.RegisterTransferServiceServer(server, &transferServer{accountService: accountService})
+	return server
+}
+
+// Serve listens on address and blocks serving the gRPC API until ctx is cancelled, the calls in flight
+// are let to finish
+func Serve(ctx context.Context, server *grpc.Server, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		select {
+		case <-ctx.Done():
+			server.GracefulStop()
+		case <-stopped:
+		}
+	}()
+	return server.Serve(listener)
+}
+
+// kindCodes maps the kinds of domain errors to gRPC status codes
+var kindCodes = map[service.ErrorKind]codes.Code{
+	service.KindNotFound:          codes.NotFound,
+	service.KindValidationFailed:  codes.InvalidArgument,
+	service.KindInsufficientFunds: codes.FailedPrecondition,
+	service.KindConflict:          codes.Aborted,
+	service.KindLimitExceeded:     codes.ResourceExhausted,
+	service.KindForbidden:         codes.PermissionDenied,
+	service.KindUnauthenticated:   codes.Unauthenticated,
+}
+
+// toStatus maps service errors to gRPC status codes, unexpected errors are not leaked to the caller
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if code, ok := kindCodes[service.KindOf(err)]; ok {
+		return status.Error(code, err.Error())
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	logger.Log.With("error", err).Error("grpc call failed")
+	return status.Error(codes.Internal, "internal error")
+}
+
+// auditContext stores the request id and the peer address in ctx for the audit events and the request-scoped
+// logger. The request id of the client is kept when valid, otherwise one is generated and sent back in the
+// response header.
+func auditContext(ctx context.Context) context.Context {
+	meta := audit.Meta{}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDKey); len(values) > 0 && logger.ValidRequestID(values[0]) {
+			meta.RequestID = values[0]
+		}
+	}
+	if meta.RequestID == "" {
+		meta.RequestID = logger.NewRequestID()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, meta.RequestID))
+	ctx = logger.NewContext(ctx, meta.RequestID)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		meta.SourceIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(meta.SourceIP); err == nil {
+			meta.SourceIP = host
+		}
+	}
+	return audit.NewContext(ctx, meta)
+}
+
+func auditUnaryInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(auditContext(ctx), req)
+}
+
+func auditStreamInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &auditServerStream{ServerStream: ss, ctx: auditContext(ss.Context())})
+}
+
+type auditServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *auditServerStream) Context() context.Context {
+	return s.ctx
+}
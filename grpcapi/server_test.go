@@ -0,0 +1,189 @@
+package grpcapi_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/grpcapi"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mock "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	pb "github.com/rahul-024/fund-transfer-poc/proto/fundtransfer/v1"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+// requestID is sent with every call of the tests, the request-scoped logger adds it to every line
+const requestID = "req-1"
+
+// dial starts the gRPC server on an in-process listener and returns a connection to it, the calls carry no
+// principal when authenticator is nil
+func dial(t *testing.T, accountService service.AccountService, authenticator *grpcapi.Authenticator) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpcapi.NewServer(accountService, authenticator)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(metadata.AppendToOutgoingContext(ctx, "x-request-id", requestID), method, req, reply, cc, opts...)
+		}),
+		grpc.WithStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(metadata.AppendToOutgoingContext(ctx, "x-request-id", requestID), desc, cc, method, opts...)
+		}))
+	if err != nil {
+		t.Fatalf("Failed to dial bufnet: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func setup(t *testing.T) (*mock.MockAccountService, *mock.MockLogger, *grpc.ClientConn) {
+	mockCtrl := gomock.NewController(t)
+	mockAccountService := mock.NewMockAccountService(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With(logger.RequestIDField, requestID).Return(mockLogger).AnyTimes()
+	return mockAccountService, mockLogger, dial(t, mockAccountService, nil)
+}
+
+// expectRunInTx lets RunInTx call its function with the mock itself, as a service bound to the transaction
+func expectRunInTx(mockAccountService *mock.MockAccountService) {
+	mockAccountService.EXPECT().RunInTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(service.AccountService) error) error {
+			return fn(mockAccountService)
+		})
+}
+
+func TestGetAccount(t *testing.T) {
+	mockAccountService, mockLogger, conn := setup(t)
+	client := pb.NewAccountServiceClient(conn)
+
+	mockLogger.EXPECT().Info("In func() GetAccount :: GRPC LAYER")
+	mockAccountService.EXPECT().GetAccountById(gomock.Any(), 1).
+		Return(models.Account{Id: 1, Owner: "Rahul", Currency: "USD", Balance: 10}, nil)
+	res, err := client.GetAccount(context.Background(), &pb.GetAccountRequest{Id: 1})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, res.Account.Owner, "Rahul")
+	assert.Equal(t, res.Account.Balance, float64(10))
+
+	//Failure case: unknown account
+	mockLogger.EXPECT().Info("In func() GetAccount :: GRPC LAYER")
+	mockAccountService.EXPECT().GetAccountById(gomock.Any(), 2).Return(models.Account{}, service.ErrAccountNotFound)
+	_, err = client.GetAccount(context.Background(), &pb.GetAccountRequest{Id: 2})
+	assert.Equal(t, status.Code(err), codes.NotFound)
+
+	//Failure case: unexpected errors are not leaked
+	mockLogger.EXPECT().Info("In func() GetAccount :: GRPC LAYER")
+	mockLogger.EXPECT().With("error", gomock.Any()).Return(mockLogger)
+	mockLogger.EXPECT().Error("grpc call failed")
+	mockAccountService.EXPECT().GetAccountById(gomock.Any(), 3).Return(models.Account{}, errors.New("connection refused"))
+	_, err = client.GetAccount(context.Background(), &pb.GetAccountRequest{Id: 3})
+	assert.Equal(t, status.Code(err), codes.Internal)
+	assert.Equal(t, status.Convert(err).Message(), "internal error")
+}
+
+func TestCreateAccount(t *testing.T) {
+	mockAccountService, mockLogger, conn := setup(t)
+	client := pb.NewAccountServiceClient(conn)
+
+	mockLogger.EXPECT().Info("In func() CreateAccount :: GRPC LAYER")
+	mockAccountService.EXPECT().SaveAccount(gomock.Any(), models.Account{Owner: "Rahul", Currency: "USD"}).
+		Return(models.Account{Id: 7, Owner: "Rahul", Currency: "USD"}, nil)
+	res, err := client.CreateAccount(context.Background(), &pb.CreateAccountRequest{Owner: "Rahul", Currency: "USD"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, res.Account.Id, int64(7))
+
+	//Failure case: unsupported currency is rejected before the service is called
+	mockLogger.EXPECT().Info("In func() CreateAccount :: GRPC LAYER")
+	_, err = client.CreateAccount(context.Background(), &pb.CreateAccountRequest{Owner: "Rahul", Currency: "XYZ"})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+
+}
+
+func TestUpdateAccount(t *testing.T) {
+	mockAccountService, mockLogger, conn := setup(t)
+	client := pb.NewAccountServiceClient(conn)
+
+	//Failure case: a rejected patch fails the transaction
+	owner := "Ravi"
+	account := models.Account{Id: 1, Owner: "Rahul", Currency: "USD", Balance: 10}
+	mockLogger.EXPECT().Info("In func() UpdateAccount :: GRPC LAYER")
+	expectRunInTx(mockAccountService)
+	mockAccountService.EXPECT().GetAccountById(gomock.Any(), 1).Return(account, nil)
+	mockAccountService.EXPECT().PatchAccountById(gomock.Any(), account, gomock.Len(1)).
+		Return(account, service.ErrInvalidPatch)
+	_, err := client.UpdateAccount(context.Background(), &pb.UpdateAccountRequest{Id: 1, Owner: &owner})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+
+}
+
+func TestListEntries(t *testing.T) {
+	mockAccountService, mockLogger, conn := setup(t)
+	client := pb.NewAccountServiceClient(conn)
+
+	mockLogger.EXPECT().Info("In func() ListEntries :: GRPC LAYER")
+	mockAccountService.EXPECT().GetEntries(gomock.Any(), 1).Return([]models.Entry{
+		{Id: 1, AccountID: 1, Amount: 100, ReasonCode: "OPENING_BALANCE"},
+		{Id: 2, AccountID: 1, Amount: -40},
+	}, nil)
+	stream, err := client.ListEntries(context.Background(), &pb.ListEntriesRequest{AccountId: 1})
+	assert.Equal(t, err, nil)
+	var amounts []float64
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.Equal(t, err, nil)
+		amounts = append(amounts, res.Entry.Amount)
+	}
+	assert.Equal(t, amounts, []float64{100, -40})
+
+	//Failure case: unknown account
+	mockLogger.EXPECT().Info("In func() ListEntries :: GRPC LAYER")
+	mockAccountService.EXPECT().GetEntries(gomock.Any(), 2).Return(nil, service.ErrAccountNotFound)
+	stream, _ = client.ListEntries(context.Background(), &pb.ListEntriesRequest{AccountId: 2})
+	_, err = stream.Recv()
+	assert.Equal(t, status.Code(err), codes.NotFound)
+}
+
+func TestCreateTransfer(t *testing.T) {
+	mockAccountService, mockLogger, conn := setup(t)
+	client := pb.NewTransferServiceClient(conn)
+
+	req := &request.TransferRequest{FromAccountID: 1, ToAccountID: 2, Amount: 25, Currency: "USD"}
+	mockLogger.EXPECT().Info("In func() CreateTransfer :: GRPC LAYER")
+	mockAccountService.EXPECT().CreateTransfer(gomock.Any(), req).
+		Return(models.Transfer{Id: 3, FromAccountID: 1, ToAccountID: 2, Amount: 25}, nil)
+	res, err := client.CreateTransfer(context.Background(), &pb.CreateTransferRequest{
+		FromAccountId: 1, ToAccountId: 2, Amount: 25, Currency: "USD",
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, res.Transfer.Id, int64(3))
+
+	//Failure case: non positive amount, rejected by the service
+	mockLogger.EXPECT().Info("In func() CreateTransfer :: GRPC LAYER")
+	mockAccountService.EXPECT().CreateTransfer(gomock.Any(), &request.TransferRequest{FromAccountID: 1, ToAccountID: 2}).
+		Return(models.Transfer{}, fmt.Errorf("%w: amount must be positive", service.ErrInvalidTransfer))
+	_, err = client.CreateTransfer(context.Background(), &pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 2})
+	assert.Equal(t, status.Code(err), codes.InvalidArgument)
+	assert.Equal(t, status.Convert(err).Message(), "invalid transfer: amount must be positive")
+
+}
@@ -0,0 +1,29 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	pb "github.com/rahul-024/fund-transfer-poc/proto/fundtransfer/v1"
+	"github.com/rahul-024/fund-transfer-poc/service"
+)
+
+type transferServer struct {
+	pb.UnimplementedTransferServiceServer
+	accountService service.AccountService
+}
+
+func (s *transferServer) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	logger.FromContext(ctx).Info("In func() CreateTransfer :: GRPC LAYER")
+	transfer, err := s.accountService.CreateTransfer(ctx, &request.TransferRequest{
+		FromAccountID: int(req.GetFromAccountId()),
+		ToAccountID:   int(req.GetToAccountId()),
+		Amount:        req.GetAmount(),
+		Currency:      req.GetCurrency(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CreateTransferResponse{Transfer: toProtoTransfer(transfer)}, nil
+}
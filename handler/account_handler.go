@@ -1,15 +1,17 @@
 package handler
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/middleware"
 	"github.com/rahul-024/fund-transfer-poc/models"
 	"github.com/rahul-024/fund-transfer-poc/models/request"
 	"github.com/rahul-024/fund-transfer-poc/service"
-	"gorm.io/gorm"
 )
 
 type AccountHandler interface {
@@ -18,6 +20,9 @@ type AccountHandler interface {
 	GetAccountById(*gin.Context)
 	DeleteAccountById(*gin.Context)
 	UpdateAccountById(*gin.Context)
+	PatchAccountById(*gin.Context)
+	AdjustBalance(*gin.Context)
+	SetTransferLimit(*gin.Context)
 	SaveTransfer(*gin.Context)
 }
 
@@ -32,9 +37,8 @@ func NewAccountHandler(s service.AccountService) AccountHandler {
 }
 
 type CreateAccountInput struct {
-	Currency string  `json:"currency" binding:"required"`
-	Owner    string  `json:"owner" binding:"required"`
-	Balance  float64 `json:"balance"`
+	Currency string `json:"currency" binding:"required"`
+	Owner    string `json:"owner" binding:"required"`
 } // @name CreateAccountInput
 
 // PostAccount             godoc
@@ -46,59 +50,69 @@ type CreateAccountInput struct {
 //	@Produce		json
 //	@Param			account	body		CreateAccountInput	true	"Account JSON"
 //	@Success		201		{object}	CreateAccountInput
-//	@Failure		400		{string}	string	"Bad/Invalid request"
-//	@Failure		500		{string}	string	"Resource not found"
-//	@Failure		500		{string}	string	"Internal server error"
+//	@Failure		400		{object}	Problem	"Bad/Invalid request"
+//	@Failure		401		{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403		{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429		{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		500		{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
 //	@Router			/accounts [post]
 func (a accountHandler) CreateAccount(c *gin.Context) {
-	logger.Log.Info("In func() CreateAccount :: HANDLER LAYER")
+	logger.FromContext(c.Request.Context()).Info("In func() CreateAccount :: HANDLER LAYER")
 	var input CreateAccountInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(fmt.Errorf("%w: %v", service.ErrInvalidRequest, err))
 		return
 	}
 
 	account := models.Account{Currency: input.Currency, Owner: input.Owner}
-	account, err := a.accountService.SaveAccount(account)
+	account, err := a.accountService.SaveAccount(c.Request.Context(), account)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Error while saving user"})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{"data": account})
 }
 
-type getAccountsRequest struct {
-	PageID   int `form:"page_id" binding:"required,min=1"`
-	PageSize int `form:"page_size" binding:"required,min=5,max=10"`
-} // @name ListAccountRequest
-
 // GetAccounts             godoc
 //
-//	@Summary		Get Accounts based on pageId and size
-//	@Description	Responds with the list of all accounts as JSON.
+//	@Summary		List accounts
+//	@Description	Responds with one page of accounts as JSON. Pages are addressed either with the opaque next_cursor of the previous page or with page_id.
 //	@Tags			accounts
 //	@Produce		json
-//	@Param			page_id	query	int	true	"Provide the pageId from where the records needs to be returned"
-//	@Param			page_size query	int	true	"provide the size of the page"
-//	@Success		200	{object}	getAccountsRequest
-//	@Failure		400	{string}	string	"Bad/Invalid request"
-//	@Failure		500	{string}	string	"Resource not found"
-//	@Failure		500	{string}	string	"Internal server error"
+//	@Param			cursor			query		string	false	"next_cursor returned with the previous page"
+//	@Param			page_id			query		int		false	"1-based page number, cannot be combined with cursor"
+//	@Param			page_size		query		int		false	"size of the page (1-100, default 10)"
+//	@Param			owner			query		string	false	"filter by owner"
+//	@Param			currency		query		string	false	"filter by currency"
+//	@Param			status			query		string	false	"filter by status (ACTIVE, CLOSED)"
+//	@Param			created_from	query		string	false	"RFC 3339 timestamp, inclusive lower bound of created_at"
+//	@Param			created_to		query		string	false	"RFC 3339 timestamp, exclusive upper bound of created_at"
+//	@Param			sort			query		string	false	"id, created_at, owner or balance, prefix with - for descending order"
+//	@Param			include_total	query		bool	false	"include the total number of matching accounts"
+//	@Success		200				{object}	models.AccountPage
+//	@Failure		400				{object}	Problem	"Bad/Invalid request"
+//	@Failure		401				{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403				{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429				{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		500				{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
 //	@Router			/accounts [get]
 func (a accountHandler) GetAccounts(ctx *gin.Context) {
-	logger.Log.Info("In func() GetAccounts :: HANDLER LAYER")
-	var req getAccountsRequest
-	var accounts []models.Account
+	logger.FromContext(ctx.Request.Context()).Info("In func() GetAccounts :: HANDLER LAYER")
+	var req request.ListAccountsRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(fmt.Errorf("%w: %v", service.ErrInvalidRequest, err))
 		return
 	}
-	accounts, error := a.accountService.GetAll(req.PageID, req.PageSize)
-	if error != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error occurred while fetching accounts"})
+	page, err := a.accountService.GetAll(ctx.Request.Context(), &req)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	ctx.JSON(http.StatusOK, accounts)
+	ctx.JSON(http.StatusOK, page)
 }
 
 // GetAccountById             godoc
@@ -109,21 +123,26 @@ func (a accountHandler) GetAccounts(ctx *gin.Context) {
 //	@Produce		json
 //	@Param			id	path		int	true	"search account by id"
 //	@Success		200	{object}	models.Account
-//	@Failure		400	{string}	string	"Bad/Invalid request"
-//	@Failure		500	{string}	string	"Resource not found"
-//	@Failure		500	{string}	string	"Internal server error"
+//	@Failure		400	{object}	Problem	"Bad/Invalid request"
+//	@Failure		401	{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403	{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429	{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		404	{object}	Problem	"Resource not found"
+//	@Failure		500	{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
 //	@Router			/accounts/{id} [get]
 func (a accountHandler) GetAccountById(ctx *gin.Context) {
-	logger.Log.Info("In func() GetAccountById :: HANDLER LAYER")
+	logger.FromContext(ctx.Request.Context()).Info("In func() GetAccountById :: HANDLER LAYER")
 	var account models.Account
 	intVar, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Path param is not an int"})
+		ctx.Error(fmt.Errorf("%w: path param id is not an int", service.ErrInvalidRequest))
 		return
 	}
-	account, err = a.accountService.GetAccountById(intVar)
+	account, err = a.accountService.GetAccountById(ctx.Request.Context(), intVar)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{"data": account})
@@ -137,29 +156,33 @@ func (a accountHandler) GetAccountById(ctx *gin.Context) {
 //	@Produce		json
 //	@Param			id	path		int	true	"delete account by id"
 //	@Success		200	{string}	string
-//	@Failure		400	{string}	string	"Bad/Invalid request"
-//	@Failure		500	{string}	string	"Resource not found"
-//	@Failure		500	{string}	string	"Internal server error"
+//	@Failure		400	{object}	Problem	"Bad/Invalid request"
+//	@Failure		401	{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403	{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429	{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		404	{object}	Problem	"Resource not found"
+//	@Failure		500	{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
 //	@Router			/accounts/{id} [delete]
 func (a accountHandler) DeleteAccountById(ctx *gin.Context) {
-	logger.Log.Info("In func() DeleteAccountById :: HANDLER LAYER")
+	logger.FromContext(ctx.Request.Context()).Info("In func() DeleteAccountById :: HANDLER LAYER")
 	intVar, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Path param is not an int"})
+		ctx.Error(fmt.Errorf("%w: path param id is not an int", service.ErrInvalidRequest))
 		return
 	}
-	err = a.accountService.DeleteAccountById(intVar)
+	err = a.accountService.DeleteAccountById(ctx.Request.Context(), intVar)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{"data": "Account with id " + ctx.Param("id") + " deleted successfully"})
 }
 
 type UpdateAccountInput struct {
-	Currency string  `json:"currency"`
-	Owner    string  `json:"owner"`
-	Balance  float64 `json:"balance"`
+	Currency string `json:"currency" binding:"required"`
+	Owner    string `json:"owner" binding:"required"`
 } // @name UpdateAccountInput
 
 // UpdateAccountById             godoc
@@ -170,77 +193,211 @@ type UpdateAccountInput struct {
 //		@Produce		json
 //	    @Consume		json
 //		@Param			id	path		int	true	"update account by id"
+//		@Param			account	body	UpdateAccountInput	true	"Account JSON"
 //		@Success		200	{object}	models.Account
-//		@Failure		400	{string}	string	"Bad/Invalid request"
-//		@Failure		500	{string}	string	"Resource not found"
-//		@Failure		500	{string}	string	"Internal server error"
+//		@Failure		400	{object}	Problem	"Bad/Invalid request"
+//		@Failure		401	{object}	Problem	"Missing or invalid credentials"
+//		@Failure		403	{object}	Problem	"Operation not permitted for the caller"
+//		@Failure		429	{object}	Problem	"Too many requests, see Retry-After"
+//		@Failure		404	{object}	Problem	"Resource not found"
+//		@Failure		500	{object}	Problem	"Internal server error"
+//		@Security		BearerAuth
 //		@Router			/accounts/{id} [put]
 func (a accountHandler) UpdateAccountById(ctx *gin.Context) {
-	logger.Log.Info("In func() UpdateAccountById :: HANDLER LAYER")
+	logger.FromContext(ctx.Request.Context()).Info("In func() UpdateAccountById :: HANDLER LAYER")
 	intVar, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Path param is not an int"})
+		ctx.Error(fmt.Errorf("%w: path param id is not an int", service.ErrInvalidRequest))
 		return
 	}
-	account, err := a.accountService.GetAccountById(intVar)
+	account, err := a.accountService.GetAccountById(ctx.Request.Context(), intVar)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	// Validate input
 	var input UpdateAccountInput
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(fmt.Errorf("%w: %v", service.ErrInvalidRequest, err))
 		return
 	}
 
-	updatedAccount := models.Account{Currency: input.Currency, Owner: input.Owner, Balance: input.Balance,
-		CreatedAt: account.CreatedAt}
-	updatedAccount, err = a.accountService.UpdateAccountById(account, updatedAccount)
+	updatedAccount := models.Account{Currency: input.Currency, Owner: input.Owner, CreatedAt: account.CreatedAt}
+	updatedAccount, err = a.accountService.UpdateAccountById(ctx.Request.Context(), account, updatedAccount)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{"data": updatedAccount})
 }
 
-func (a accountHandler) SaveTransfer(ctx *gin.Context) {
-	logger.Log.Info("In func() SaveTransfer :: HANDLER LAYER")
-	txHandle := ctx.MustGet("db_trx").(*gorm.DB)
+// PatchAccountById             godoc
+//
+//	@Summary		Partially update account by id
+//	@Description	Applies a JSON Merge Patch (RFC 7396) to the owner and currency of an account. The balance cannot be patched.
+//	@Tags			accounts
+//	@Accept			application/merge-patch+json
+//	@Produce		json
+//	@Param			id		path		int		true	"patch account by id"
+//	@Param			patch	body		object	true	"Merge patch document"
+//	@Success		200		{object}	models.Account
+//	@Failure		400		{object}	Problem	"Bad/Invalid request"
+//	@Failure		401		{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403		{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429		{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		404		{object}	Problem	"Resource not found"
+//	@Failure		415		{object}	Problem	"Unsupported media type"
+//	@Failure		500		{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Router			/accounts/{id} [patch]
+func (a accountHandler) PatchAccountById(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() PatchAccountById :: HANDLER LAYER")
+	if contentType := ctx.ContentType(); contentType != "application/merge-patch+json" && contentType != "application/json" {
+		middleware.AbortWithProblem(ctx, http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE",
+			"Content-Type must be application/merge-patch+json")
+		return
+	}
+	intVar, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(fmt.Errorf("%w: path param id is not an int", service.ErrInvalidRequest))
+		return
+	}
+	var patch map[string]json.RawMessage
+	if err := ctx.ShouldBindJSON(&patch); err != nil {
+		ctx.Error(fmt.Errorf("%w: merge patch document must be a JSON object", service.ErrInvalidPatch))
+		return
+	}
+	account, err := a.accountService.GetAccountById(ctx.Request.Context(), intVar)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	account, err = a.accountService.PatchAccountById(ctx.Request.Context(), account, patch)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": account})
+}
 
-	var input request.TransferRequest
+// AdjustBalance             godoc
+//
+//	@Summary		Adjust the balance of an account
+//	@Description	Credits (positive amount) or debits (negative amount) an account and posts a ledger entry with the reason code.
+//	@Tags			accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int									true	"account id"
+//	@Param			adjustment	body		request.BalanceAdjustmentRequest	true	"Adjustment JSON"
+//	@Success		201			{object}	models.Entry
+//	@Failure		400			{object}	Problem	"Bad/Invalid request"
+//	@Failure		401			{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403			{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429			{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		404			{object}	Problem	"Resource not found"
+//	@Failure		409			{object}	Problem	"Conflicting concurrent update"
+//	@Failure		422			{object}	Problem	"Insufficient funds"
+//	@Failure		500			{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Router			/accounts/{id}/adjustments [post]
+func (a accountHandler) AdjustBalance(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() AdjustBalance :: HANDLER LAYER")
+	intVar, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(fmt.Errorf("%w: path param id is not an int", service.ErrInvalidRequest))
+		return
+	}
+	var input request.BalanceAdjustmentRequest
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(fmt.Errorf("%w: %v", service.ErrInvalidRequest, err))
 		return
 	}
-	if _, err := a.accountService.WithTrx(txHandle).SaveTransfer(&input); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Error while saving transfer"})
-		txHandle.Rollback()
+	entry, err := a.accountService.AdjustBalance(ctx.Request.Context(), intVar, &input)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": entry})
+}
 
-	if err := a.accountService.WithTrx(txHandle).SaveEntry(&input, "DEBIT"); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Error while saving entry for debited account"})
-		txHandle.Rollback()
+// SetTransferLimit             godoc
+//
+//	@Summary		Change the transfer limit of an account
+//	@Description	Sets the largest amount of one transfer out of the account, 0 removes the limit. Transfers above it are rejected with 422 LIMIT_EXCEEDED. Only admins may change limits.
+//	@Tags			accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int							true	"account id"
+//	@Param			limit	body		request.TransferLimitRequest	true	"Limit JSON"
+//	@Success		200		{object}	models.Account
+//	@Failure		400		{object}	Problem	"Bad/Invalid request"
+//	@Failure		401		{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403		{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429		{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		404		{object}	Problem	"Resource not found"
+//	@Failure		500		{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Router			/accounts/{id}/limits [put]
+func (a accountHandler) SetTransferLimit(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() SetTransferLimit :: HANDLER LAYER")
+	intVar, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(fmt.Errorf("%w: path param id is not an int", service.ErrInvalidRequest))
 		return
 	}
-
-	if err := a.accountService.WithTrx(txHandle).SaveEntry(&input, "CREDIT"); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Error while saving entry for credited account"})
-		txHandle.Rollback()
+	var input request.TransferLimitRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(fmt.Errorf("%w: %v", service.ErrInvalidRequest, err))
 		return
 	}
-
-	if err := a.accountService.WithTrx(txHandle).DecrementBalance(input.FromAccountID, input.Amount); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Error while decrementing balance from sender account"})
-		txHandle.Rollback()
+	account, err := a.accountService.SetTransferLimit(ctx.Request.Context(), intVar, &input)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
+	ctx.JSON(http.StatusOK, gin.H{"data": account})
+}
 
-	if err := a.accountService.WithTrx(txHandle).IncrementBalance(input.ToAccountID, input.Amount); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Error while decrementing balance from sender account"})
-		txHandle.Rollback()
+// SaveTransfer             godoc
+//
+//	@Summary		Transfer money between accounts
+//	@Description	Records the transfer with a debit and a credit entry and moves the amount, all in one transaction. Both accounts must exist and share the currency, and the sender must cover the amount. Partners sign the request instead of sending a token: X-Signature is the hex HMAC-SHA256, with their secret, of the method, the path with the query, the timestamp, the nonce and the hex SHA-256 of the body, separated by newlines. Partners only transfer out of the accounts they own, the accounts of owner partner:<id>.
+//	@Tags			transfers
+//	@Accept			json
+//	@Produce		json
+//	@Param			transfer				body		request.TransferRequest	true	"Transfer JSON"
+//	@Param			X-Partner-Id			header		string					false	"Id of the signing partner"
+//	@Param			X-Signature-Timestamp	header		int						false	"Unix seconds of the signature, within the tolerated clock skew"
+//	@Param			X-Signature-Nonce		header		string					false	"Value used once per partner"
+//	@Param			X-Signature				header		string					false	"Signature of the request"
+//	@Success		201			{object}	models.Transfer
+//	@Failure		400			{object}	Problem	"Bad/Invalid request"
+//	@Failure		401			{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403			{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429			{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		404			{object}	Problem	"Resource not found"
+//	@Failure		409			{object}	Problem	"Conflicting concurrent update"
+//	@Failure		422			{object}	Problem	"Insufficient funds or transfer limit exceeded"
+//	@Failure		500			{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Router			/transfers [post]
+func (a accountHandler) SaveTransfer(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() SaveTransfer :: HANDLER LAYER")
+
+	var input request.TransferRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(fmt.Errorf("%w: %v", service.ErrInvalidRequest, err))
+		return
+	}
+	transfer, err := a.accountService.CreateTransfer(ctx.Request.Context(), &input)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": transfer})
 }
@@ -1,6 +1,9 @@
 package handler_test
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -10,48 +13,170 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/rahul-024/fund-transfer-poc/handler"
 	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/middleware"
 	mock "github.com/rahul-024/fund-transfer-poc/mocks"
 	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/service"
 	"gopkg.in/go-playground/assert.v1"
 )
 
+// serve routes the request to the handler behind the error middleware, like the server does
+func serve(method string, path string, h gin.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorMiddleware())
+	router.Handle(method, path, h)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// problem decodes a problem+json response
+func problem(t *testing.T, recorder *httptest.ResponseRecorder) middleware.Problem {
+	assert.Equal(t, recorder.Header().Get("Content-Type"), middleware.ProblemContentType)
+	var p middleware.Problem
+	if err := json.Unmarshal(recorder.Body.Bytes(), &p); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+	return p
+}
+
 func TestCreateAccount(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
 	mockAccountService := mock.NewMockAccountService(mockCtrl)
 	mockLogger := mock.NewMockLogger(mockCtrl)
 	logger.SetLogger(mockLogger)
+	accountHandlerImpl := handler.NewAccountHandler(mockAccountService)
 	//Success case
 	mockLogger.EXPECT().Info("In func() CreateAccount :: HANDLER LAYER")
-	recorder := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(recorder)
 	jsonParam := `{"Currency":"USD","Owner":"rahul","Balance": 0.0}`
-	req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader(string(jsonParam)))
-	c.Request = req
+	req := httptest.NewRequest(http.MethodPost, "/accounts", strings.NewReader(string(jsonParam)))
 	account := models.Account{Currency: "USD", Owner: "rahul", Balance: 0.0}
-	mockAccountService.EXPECT().SaveAccount(account).Return(models.Account{Currency: "USD", Owner: "rahul", Balance: 24}, nil).Times(1)
-	accountHandlerImpl := handler.NewAccountHandler(mockAccountService)
-	accountHandlerImpl.CreateAccount(c)
+	mockAccountService.EXPECT().SaveAccount(gomock.Any(), account).Return(models.Account{Currency: "USD", Owner: "rahul", Balance: 24}, nil).Times(1)
+	recorder := serve(http.MethodPost, "/accounts", accountHandlerImpl.CreateAccount, req)
+	assert.Equal(t, 201, recorder.Code)
 
 	//Failure case(1)
 	jsonParam = `{}`
-	recorder = httptest.NewRecorder()
-	c, _ = gin.CreateTestContext(recorder)
 	mockLogger.EXPECT().Info("In func() CreateAccount :: HANDLER LAYER")
-	req = httptest.NewRequest(http.MethodGet, "/", strings.NewReader(string(jsonParam)))
-	c.Request = req
-	accountHandlerImpl.CreateAccount(c)
+	req = httptest.NewRequest(http.MethodPost, "/accounts", strings.NewReader(string(jsonParam)))
+	recorder = serve(http.MethodPost, "/accounts", accountHandlerImpl.CreateAccount, req)
 	assert.Equal(t, 400, recorder.Code)
+	assert.Equal(t, problem(t, recorder).Code, "INVALID_REQUEST")
 
-	//Failure case(2)
+	//Failure case(2) - the cause of unexpected errors is logged, not returned
 	jsonParam = `{"Currency":"USD","Owner":"rahul","Balance": 0.0}`
-	recorder = httptest.NewRecorder()
-	c, _ = gin.CreateTestContext(recorder)
 	mockLogger.EXPECT().Info("In func() CreateAccount :: HANDLER LAYER")
-	req = httptest.NewRequest(http.MethodGet, "/", strings.NewReader(string(jsonParam)))
-	c.Request = req
-	mockAccountService.EXPECT().SaveAccount(account).
-		Return(models.Account{Currency: "USD", Owner: "rahul", Balance: 24}, nil)
-	accountHandlerImpl.CreateAccount(c)
+	mockLogger.EXPECT().With("method", http.MethodPost, "path", "/accounts", "error", gomock.Any()).Return(mockLogger)
+	mockLogger.EXPECT().Error("request failed").Times(1)
+	req = httptest.NewRequest(http.MethodPost, "/accounts", strings.NewReader(string(jsonParam)))
+	mockAccountService.EXPECT().SaveAccount(gomock.Any(), account).
+		Return(models.Account{}, errors.New("insert failed"))
+	recorder = serve(http.MethodPost, "/accounts", accountHandlerImpl.CreateAccount, req)
+	assert.Equal(t, 500, recorder.Code)
+	p := problem(t, recorder)
+	assert.Equal(t, p.Code, "INTERNAL_ERROR")
+	assert.Equal(t, strings.Contains(recorder.Body.String(), "insert failed"), false)
+}
+
+func TestGetAccountById(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockAccountService := mock.NewMockAccountService(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	accountHandlerImpl := handler.NewAccountHandler(mockAccountService)
+
+	//Failure case(1) - the account does not exist
+	mockLogger.EXPECT().Info("In func() GetAccountById :: HANDLER LAYER")
+	mockAccountService.EXPECT().GetAccountById(gomock.Any(), 7).Return(models.Account{}, service.ErrAccountNotFound)
+	recorder := serve(http.MethodGet, "/accounts/:id", accountHandlerImpl.GetAccountById,
+		httptest.NewRequest(http.MethodGet, "/accounts/7", nil))
+	assert.Equal(t, 404, recorder.Code)
+	p := problem(t, recorder)
+	assert.Equal(t, p.Status, 404)
+	assert.Equal(t, p.Title, "Not Found")
+	assert.Equal(t, p.Code, "ACCOUNT_NOT_FOUND")
+	assert.Equal(t, p.Instance, "/accounts/7")
+
+	//Failure case(2) - the id is not a number
+	mockLogger.EXPECT().Info("In func() GetAccountById :: HANDLER LAYER")
+	recorder = serve(http.MethodGet, "/accounts/:id", accountHandlerImpl.GetAccountById,
+		httptest.NewRequest(http.MethodGet, "/accounts/seven", nil))
 	assert.Equal(t, 400, recorder.Code)
+	assert.Equal(t, problem(t, recorder).Code, "INVALID_REQUEST")
+}
+
+func TestSaveTransfer(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockAccountService := mock.NewMockAccountService(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	accountHandlerImpl := handler.NewAccountHandler(mockAccountService)
+	body := `{"from_account_id":1,"to_account_id":2,"amount":50}`
+
+	for _, tc := range []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("%w: balance of account 1 is lower than 50.00", service.ErrInsufficientFunds), 422, "INSUFFICIENT_FUNDS"},
+		{fmt.Errorf("%w: receiver account 2 does not exist", service.ErrAccountNotFound), 404, "ACCOUNT_NOT_FOUND"},
+		{service.ErrTransactionConflict, 409, "TRANSACTION_CONFLICT"},
+	} {
+		mockLogger.EXPECT().Info("In func() SaveTransfer :: HANDLER LAYER")
+		mockAccountService.EXPECT().CreateTransfer(gomock.Any(), gomock.Any()).Return(models.Transfer{}, tc.err)
+		recorder := serve(http.MethodPost, "/transfers", accountHandlerImpl.SaveTransfer,
+			httptest.NewRequest(http.MethodPost, "/transfers", strings.NewReader(body)))
+		assert.Equal(t, recorder.Code, tc.status)
+		p := problem(t, recorder)
+		assert.Equal(t, p.Code, tc.code)
+		assert.Equal(t, p.Detail, tc.err.Error())
+	}
+}
+
+func TestPatchAccountById(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockAccountService := mock.NewMockAccountService(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	accountHandlerImpl := handler.NewAccountHandler(mockAccountService)
+	account := models.Account{Id: 1, Currency: "USD", Owner: "rahul", Balance: 10}
+
+	//Success case
+	mockLogger.EXPECT().Info("In func() PatchAccountById :: HANDLER LAYER")
+	req := httptest.NewRequest(http.MethodPatch, "/accounts/1", strings.NewReader(`{"owner":"mike"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	patch := map[string]json.RawMessage{"owner": json.RawMessage(`"mike"`)}
+	mockAccountService.EXPECT().GetAccountById(gomock.Any(), 1).Return(account, nil)
+	mockAccountService.EXPECT().PatchAccountById(gomock.Any(), account, patch).
+		Return(models.Account{Id: 1, Currency: "USD", Owner: "mike", Balance: 10}, nil)
+	recorder := serve(http.MethodPatch, "/accounts/:id", accountHandlerImpl.PatchAccountById, req)
+	assert.Equal(t, 200, recorder.Code)
+
+	//Failure case(1) - not a JSON object
+	mockLogger.EXPECT().Info("In func() PatchAccountById :: HANDLER LAYER")
+	req = httptest.NewRequest(http.MethodPatch, "/accounts/1", strings.NewReader(`["owner"]`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	recorder = serve(http.MethodPatch, "/accounts/:id", accountHandlerImpl.PatchAccountById, req)
+	assert.Equal(t, 400, recorder.Code)
+
+	//Failure case(2) - field rejected by the service
+	mockLogger.EXPECT().Info("In func() PatchAccountById :: HANDLER LAYER")
+	req = httptest.NewRequest(http.MethodPatch, "/accounts/1", strings.NewReader(`{"balance":100}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	mockAccountService.EXPECT().GetAccountById(gomock.Any(), 1).Return(account, nil)
+	mockAccountService.EXPECT().PatchAccountById(gomock.Any(), account, gomock.Any()).
+		Return(account, fmt.Errorf("%w: field \"balance\" cannot be patched", service.ErrInvalidPatch))
+	recorder = serve(http.MethodPatch, "/accounts/:id", accountHandlerImpl.PatchAccountById, req)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Equal(t, problem(t, recorder).Detail, `invalid merge patch: field "balance" cannot be patched`)
+
+	//Failure case(3) - unsupported content type
+	mockLogger.EXPECT().Info("In func() PatchAccountById :: HANDLER LAYER")
+	req = httptest.NewRequest(http.MethodPatch, "/accounts/1", strings.NewReader(`owner=mike`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder = serve(http.MethodPatch, "/accounts/:id", accountHandlerImpl.PatchAccountById, req)
+	assert.Equal(t, 415, recorder.Code)
+	assert.Equal(t, problem(t, recorder).Code, "UNSUPPORTED_MEDIA_TYPE")
 }
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/service"
+)
+
+type APIKeyHandler interface {
+	CreateAPIKey(*gin.Context)
+	GetAPIKeys(*gin.Context)
+	RevokeAPIKeyById(*gin.Context)
+}
+
+type apiKeyHandler struct {
+	apiKeyService service.APIKeyService
+}
+
+func NewAPIKeyHandler(s service.APIKeyService) APIKeyHandler {
+	return apiKeyHandler{
+		apiKeyService: s,
+	}
+}
+
+// CreateAPIKey             godoc
+//
+//	@Summary		Issue an API key
+//	@Description	Issues a key for a machine client, sent in the X-API-Key header. Scopes: accounts:read, accounts:write, transfers:write, audit:read, webhooks:manage. The key is only returned here.
+//	@Tags			api-keys
+//	@Accept			json
+//	@Produce		json
+//	@Param			key	body		request.CreateAPIKeyRequest	true	"API key JSON"
+//	@Success		201	{object}	models.APIKey
+//	@Failure		400	{object}	Problem	"Bad/Invalid request"
+//	@Failure		401	{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403	{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429	{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		500	{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Router			/api-keys [post]
+func (a apiKeyHandler) CreateAPIKey(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() CreateAPIKey :: HANDLER LAYER")
+	var input request.CreateAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(fmt.Errorf("%w: %v", service.ErrInvalidRequest, err))
+		return
+	}
+	key, err := a.apiKeyService.CreateAPIKey(ctx.Request.Context(), &input)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": key})
+}
+
+// GetAPIKeys             godoc
+//
+//	@Summary		List API keys
+//	@Description	Lists the issued keys, revoked ones included, with the time they were last used
+//	@Tags			api-keys
+//	@Produce		json
+//	@Success		200	{array}		models.APIKey
+//	@Failure		401	{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403	{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429	{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		500	{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Router			/api-keys [get]
+func (a apiKeyHandler) GetAPIKeys(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() GetAPIKeys :: HANDLER LAYER")
+	keys, err := a.apiKeyService.GetAPIKeys(ctx.Request.Context())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": keys})
+}
+
+// RevokeAPIKeyById             godoc
+//
+//	@Summary		Revoke API key by id
+//	@Description	Requests sent with the key are rejected from then on, the key stays listed
+//	@Tags			api-keys
+//	@Produce		json
+//	@Param			id	path		int	true	"API key id"
+//	@Success		200	{object}	models.APIKey
+//	@Failure		400	{object}	Problem	"Bad/Invalid request"
+//	@Failure		401	{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403	{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429	{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		404	{object}	Problem	"Resource not found"
+//	@Security		BearerAuth
+//	@Router			/api-keys/{id} [delete]
+func (a apiKeyHandler) RevokeAPIKeyById(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() RevokeAPIKeyById :: HANDLER LAYER")
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(fmt.Errorf("%w: path param id is not an int", service.ErrInvalidRequest))
+		return
+	}
+	key, err := a.apiKeyService.RevokeAPIKeyById(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": key})
+}
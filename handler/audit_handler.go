@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/service"
+)
+
+type AuditHandler interface {
+	GetAuditEvents(*gin.Context)
+}
+
+type auditHandler struct {
+	auditService service.AuditService
+}
+
+func NewAuditHandler(s service.AuditService) AuditHandler {
+	return auditHandler{
+		auditService: s,
+	}
+}
+
+// GetAuditEvents             godoc
+//
+//	@Summary		List audit events
+//	@Description	Responds with the audit events matching the filters, newest first.
+//	@Tags			audit
+//	@Produce		json
+//	@Param			actor		query		string	false	"filter by actor"
+//	@Param			operation	query		string	false	"filter by operation, e.g. ACCOUNT_UPDATED"
+//	@Param			entity_type	query		string	false	"filter by entity type (account, transfer)"
+//	@Param			entity_id	query		int		false	"filter by entity id"
+//	@Param			request_id	query		string	false	"filter by request id"
+//	@Param			from		query		string	false	"RFC 3339 timestamp, inclusive lower bound"
+//	@Param			to			query		string	false	"RFC 3339 timestamp, exclusive upper bound"
+//	@Param			page_id		query		int		false	"1-based page number"
+//	@Param			page_size	query		int		false	"size of the page (1-100, default 10)"
+//	@Success		200			{array}		models.AuditEvent
+//	@Failure		400			{object}	Problem	"Bad/Invalid request"
+//	@Failure		401			{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403			{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429			{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		500			{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Router			/audit [get]
+func (a auditHandler) GetAuditEvents(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() GetAuditEvents :: HANDLER LAYER")
+	var req request.ListAuditEventsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(fmt.Errorf("%w: %v", service.ErrInvalidRequest, err))
+		return
+	}
+	events, err := a.auditService.GetAuditEvents(ctx.Request.Context(), &req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": events})
+}
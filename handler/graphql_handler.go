@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-024/fund-transfer-poc/graph"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/service"
+)
+
+type GraphqlHandler interface {
+	Query(*gin.Context)
+}
+
+type graphqlHandler struct {
+	schema *graph.Schema
+}
+
+func NewGraphqlHandler(s *graph.Schema) GraphqlHandler {
+	return graphqlHandler{
+		schema: s,
+	}
+}
+
+// GraphqlRequest is the body of a GraphQL request sent over HTTP
+type GraphqlRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Query executes a GraphQL query or mutation. Errors of the operation are reported in the errors
+// member of the response with status 200, as GraphQL clients expect.
+func (g graphqlHandler) Query(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() Query :: HANDLER LAYER")
+	var req GraphqlRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(fmt.Errorf("%w: %v", service.ErrInvalidRequest, err))
+		return
+	}
+	response := g.schema.Exec(ctx.Request.Context(), req.Query, req.OperationName, req.Variables)
+	ctx.JSON(http.StatusOK, response)
+}
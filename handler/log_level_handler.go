@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/service"
+)
+
+type LogLevelHandler interface {
+	GetLogLevel(*gin.Context)
+	SetLogLevel(*gin.Context)
+	ResetLogLevel(*gin.Context)
+}
+
+type logLevelHandler struct {
+	logLevelService service.LogLevelService
+}
+
+func NewLogLevelHandler(s service.LogLevelService) LogLevelHandler {
+	return logLevelHandler{
+		logLevelService: s,
+	}
+}
+
+// GetLogLevel             godoc
+//
+//	@Summary		Get the log level
+//	@Description	Responds with the log level of the instance answering, the configured one and when its override expires
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{object}	models.LogLevel
+//	@Failure		401	{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403	{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429	{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		500	{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Router			/admin/log-level [get]
+func (a logLevelHandler) GetLogLevel(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() GetLogLevel :: HANDLER LAYER")
+	level, err := a.logLevelService.GetLogLevel(ctx.Request.Context())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": level})
+}
+
+// SetLogLevel             godoc
+//
+//	@Summary		Override the log level
+//	@Description	Sets the log level of the instance answering for ttl_seconds, 15 minutes by default, then the configured level is back. A new override replaces the current one.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			level	body		request.LogLevelRequest	true	"Log level JSON"
+//	@Success		200		{object}	models.LogLevel
+//	@Failure		400		{object}	Problem	"Bad/Invalid request"
+//	@Failure		401		{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403		{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429		{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		500		{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Router			/admin/log-level [put]
+func (a logLevelHandler) SetLogLevel(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() SetLogLevel :: HANDLER LAYER")
+	var input request.LogLevelRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(fmt.Errorf("%w: %v", service.ErrInvalidRequest, err))
+		return
+	}
+	level, err := a.logLevelService.SetLogLevel(ctx.Request.Context(), &input)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": level})
+}
+
+// ResetLogLevel             godoc
+//
+//	@Summary		Reset the log level
+//	@Description	Ends the override of the log level of the instance answering, the configured level is in effect again
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{object}	models.LogLevel
+//	@Failure		401	{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403	{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429	{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		500	{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Router			/admin/log-level [delete]
+func (a logLevelHandler) ResetLogLevel(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() ResetLogLevel :: HANDLER LAYER")
+	level, err := a.logLevelService.ResetLogLevel(ctx.Request.Context())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": level})
+}
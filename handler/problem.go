@@ -0,0 +1,7 @@
+package handler
+
+import "github.com/rahul-024/fund-transfer-poc/middleware"
+
+// Problem is the body of the error responses, the handlers attach their errors with gin.Context.Error
+// and middleware.ErrorMiddleware renders them
+type Problem = middleware.Problem
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/service"
+)
+
+type WebhookHandler interface {
+	CreateSubscription(*gin.Context)
+	GetSubscriptions(*gin.Context)
+	GetSubscriptionById(*gin.Context)
+	DeleteSubscriptionById(*gin.Context)
+	GetDeliveries(*gin.Context)
+	GetDeliveryAttempts(*gin.Context)
+	Redeliver(*gin.Context)
+}
+
+type webhookHandler struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookHandler(s service.WebhookService) WebhookHandler {
+	return webhookHandler{
+		webhookService: s,
+	}
+}
+
+// CreateSubscription             godoc
+//
+//	@Summary		Subscribe a URL to events
+//	@Description	Registers a URL for event types ("*" for all). Deliveries are signed with HMAC-SHA256 of "<X-Webhook-Timestamp>.<body>" in X-Webhook-Signature. The secret is only returned here. URLs of loopback, private or link-local addresses are rejected. Callers restricted to their own accounts by their scopes create subscriptions that only receive the events of those accounts. A subscription receives every TransferCompleted once, the copy of the sender account unless it only sees the receiver.
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			subscription	body		request.CreateWebhookSubscriptionRequest	true	"Subscription JSON"
+//	@Success		201				{object}	models.WebhookSubscription
+//	@Failure		400				{object}	Problem	"Bad/Invalid request"
+//	@Failure		401				{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403				{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429				{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		500				{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Router			/webhooks [post]
+func (w webhookHandler) CreateSubscription(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() CreateSubscription :: HANDLER LAYER")
+	var input request.CreateWebhookSubscriptionRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(fmt.Errorf("%w: %v", service.ErrInvalidRequest, err))
+		return
+	}
+	subscription, err := w.webhookService.CreateSubscription(ctx.Request.Context(), &input)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": subscription})
+}
+
+// GetSubscriptions             godoc
+//
+//	@Summary		List webhook subscriptions
+//	@Tags			webhooks
+//	@Produce		json
+//	@Success		200	{array}		models.WebhookSubscription
+//	@Failure		401	{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403	{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429	{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		500	{object}	Problem	"Internal server error"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Router			/webhooks [get]
+func (w webhookHandler) GetSubscriptions(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() GetSubscriptions :: HANDLER LAYER")
+	subscriptions, err := w.webhookService.GetSubscriptions(ctx.Request.Context())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": subscriptions})
+}
+
+// GetSubscriptionById             godoc
+//
+//	@Summary		Get webhook subscription by id
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			id	path		int	true	"subscription id"
+//	@Success		200	{object}	models.WebhookSubscription
+//	@Failure		400	{object}	Problem	"Bad/Invalid request"
+//	@Failure		401	{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403	{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429	{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		404	{object}	Problem	"Resource not found"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Router			/webhooks/{id} [get]
+func (w webhookHandler) GetSubscriptionById(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() GetSubscriptionById :: HANDLER LAYER")
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(fmt.Errorf("%w: path param id is not an int", service.ErrInvalidRequest))
+		return
+	}
+	subscription, err := w.webhookService.GetSubscriptionById(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": subscription})
+}
+
+// DeleteSubscriptionById             godoc
+//
+//	@Summary		Delete webhook subscription by id
+//	@Description	Deletes the subscription together with its deliveries
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			id	path		int	true	"subscription id"
+//	@Success		200	{string}	string
+//	@Failure		400	{object}	Problem	"Bad/Invalid request"
+//	@Failure		401	{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403	{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429	{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		404	{object}	Problem	"Resource not found"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Router			/webhooks/{id} [delete]
+func (w webhookHandler) DeleteSubscriptionById(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() DeleteSubscriptionById :: HANDLER LAYER")
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(fmt.Errorf("%w: path param id is not an int", service.ErrInvalidRequest))
+		return
+	}
+	if err = w.webhookService.DeleteSubscriptionById(ctx.Request.Context(), id); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": "Subscription with id " + ctx.Param("id") + " deleted successfully"})
+}
+
+// GetDeliveries             godoc
+//
+//	@Summary		List the deliveries of a subscription
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			id	path		int	true	"subscription id"
+//	@Success		200	{array}		models.WebhookDelivery
+//	@Failure		400	{object}	Problem	"Bad/Invalid request"
+//	@Failure		401	{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403	{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429	{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		404	{object}	Problem	"Resource not found"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Router			/webhooks/{id}/deliveries [get]
+func (w webhookHandler) GetDeliveries(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() GetDeliveries :: HANDLER LAYER")
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(fmt.Errorf("%w: path param id is not an int", service.ErrInvalidRequest))
+		return
+	}
+	deliveries, err := w.webhookService.GetDeliveries(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": deliveries})
+}
+
+// GetDeliveryAttempts             godoc
+//
+//	@Summary		List the attempts made for a delivery
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			id			path		int	true	"subscription id"
+//	@Param			deliveryId	path		int	true	"delivery id"
+//	@Success		200			{array}		models.WebhookDeliveryAttempt
+//	@Failure		400			{object}	Problem	"Bad/Invalid request"
+//	@Failure		401			{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403			{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429			{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		404			{object}	Problem	"Resource not found"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Router			/webhooks/{id}/deliveries/{deliveryId}/attempts [get]
+func (w webhookHandler) GetDeliveryAttempts(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() GetDeliveryAttempts :: HANDLER LAYER")
+	id, errId := strconv.Atoi(ctx.Param("id"))
+	deliveryId, errDeliveryId := strconv.Atoi(ctx.Param("deliveryId"))
+	if errId != nil || errDeliveryId != nil {
+		ctx.Error(fmt.Errorf("%w: path params id and deliveryId must be ints", service.ErrInvalidRequest))
+		return
+	}
+	attempts, err := w.webhookService.GetDeliveryAttempts(ctx.Request.Context(), id, deliveryId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": attempts})
+}
+
+// Redeliver             godoc
+//
+//	@Summary		Queue a delivery again
+//	@Description	Resets the delivery to pending with a fresh retry budget, also for deliveries that already succeeded
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			id			path		int	true	"subscription id"
+//	@Param			deliveryId	path		int	true	"delivery id"
+//	@Success		202			{object}	models.WebhookDelivery
+//	@Failure		400			{object}	Problem	"Bad/Invalid request"
+//	@Failure		401			{object}	Problem	"Missing or invalid credentials"
+//	@Failure		403			{object}	Problem	"Operation not permitted for the caller"
+//	@Failure		429			{object}	Problem	"Too many requests, see Retry-After"
+//	@Failure		404			{object}	Problem	"Resource not found"
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Router			/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func (w webhookHandler) Redeliver(ctx *gin.Context) {
+	logger.FromContext(ctx.Request.Context()).Info("In func() Redeliver :: HANDLER LAYER")
+	id, errId := strconv.Atoi(ctx.Param("id"))
+	deliveryId, errDeliveryId := strconv.Atoi(ctx.Param("deliveryId"))
+	if errId != nil || errDeliveryId != nil {
+		ctx.Error(fmt.Errorf("%w: path params id and deliveryId must be ints", service.ErrInvalidRequest))
+		return
+	}
+	delivery, err := w.webhookService.Redeliver(ctx.Request.Context(), id, deliveryId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusAccepted, gin.H{"data": delivery})
+}
@@ -0,0 +1,140 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/handler"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mock "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestCreateSubscription(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockWebhookService := mock.NewMockWebhookService(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	webhookHandlerImpl := handler.NewWebhookHandler(mockWebhookService)
+
+	//Success case - the secret is only returned here
+	mockLogger.EXPECT().Info("In func() CreateSubscription :: HANDLER LAYER")
+	input := request.CreateWebhookSubscriptionRequest{Url: "https://203.0.113.10/hooks", EventTypes: []string{"*"}}
+	mockWebhookService.EXPECT().CreateSubscription(gomock.Any(), &input).
+		Return(models.WebhookSubscription{Id: 1, Url: input.Url, EventTypes: input.EventTypes, Secret: "whsec_0123456789abcdef", Active: true}, nil)
+	recorder := serve(http.MethodPost, "/webhooks", webhookHandlerImpl.CreateSubscription,
+		httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"url":"https://203.0.113.10/hooks","event_types":["*"]}`)))
+	assert.Equal(t, 201, recorder.Code)
+	assert.Equal(t, strings.Contains(recorder.Body.String(), `"secret":"whsec_0123456789abcdef"`), true)
+
+	//Failure case(1) - the event types are missing
+	mockLogger.EXPECT().Info("In func() CreateSubscription :: HANDLER LAYER")
+	recorder = serve(http.MethodPost, "/webhooks", webhookHandlerImpl.CreateSubscription,
+		httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"url":"https://203.0.113.10/hooks"}`)))
+	assert.Equal(t, 400, recorder.Code)
+	assert.Equal(t, problem(t, recorder).Code, "INVALID_REQUEST")
+
+	//Failure case(2) - the url is rejected by the service
+	mockLogger.EXPECT().Info("In func() CreateSubscription :: HANDLER LAYER")
+	mockWebhookService.EXPECT().CreateSubscription(gomock.Any(), gomock.Any()).
+		Return(models.WebhookSubscription{}, fmt.Errorf("%w: webhook address is not allowed: 169.254.169.254", service.ErrInvalidSubscription))
+	recorder = serve(http.MethodPost, "/webhooks", webhookHandlerImpl.CreateSubscription,
+		httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"url":"http://169.254.169.254/","event_types":["*"]}`)))
+	assert.Equal(t, 400, recorder.Code)
+	assert.Equal(t, problem(t, recorder).Code, "INVALID_SUBSCRIPTION")
+}
+
+func TestGetSubscriptions(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockWebhookService := mock.NewMockWebhookService(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	webhookHandlerImpl := handler.NewWebhookHandler(mockWebhookService)
+
+	//Success case
+	mockLogger.EXPECT().Info("In func() GetSubscriptions :: HANDLER LAYER")
+	mockWebhookService.EXPECT().GetSubscriptions(gomock.Any()).
+		Return([]models.WebhookSubscription{{Id: 1, Url: "https://203.0.113.10/hooks", EventTypes: []string{"*"}, Active: true}}, nil)
+	recorder := serve(http.MethodGet, "/webhooks", webhookHandlerImpl.GetSubscriptions,
+		httptest.NewRequest(http.MethodGet, "/webhooks", nil))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, strings.Contains(recorder.Body.String(), `"url":"https://203.0.113.10/hooks"`), true)
+
+	//Failure case - the caller may not manage webhooks
+	mockLogger.EXPECT().Info("In func() GetSubscriptions :: HANDLER LAYER")
+	mockWebhookService.EXPECT().GetSubscriptions(gomock.Any()).Return(nil, service.ErrForbidden)
+	recorder = serve(http.MethodGet, "/webhooks", webhookHandlerImpl.GetSubscriptions,
+		httptest.NewRequest(http.MethodGet, "/webhooks", nil))
+	assert.Equal(t, 403, recorder.Code)
+	assert.Equal(t, problem(t, recorder).Code, "FORBIDDEN")
+}
+
+func TestDeleteSubscriptionById(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockWebhookService := mock.NewMockWebhookService(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	webhookHandlerImpl := handler.NewWebhookHandler(mockWebhookService)
+
+	//Success case
+	mockLogger.EXPECT().Info("In func() DeleteSubscriptionById :: HANDLER LAYER")
+	mockWebhookService.EXPECT().DeleteSubscriptionById(gomock.Any(), 1).Return(nil)
+	recorder := serve(http.MethodDelete, "/webhooks/:id", webhookHandlerImpl.DeleteSubscriptionById,
+		httptest.NewRequest(http.MethodDelete, "/webhooks/1", nil))
+	assert.Equal(t, 200, recorder.Code)
+
+	//Failure case(1) - the subscription does not exist
+	mockLogger.EXPECT().Info("In func() DeleteSubscriptionById :: HANDLER LAYER")
+	mockWebhookService.EXPECT().DeleteSubscriptionById(gomock.Any(), 7).Return(service.ErrSubscriptionNotFound)
+	recorder = serve(http.MethodDelete, "/webhooks/:id", webhookHandlerImpl.DeleteSubscriptionById,
+		httptest.NewRequest(http.MethodDelete, "/webhooks/7", nil))
+	assert.Equal(t, 404, recorder.Code)
+	assert.Equal(t, problem(t, recorder).Code, "SUBSCRIPTION_NOT_FOUND")
+
+	//Failure case(2) - the id is not a number
+	mockLogger.EXPECT().Info("In func() DeleteSubscriptionById :: HANDLER LAYER")
+	recorder = serve(http.MethodDelete, "/webhooks/:id", webhookHandlerImpl.DeleteSubscriptionById,
+		httptest.NewRequest(http.MethodDelete, "/webhooks/seven", nil))
+	assert.Equal(t, 400, recorder.Code)
+	assert.Equal(t, problem(t, recorder).Code, "INVALID_REQUEST")
+}
+
+func TestRedeliver(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockWebhookService := mock.NewMockWebhookService(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	webhookHandlerImpl := handler.NewWebhookHandler(mockWebhookService)
+	path := "/webhooks/:id/deliveries/:deliveryId/redeliver"
+
+	//Success case
+	mockLogger.EXPECT().Info("In func() Redeliver :: HANDLER LAYER")
+	mockWebhookService.EXPECT().Redeliver(gomock.Any(), 1, 9).
+		Return(models.WebhookDelivery{Id: 9, SubscriptionID: 1, Status: models.DeliveryPending}, nil)
+	recorder := serve(http.MethodPost, path, webhookHandlerImpl.Redeliver,
+		httptest.NewRequest(http.MethodPost, "/webhooks/1/deliveries/9/redeliver", nil))
+	assert.Equal(t, 202, recorder.Code)
+	assert.Equal(t, strings.Contains(recorder.Body.String(), `"status":"`+models.DeliveryPending+`"`), true)
+
+	//Failure case(1) - the delivery belongs to another subscription
+	mockLogger.EXPECT().Info("In func() Redeliver :: HANDLER LAYER")
+	mockWebhookService.EXPECT().Redeliver(gomock.Any(), 2, 9).Return(models.WebhookDelivery{}, service.ErrDeliveryNotFound)
+	recorder = serve(http.MethodPost, path, webhookHandlerImpl.Redeliver,
+		httptest.NewRequest(http.MethodPost, "/webhooks/2/deliveries/9/redeliver", nil))
+	assert.Equal(t, 404, recorder.Code)
+	assert.Equal(t, problem(t, recorder).Code, "DELIVERY_NOT_FOUND")
+
+	//Failure case(2) - the delivery id is not a number
+	mockLogger.EXPECT().Info("In func() Redeliver :: HANDLER LAYER")
+	recorder = serve(http.MethodPost, path, webhookHandlerImpl.Redeliver,
+		httptest.NewRequest(http.MethodPost, "/webhooks/1/deliveries/nine/redeliver", nil))
+	assert.Equal(t, 400, recorder.Code)
+	assert.Equal(t, problem(t, recorder).Code, "INVALID_REQUEST")
+}
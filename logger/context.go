@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// maxRequestIDLength keeps the ids sent by clients storable
+const maxRequestIDLength = 128
+
+// RequestIDField is the field of the request id in the lines of the request-scoped logger
+const RequestIDField = "request_id"
+
+type contextKey struct{}
+
+// scope is what a context knows about the lines logged for it
+type scope struct {
+	requestID string
+	fields    []interface{}
+}
+
+func scopeOf(ctx context.Context) scope {
+	if ctx == nil {
+		return scope{}
+	}
+	s, _ := ctx.Value(contextKey{}).(scope)
+	return s
+}
+
+// NewContext returns a copy of ctx carrying the id of the request it serves
+func NewContext(ctx context.Context, requestID string) context.Context {
+	s := scopeOf(ctx)
+	s.requestID = requestID
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// WithFields returns a copy of ctx whose logger adds the fields, alternating string keys and values, to
+// every line. Layers use it to tag the lines of the layers below with the ids they work on.
+func WithFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	s := scopeOf(ctx)
+	// the fields of the parent context must not be shared with its other children
+	s.fields = append(append([]interface{}{}, s.fields...), keysAndValues...)
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// RequestID returns the request id stored in ctx, empty when there is none
+func RequestID(ctx context.Context) string {
+	return scopeOf(ctx).requestID
+}
+
+// NewRequestID returns a random id for the requests that come without one
+func NewRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		// the id only correlates log lines, a constant one is better than no request
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
+
+// ValidRequestID accepts the ids sent by clients made of up to 128 printable ASCII characters, so that they
+// cannot forge log lines
+func ValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// FromContext returns the request-scoped logger of ctx, it adds the request id and the fields of ctx to every
+// line. Log is returned as is for contexts without them, like the ones of the background workers.
+func FromContext(ctx context.Context) Logger {
+	s := scopeOf(ctx)
+	fields := s.fields
+	if s.requestID != "" {
+		fields = append([]interface{}{RequestIDField, s.requestID}, fields...)
+	}
+	if len(fields) == 0 {
+		return Log
+	}
+	return Log.With(fields...)
+}
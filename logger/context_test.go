@@ -0,0 +1,43 @@
+package logger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mock "github.com/rahul-024/fund-transfer-poc/mocks"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestFromContext(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	requestLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+
+	//contexts without a request id or fields log through the package logger
+	assert.Equal(t, logger.FromContext(context.Background()), logger.Log)
+
+	ctx := logger.NewContext(context.Background(), "req-1")
+	assert.Equal(t, logger.RequestID(ctx), "req-1")
+	mockLogger.EXPECT().With(logger.RequestIDField, "req-1").Return(requestLogger)
+	requestLogger.EXPECT().Info("In func() SaveTransfer :: SERVICE LAYER")
+	logger.FromContext(ctx).Info("In func() SaveTransfer :: SERVICE LAYER")
+
+	//the fields of a context add up and stay out of its parent
+	tagged := logger.WithFields(logger.WithFields(ctx, "account_id", 7), "amount", 25.0)
+	mockLogger.EXPECT().With(logger.RequestIDField, "req-1", "account_id", 7, "amount", 25.0).Return(requestLogger)
+	requestLogger.EXPECT().Error("transfer failed")
+	logger.FromContext(tagged).Error("transfer failed")
+	mockLogger.EXPECT().With(logger.RequestIDField, "req-1").Return(requestLogger)
+	requestLogger.EXPECT().Warn("retrying")
+	logger.FromContext(ctx).Warn("retrying")
+}
+
+func TestValidRequestID(t *testing.T) {
+	assert.Equal(t, logger.ValidRequestID("0f6e2c1a-4b1d-4d9b-9a55-3c2f7f1f4e21"), true)
+	assert.Equal(t, logger.ValidRequestID(""), false)
+	assert.Equal(t, logger.ValidRequestID("forged\nrequest_id=other"), false)
+	assert.Equal(t, len(logger.NewRequestID()), 32)
+}
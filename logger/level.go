@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrLevelUnsupported is returned when the registered logger cannot change its level at runtime
+var ErrLevelUnsupported = errors.New("the logger cannot change its level at runtime")
+
+// LevelController changes the level of the registered logger, the factories register one along with it
+type LevelController interface {
+	SetLevel(level string) error
+}
+
+// LevelStatus is the level in effect and where it comes from
+type LevelStatus struct {
+	// Level is the level in effect
+	Level string
+	// Configured is the level of the profile, in effect again once the override expires
+	Configured string
+	// OverrideUntil is when the override expires, zero when the configured level is in effect
+	OverrideUntil time.Time
+}
+
+// levels is the state of the level of Log: the configured one and a temporary override
+var levels struct {
+	mu         sync.Mutex
+	controller LevelController
+	configured string
+	override   string
+	until      time.Time
+	revert     *time.Timer
+}
+
+// SetLevelController registers the controller of the level of Log, level being the one Log was built with.
+// Any override of the previous logger is dropped.
+func SetLevelController(controller LevelController, level string) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+	clearOverride()
+	levels.controller = controller
+	levels.configured = level
+}
+
+// SetLevel changes the configured level. It takes effect right away, or when the override expires.
+func SetLevel(level string) error {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+	if levels.controller == nil {
+		return ErrLevelUnsupported
+	}
+	if levels.override == "" {
+		if err := levels.controller.SetLevel(level); err != nil {
+			return err
+		}
+	}
+	levels.configured = level
+	return nil
+}
+
+// OverrideLevel sets the level for ttl, then reverts to the configured level. A new override replaces the
+// current one.
+func OverrideLevel(level string, ttl time.Duration) (LevelStatus, error) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+	if levels.controller == nil {
+		return LevelStatus{}, ErrLevelUnsupported
+	}
+	if err := levels.controller.SetLevel(level); err != nil {
+		return currentLevel(), err
+	}
+	clearOverride()
+	levels.override = level
+	levels.until = time.Now().Add(ttl)
+	var revert *time.Timer
+	revert = time.AfterFunc(ttl, func() {
+		levels.mu.Lock()
+		defer levels.mu.Unlock()
+		// a replaced override must not revert its successor
+		if levels.revert == revert {
+			revertLevel()
+		}
+	})
+	levels.revert = revert
+	return currentLevel(), nil
+}
+
+// ResetLevel ends the override, the configured level is in effect again
+func ResetLevel() (LevelStatus, error) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+	if levels.controller == nil {
+		return LevelStatus{}, ErrLevelUnsupported
+	}
+	if levels.override != "" {
+		revertLevel()
+	}
+	return currentLevel(), nil
+}
+
+// CurrentLevel returns the level in effect
+func CurrentLevel() LevelStatus {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+	return currentLevel()
+}
+
+func currentLevel() LevelStatus {
+	if levels.override == "" {
+		return LevelStatus{Level: levels.configured, Configured: levels.configured}
+	}
+	return LevelStatus{Level: levels.override, Configured: levels.configured, OverrideUntil: levels.until}
+}
+
+// revertLevel ends the override and applies the configured level, levels.mu must be held
+func revertLevel() {
+	clearOverride()
+	if err := levels.controller.SetLevel(levels.configured); err != nil && Log != nil {
+		Log.With("level", levels.configured, "error", err).Error("reverting the log level failed")
+	}
+}
+
+func clearOverride() {
+	if levels.revert != nil {
+		levels.revert.Stop()
+		levels.revert = nil
+	}
+	levels.override = ""
+	levels.until = time.Time{}
+}
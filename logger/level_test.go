@@ -0,0 +1,94 @@
+package logger_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+// levels records the levels set by the logger package, it knows the levels of zap
+type levels struct {
+	mu    sync.Mutex
+	level string
+}
+
+func (l *levels) SetLevel(level string) error {
+	switch level {
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("unrecognized level: %q", level)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+	return nil
+}
+
+func (l *levels) current() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.level
+}
+
+func TestLevel(t *testing.T) {
+	controller := &levels{level: "info"}
+	logger.SetLevelController(controller, "info")
+	assert.Equal(t, logger.CurrentLevel(), logger.LevelStatus{Level: "info", Configured: "info"})
+
+	//the configured level applies right away without override
+	assert.Equal(t, logger.SetLevel("warn"), nil)
+	assert.Equal(t, controller.current(), "warn")
+	assert.NotEqual(t, logger.SetLevel("verbose"), nil)
+	assert.Equal(t, controller.current(), "warn")
+
+	//an override applies until it is reset, the configured level changes underneath it
+	status, err := logger.OverrideLevel("debug", time.Hour)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, status.Level, "debug")
+	assert.Equal(t, status.Configured, "warn")
+	assert.Equal(t, status.OverrideUntil.After(time.Now().Add(59*time.Minute)), true)
+	assert.Equal(t, logger.SetLevel("error"), nil)
+	assert.Equal(t, controller.current(), "debug")
+	status, err = logger.ResetLevel()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, status, logger.LevelStatus{Level: "error", Configured: "error"})
+	assert.Equal(t, controller.current(), "error")
+
+	//unknown levels are rejected and leave the level as is
+	_, err = logger.OverrideLevel("verbose", time.Hour)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, logger.CurrentLevel().Level, "error")
+}
+
+func TestLevelExpires(t *testing.T) {
+	controller := &levels{level: "info"}
+	logger.SetLevelController(controller, "info")
+
+	//a replaced override does not revert its successor
+	_, err := logger.OverrideLevel("warn", 20*time.Millisecond)
+	assert.Equal(t, err, nil)
+	_, err = logger.OverrideLevel("debug", 200*time.Millisecond)
+	assert.Equal(t, err, nil)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, controller.current(), "debug")
+
+	//the configured level is back once the override expires
+	deadline := time.Now().Add(5 * time.Second)
+	for controller.current() != "info" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, controller.current(), "info")
+	assert.Equal(t, logger.CurrentLevel(), logger.LevelStatus{Level: "info", Configured: "info"})
+}
+
+func TestLevelUnsupported(t *testing.T) {
+	logger.SetLevelController(nil, "")
+	assert.Equal(t, errors.Is(logger.SetLevel("info"), logger.ErrLevelUnsupported), true)
+	_, err := logger.OverrideLevel("info", time.Minute)
+	assert.Equal(t, errors.Is(err, logger.ErrLevelUnsupported), true)
+}
@@ -8,13 +8,17 @@ var Log Logger
 // Logger represent common interface for logging function
 type Logger interface {
 	Errorf(format string, args ...interface{})
+	Error(args ...interface{})
 	Fatalf(format string, args ...interface{})
 	Fatal(args ...interface{})
 	Infof(format string, args ...interface{})
 	Info(args ...interface{})
 	Warnf(format string, args ...interface{})
+	Warn(args ...interface{})
 	Debugf(format string, args ...interface{})
 	Debug(args ...interface{})
+	// With returns a logger adding the fields, alternating string keys and values, to every line
+	With(keysAndValues ...interface{}) Logger
 }
 
 // SetLogger is the setter for log variable, it should be the only way to assign value to log
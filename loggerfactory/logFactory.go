@@ -7,8 +7,9 @@ import (
 
 // logger mapp to map logger code to logger builder
 var logfactoryBuilderMap = map[string]logFbInterface{
-	config.ZAP:    &ZapFactory{},
-	config.LOGRUS: &LogrusFactory{},
+	config.ZAP:     &ZapFactory{},
+	config.LOGRUS:  &LogrusFactory{},
+	config.ZEROLOG: &ZerologFactory{},
 }
 
 // interface for logger factory
@@ -2,68 +2,141 @@
 package logrus
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/rahul-024/fund-transfer-poc/config"
-	"github.com/rahul-024/fund-transfer-poc/logger"
+	appLogger "github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/loggerfactory/output"
 	"github.com/sirupsen/logrus"
 )
 
-//type loggerWrapper struct {
-//	lw *logrus.Logger
-//}
-//
-//func (logger *loggerWrapper) Errorf(format string, args ...interface{}) {
-//	logger.lw.Errorf(format, args)
-//}
-//func (logger *loggerWrapper) Fatalf(format string, args ...interface{}) {
-//	logger.lw.Fatalf(format, args)
-//}
-//func (logger *loggerWrapper) Fatal(args ...interface{}) {
-//	logger.lw.Fatal(args)
-//}
-//func (logger *loggerWrapper) Infof(format string, args ...interface{}) {
-//	logger.lw.Infof(format, args)
-//}
-//func (logger *loggerWrapper) Warnf(format string, args ...interface{}) {
-//	logger.lw.Warnf(format, args)
-//}
-//func (logger *loggerWrapper) Debugf(format string, args ...interface{}) {
-//	logger.lw.Debugf(format, args)
-//}
-//func (logger *loggerWrapper) Printf(format string, args ...interface{}) {
-//	logger.lw.Printf(format, args)
-//}
-//func (logger *loggerWrapper) Println(args ...interface{}) {
-//	logger.lw.Println(args)
-//}
+// loggerWrapper adapts a logrus entry to logger.Logger, the fields added by With live in the entry.
+// logrus has no sampler, the wrapper drops the lines the sampler does not allow.
+type loggerWrapper struct {
+	lw      *logrus.Entry
+	sampler *output.Sampler
+}
+
+// sampled tells whether a line of the level and message is written, the lines below the level are not counted
+func (logger *loggerWrapper) sampled(level logrus.Level, msg string) bool {
+	return logger.lw.Logger.IsLevelEnabled(level) && logger.sampler.Allow(level.String(), msg, time.Now())
+}
+
+func (logger *loggerWrapper) Errorf(format string, args ...interface{}) {
+	if logger.sampled(logrus.ErrorLevel, format) {
+		logger.lw.Errorf(format, args...)
+	}
+}
+func (logger *loggerWrapper) Error(args ...interface{}) {
+	if logger.sampled(logrus.ErrorLevel, fmt.Sprint(args...)) {
+		logger.lw.Error(args...)
+	}
+}
+func (logger *loggerWrapper) Fatalf(format string, args ...interface{}) {
+	logger.lw.Fatalf(format, args...)
+}
+func (logger *loggerWrapper) Fatal(args ...interface{}) {
+	logger.lw.Fatal(args...)
+}
+func (logger *loggerWrapper) Infof(format string, args ...interface{}) {
+	if logger.sampled(logrus.InfoLevel, format) {
+		logger.lw.Infof(format, args...)
+	}
+}
+func (logger *loggerWrapper) Info(args ...interface{}) {
+	if logger.sampled(logrus.InfoLevel, fmt.Sprint(args...)) {
+		logger.lw.Info(args...)
+	}
+}
+func (logger *loggerWrapper) Warnf(format string, args ...interface{}) {
+	if logger.sampled(logrus.WarnLevel, format) {
+		logger.lw.Warnf(format, args...)
+	}
+}
+func (logger *loggerWrapper) Warn(args ...interface{}) {
+	if logger.sampled(logrus.WarnLevel, fmt.Sprint(args...)) {
+		logger.lw.Warn(args...)
+	}
+}
+func (logger *loggerWrapper) Debugf(format string, args ...interface{}) {
+	if logger.sampled(logrus.DebugLevel, format) {
+		logger.lw.Debugf(format, args...)
+	}
+}
+func (logger *loggerWrapper) Debug(args ...interface{}) {
+	if logger.sampled(logrus.DebugLevel, fmt.Sprint(args...)) {
+		logger.lw.Debug(args...)
+	}
+}
+func (logger *loggerWrapper) With(keysAndValues ...interface{}) appLogger.Logger {
+	return &loggerWrapper{logger.lw.WithFields(fields(keysAndValues)), logger.sampler}
+}
+
+// fields pairs the keys and values like the sugared zap logger does, a key without value is kept with a nil one
+func fields(keysAndValues []interface{}) logrus.Fields {
+	f := make(logrus.Fields, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key := fmt.Sprint(keysAndValues[i])
+		if i+1 < len(keysAndValues) {
+			f[key] = keysAndValues[i+1]
+		} else {
+			f[key] = nil
+		}
+	}
+	return f
+}
 
 func RegisterLog(lc config.LogConfig) error {
 	//standard configuration
 	log := logrus.New()
 	log.SetFormatter(&logrus.TextFormatter{})
 	log.SetReportCaller(true)
-	//log.SetOutput(os.Stdout)
 	//customize it from configuration file
 	err := customizeLogFromConfig(log, lc)
 	if err != nil {
 		return errors.Wrap(err, "")
 	}
-	//This is for loggerWrapper implementation
-	//logger.Logger(&loggerWrapper{log})
+	outputs, err := output.Open(lc)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	log.SetOutput(outputs)
+	appLogger.SetLogger(&loggerWrapper{logrus.NewEntry(log), output.NewSampler(lc.Sampling)})
+	appLogger.SetLevelController(levelController{log}, lc.Level)
+	return output.Use(outputs)
+}
+
+// levelController changes the level of the logger the entries of the wrappers write to
+type levelController struct {
+	log *logrus.Logger
+}
 
-	logger.SetLogger(log)
+func (c levelController) SetLevel(level string) error {
+	l, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	c.log.SetLevel(l)
 	return nil
 }
 
 // customizeLogFromConfig customize log based on parameters from configuration file
 func customizeLogFromConfig(log *logrus.Logger, lc config.LogConfig) error {
 	log.SetReportCaller(lc.EnableCaller)
-	//log.SetOutput(os.Stdout)
 	l := &log.Level
 	err := l.UnmarshalText([]byte(lc.Level))
 	if err != nil {
 		return errors.Wrap(err, "")
 	}
 	log.SetLevel(*l)
+	encoding, err := output.Encoding(lc)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	if encoding == config.JSON {
+		log.SetFormatter(&logrus.JSONFormatter{})
+	}
 	return nil
 }
@@ -0,0 +1,214 @@
+// package output handles the outputs and the sampling shared by the loggers
+package output
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rahul-024/fund-transfer-poc/config"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Constants for the outputs that are not files, they need to match outputs in logConfig
+const (
+	STDOUT string = "stdout"
+	STDERR string = "stderr"
+)
+
+// Encoding returns the encoding of lc, console when unset
+func Encoding(lc config.LogConfig) (string, error) {
+	switch lc.Encoding {
+	case config.CONSOLE, "":
+		return config.CONSOLE, nil
+	case config.JSON:
+		return config.JSON, nil
+	default:
+		return "", fmt.Errorf("unknown log encoding %q", lc.Encoding)
+	}
+}
+
+// Outputs writes to all the outputs of a logger
+type Outputs struct {
+	io.Writer
+	closers []io.Closer
+}
+
+// Close stops the rotation of the files and closes them, stdout and stderr stay open
+func (o *Outputs) Close() error {
+	var err error
+	for _, c := range o.closers {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// registered holds the outputs of the registered logger
+var registered struct {
+	sync.Mutex
+	outputs *Outputs
+}
+
+// Use records o as the outputs of the registered logger and closes the outputs of the logger it replaces
+func Use(o *Outputs) error {
+	registered.Lock()
+	previous := registered.outputs
+	registered.outputs = o
+	registered.Unlock()
+	if previous == nil || previous == o {
+		return nil
+	}
+	return previous.Close()
+}
+
+// Open returns a writer to all the outputs of lc, stdout when there is none. Files are created with their
+// directories and rotated as configured. The loggers pass the outputs to Use once registered.
+func Open(lc config.LogConfig) (*Outputs, error) {
+	outputs := lc.Outputs
+	if len(outputs) == 0 {
+		outputs = []string{STDOUT}
+	}
+	o := &Outputs{}
+	writers := make([]io.Writer, 0, len(outputs))
+	for _, output := range outputs {
+		w, c, err := open(output, lc.Rotation)
+		if err != nil {
+			o.Close()
+			return nil, errors.Wrap(err, output)
+		}
+		writers = append(writers, w)
+		if c != nil {
+			o.closers = append(o.closers, c)
+		}
+	}
+	o.Writer = writers[0]
+	if len(writers) > 1 {
+		o.Writer = io.MultiWriter(writers...)
+	}
+	return o, nil
+}
+
+// open returns the writer of an output and its closer, nil for stdout and stderr
+func open(output string, rc config.LogRotationConfig) (io.Writer, io.Closer, error) {
+	switch output {
+	case STDOUT:
+		return os.Stdout, nil, nil
+	case STDERR:
+		return os.Stderr, nil, nil
+	}
+	if rc.MaxSizeMB <= 0 && rc.Interval <= 0 {
+		if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+			return nil, nil, err
+		}
+		file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		return file, file, nil
+	}
+	file := &lumberjack.Logger{
+		Filename:   output,
+		MaxSize:    rc.MaxSizeMB,
+		MaxBackups: rc.MaxBackups,
+		MaxAge:     rc.MaxAgeDays,
+		Compress:   rc.Compress,
+		LocalTime:  true,
+	}
+	if rc.MaxSizeMB <= 0 {
+		// lumberjack rotates at 100 MB when no size is set, the files rotated by time have no limit
+		file.MaxSize = math.MaxInt32
+	}
+	if rc.Interval > 0 {
+		return file, rotateEvery(file, rc.Interval), nil
+	}
+	return file, file, nil
+}
+
+// rotation rotates a file at each interval until it is closed
+type rotation struct {
+	file *lumberjack.Logger
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func rotateEvery(file *lumberjack.Logger, interval time.Duration) *rotation {
+	r := &rotation{file: file, stop: make(chan struct{}), done: make(chan struct{})}
+	go r.run(interval)
+	return r
+}
+
+func (r *rotation) run(interval time.Duration) {
+	defer close(r.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			if err := r.file.Rotate(); err != nil {
+				// the logger cannot log its own failures
+				fmt.Fprintf(os.Stderr, "rotating log file %s failed: %v\n", r.file.Filename, err)
+			}
+		}
+	}
+}
+
+// Close stops the rotation and closes the file
+func (r *rotation) Close() error {
+	r.once.Do(func() { close(r.stop) })
+	<-r.done
+	return r.file.Close()
+}
+
+// Sampler caps the lines repeated in a burst for the loggers without a sampler of their own, a nil Sampler
+// allows every line
+type Sampler struct {
+	initial    int
+	thereafter int
+	tick       time.Duration
+
+	mu          sync.Mutex
+	tickStarted time.Time
+	counts      map[string]int
+}
+
+// NewSampler returns the sampler configured by sc, nil when sampling is disabled
+func NewSampler(sc config.LogSamplingConfig) *Sampler {
+	if sc.Initial <= 0 {
+		return nil
+	}
+	tick := sc.Tick
+	if tick <= 0 {
+		tick = time.Second
+	}
+	return &Sampler{initial: sc.Initial, thereafter: sc.Thereafter, tick: tick, counts: map[string]int{}}
+}
+
+// Allow counts a line of the level and message at now and tells whether it is written
+func (s *Sampler) Allow(level string, msg string, now time.Time) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.tickStarted) >= s.tick {
+		s.tickStarted = now
+		s.counts = map[string]int{}
+	}
+	key := level + "|" + msg
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= s.initial {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.initial)%s.thereafter == 0
+}
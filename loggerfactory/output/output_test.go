@@ -0,0 +1,102 @@
+package output_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/config"
+	"github.com/rahul-024/fund-transfer-poc/loggerfactory/output"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestSampler(t *testing.T) {
+	sampler := output.NewSampler(config.LogSamplingConfig{Initial: 2, Thereafter: 3, Tick: time.Second})
+	now := time.Now()
+
+	//the first lines of a tick are written, then every third
+	var allowed []bool
+	for i := 0; i < 8; i++ {
+		allowed = append(allowed, sampler.Allow("info", "polled", now))
+	}
+	assert.Equal(t, allowed, []bool{true, true, false, false, true, false, false, true})
+
+	//the other messages and levels are counted on their own
+	assert.Equal(t, sampler.Allow("info", "started", now), true)
+	assert.Equal(t, sampler.Allow("warn", "polled", now), true)
+
+	//the counts start over each tick
+	assert.Equal(t, sampler.Allow("info", "polled", now.Add(time.Second)), true)
+
+	//sampling is disabled without initial lines
+	disabled := output.NewSampler(config.LogSamplingConfig{})
+	assert.Equal(t, disabled == nil, true)
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, disabled.Allow("info", "polled", now), true)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain", "app.log")
+	rotated := filepath.Join(dir, "rotated", "app.log")
+
+	//files are created with their directories and appended to
+	w, err := output.Open(config.LogConfig{Outputs: []string{plain}})
+	assert.Equal(t, err, nil)
+	fmt.Fprintln(w, "first")
+	w, _ = output.Open(config.LogConfig{Outputs: []string{plain}})
+	fmt.Fprintln(w, "second")
+	content, _ := os.ReadFile(plain)
+	assert.Equal(t, string(content), "first\nsecond\n")
+
+	//every output gets the lines
+	w, err = output.Open(config.LogConfig{
+		Outputs:  []string{plain, rotated},
+		Rotation: config.LogRotationConfig{MaxSizeMB: 1, MaxBackups: 2},
+	})
+	assert.Equal(t, err, nil)
+	fmt.Fprintln(w, "third")
+	content, _ = os.ReadFile(plain)
+	assert.Equal(t, strings.HasSuffix(string(content), "third\n"), true)
+	content, _ = os.ReadFile(rotated)
+	assert.Equal(t, string(content), "third\n")
+}
+
+func TestEncoding(t *testing.T) {
+	encoding, err := output.Encoding(config.LogConfig{})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, encoding, config.CONSOLE)
+	encoding, _ = output.Encoding(config.LogConfig{Encoding: "json"})
+	assert.Equal(t, encoding, config.JSON)
+	_, err = output.Encoding(config.LogConfig{Encoding: "xml"})
+	assert.NotEqual(t, err, nil)
+}
+
+func TestUseClosesTheReplacedOutputs(t *testing.T) {
+	dir := t.TempDir()
+	goroutines := runtime.NumGoroutine()
+
+	//the files rotated by time are rotated in the background
+	rotated, err := output.Open(config.LogConfig{
+		Outputs:  []string{filepath.Join(dir, "rotated.log")},
+		Rotation: config.LogRotationConfig{Interval: time.Hour},
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, output.Use(rotated), nil)
+	assert.Equal(t, runtime.NumGoroutine(), goroutines+1)
+
+	//replacing the outputs stops the rotation and closes the file
+	plain, err := output.Open(config.LogConfig{Outputs: []string{filepath.Join(dir, "plain.log")}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, output.Use(plain), nil)
+	assert.Equal(t, runtime.NumGoroutine(), goroutines)
+
+	assert.Equal(t, output.Use(nil), nil)
+	_, err = fmt.Fprintln(plain, "closed")
+	assert.NotEqual(t, err, nil)
+}
@@ -3,61 +3,86 @@ package zap
 
 import (
 	"encoding/json"
+	"io"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rahul-024/fund-transfer-poc/config"
-	"github.com/rahul-024/fund-transfer-poc/logger"
+	appLogger "github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/loggerfactory/output"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
-//type loggerWrapper struct {
-//	lw *zap.SugaredLogger
-//}
-//
-//
-//func (logger *loggerWrapper) Errorf(format string, args ...interface{}) {
-//	logger.lw.Errorf(format, args)
-//}
-//func (logger *loggerWrapper) Fatalf(format string, args ...interface{}) {
-//	logger.lw.Fatalf(format, args)
-//}
-//func (logger *loggerWrapper) Fatal(args ...interface{}) {
-//	logger.lw.Fatal(args)
-//}
-//func (logger *loggerWrapper) Infof(format string, args ...interface{}) {
-//	logger.lw.Infof(format, args)
-//}
-//func (logger *loggerWrapper) Warnf(format string, args ...interface{}) {
-//	logger.lw.Warnf(format, args)
-//}
-//func (logger *loggerWrapper) Debugf(format string, args ...interface{}) {
-//	logger.lw.Debugf(format, args)
-//}
-//func (logger *loggerWrapper) Printf(format string, args ...interface{}) {
-//	logger.lw.Infof(format, args)
-//}
-//func (logger *loggerWrapper) Println(args ...interface{}) {
-//	logger.lw.Info(args, "\n")
-//}
+// loggerWrapper adapts the sugared logger to logger.Logger, whose With returns the interface
+type loggerWrapper struct {
+	lw *zap.SugaredLogger
+}
+
+func (logger *loggerWrapper) Errorf(format string, args ...interface{}) {
+	logger.lw.Errorf(format, args...)
+}
+func (logger *loggerWrapper) Error(args ...interface{}) {
+	logger.lw.Error(args...)
+}
+func (logger *loggerWrapper) Fatalf(format string, args ...interface{}) {
+	logger.lw.Fatalf(format, args...)
+}
+func (logger *loggerWrapper) Fatal(args ...interface{}) {
+	logger.lw.Fatal(args...)
+}
+func (logger *loggerWrapper) Infof(format string, args ...interface{}) {
+	logger.lw.Infof(format, args...)
+}
+func (logger *loggerWrapper) Info(args ...interface{}) {
+	logger.lw.Info(args...)
+}
+func (logger *loggerWrapper) Warnf(format string, args ...interface{}) {
+	logger.lw.Warnf(format, args...)
+}
+func (logger *loggerWrapper) Warn(args ...interface{}) {
+	logger.lw.Warn(args...)
+}
+func (logger *loggerWrapper) Debugf(format string, args ...interface{}) {
+	logger.lw.Debugf(format, args...)
+}
+func (logger *loggerWrapper) Debug(args ...interface{}) {
+	logger.lw.Debug(args...)
+}
+func (logger *loggerWrapper) With(keysAndValues ...interface{}) appLogger.Logger {
+	return &loggerWrapper{logger.lw.With(keysAndValues...)}
+}
 
 func RegisterLog(lc config.LogConfig) error {
-	zLogger, err := initLog(lc)
+	outputs, err := output.Open(lc)
+	if err != nil {
+		return errors.Wrap(err, "RegisterLog")
+	}
+	zLogger, level, err := initLog(lc, outputs)
 	if err != nil {
+		outputs.Close()
 		return errors.Wrap(err, "RegisterLog")
 	}
 	defer zLogger.Sync()
-	zSugarlog := zLogger.Sugar()
-	zSugarlog.Info()
+	// the callers are reported past the wrapper
+	zSugarlog := zLogger.WithOptions(zap.AddCallerSkip(1)).Sugar()
 
-	//This is for loggerWrapper implementation
-	//appLogger.SetLogger(&loggerWrapper{zaplog})
+	appLogger.SetLogger(&loggerWrapper{zSugarlog})
+	appLogger.SetLevelController(levelController{level}, lc.Level)
+	return output.Use(outputs)
+}
 
-	logger.SetLogger(zSugarlog)
-	return nil
+// levelController changes the atomic level shared by the loggers derived from the registered one
+type levelController struct {
+	level zap.AtomicLevel
+}
+
+func (c levelController) SetLevel(level string) error {
+	return c.level.UnmarshalText([]byte(level))
 }
 
-// initLog create logger
-func initLog(lc config.LogConfig) (zap.Logger, error) {
+// initLog create logger writing to outputs
+func initLog(lc config.LogConfig, outputs io.Writer) (zap.Logger, zap.AtomicLevel, error) {
 	rawJSON := []byte(`{
 	 "level": "info",
      "Development": true,
@@ -84,20 +109,25 @@ func initLog(lc config.LogConfig) (zap.Logger, error) {
 	var zLogger *zap.Logger
 	//standard configuration
 	if err := json.Unmarshal(rawJSON, &cfg); err != nil {
-		return *zLogger, errors.Wrap(err, "Unmarshal")
+		return *zLogger, cfg.Level, errors.Wrap(err, "Unmarshal")
 	}
 	//customize it from configuration file
 	err := customizeLogFromConfig(&cfg, lc)
 	if err != nil {
-		return *zLogger, errors.Wrap(err, "cfg.Build()")
+		return *zLogger, cfg.Level, errors.Wrap(err, "cfg.Build()")
+	}
+	core, err := newCore(cfg, lc, outputs)
+	if err != nil {
+		return *zLogger, cfg.Level, errors.Wrap(err, "newCore")
 	}
-	zLogger, err = cfg.Build()
+	// the options of the configuration, like the caller, apply to the core writing to the configured outputs
+	zLogger, err = cfg.Build(zap.WrapCore(func(zapcore.Core) zapcore.Core { return core }))
 	if err != nil {
-		return *zLogger, errors.Wrap(err, "cfg.Build()")
+		return *zLogger, cfg.Level, errors.Wrap(err, "cfg.Build()")
 	}
 
 	zLogger.Debug("logger construction succeeded")
-	return *zLogger, nil
+	return *zLogger, cfg.Level, nil
 }
 
 // customizeLogFromConfig customize log based on parameters from configuration file
@@ -112,5 +142,33 @@ func customizeLogFromConfig(cfg *zap.Config, lc config.LogConfig) error {
 	}
 	cfg.Level.SetLevel(l)
 
+	cfg.Encoding, err = output.Encoding(lc)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	if cfg.Encoding == config.JSON {
+		// one object per line with readable timestamps, for the log shippers. The development mode would add
+		// stack traces to warnings and panic on DPanic lines.
+		cfg.EncoderConfig.LineEnding = zapcore.DefaultLineEnding
+		cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		cfg.Development = false
+	}
 	return nil
 }
+
+// newCore writes the lines of the configured encoding to the outputs, sampled when enabled
+func newCore(cfg zap.Config, lc config.LogConfig, outputs io.Writer) (zapcore.Core, error) {
+	encoder := zapcore.NewConsoleEncoder(cfg.EncoderConfig)
+	if cfg.Encoding == config.JSON {
+		encoder = zapcore.NewJSONEncoder(cfg.EncoderConfig)
+	}
+	core := zapcore.NewCore(encoder, zapcore.AddSync(outputs), cfg.Level)
+	if sc := lc.Sampling; sc.Initial > 0 {
+		tick := sc.Tick
+		if tick <= 0 {
+			tick = time.Second
+		}
+		core = zapcore.NewSamplerWithOptions(core, tick, sc.Initial, sc.Thereafter)
+	}
+	return core, nil
+}
@@ -0,0 +1,122 @@
+// package zerolog handles creating zerolog logger
+package zerolog
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rahul-024/fund-transfer-poc/config"
+	appLogger "github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/loggerfactory/output"
+	"github.com/rs/zerolog"
+)
+
+// loggerWrapper adapts a zerolog logger to logger.Logger, the fields added by With live in its context
+type loggerWrapper struct {
+	lw zerolog.Logger
+}
+
+func (logger *loggerWrapper) Errorf(format string, args ...interface{}) {
+	logger.lw.Error().Msgf(format, args...)
+}
+func (logger *loggerWrapper) Error(args ...interface{}) {
+	logger.lw.Error().Msg(fmt.Sprint(args...))
+}
+func (logger *loggerWrapper) Fatalf(format string, args ...interface{}) {
+	logger.lw.Fatal().Msgf(format, args...)
+}
+func (logger *loggerWrapper) Fatal(args ...interface{}) {
+	logger.lw.Fatal().Msg(fmt.Sprint(args...))
+}
+func (logger *loggerWrapper) Infof(format string, args ...interface{}) {
+	logger.lw.Info().Msgf(format, args...)
+}
+func (logger *loggerWrapper) Info(args ...interface{}) {
+	logger.lw.Info().Msg(fmt.Sprint(args...))
+}
+func (logger *loggerWrapper) Warnf(format string, args ...interface{}) {
+	logger.lw.Warn().Msgf(format, args...)
+}
+func (logger *loggerWrapper) Warn(args ...interface{}) {
+	logger.lw.Warn().Msg(fmt.Sprint(args...))
+}
+func (logger *loggerWrapper) Debugf(format string, args ...interface{}) {
+	logger.lw.Debug().Msgf(format, args...)
+}
+func (logger *loggerWrapper) Debug(args ...interface{}) {
+	logger.lw.Debug().Msg(fmt.Sprint(args...))
+}
+func (logger *loggerWrapper) With(keysAndValues ...interface{}) appLogger.Logger {
+	// zerolog drops a key without value, it is kept with a nil one like the other backends do
+	if len(keysAndValues)%2 == 1 {
+		keysAndValues = append(keysAndValues[:len(keysAndValues):len(keysAndValues)], nil)
+	}
+	return &loggerWrapper{logger.lw.With().Fields(keysAndValues).Logger()}
+}
+
+func RegisterLog(lc config.LogConfig) error {
+	//customize it from configuration file
+	outputs, err := output.Open(lc)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	log, err := newLogger(lc, outputs)
+	if err != nil {
+		outputs.Close()
+		return errors.Wrap(err, "")
+	}
+	appLogger.SetLogger(&loggerWrapper{log})
+	appLogger.SetLevelController(levelController{}, lc.Level)
+	return output.Use(outputs)
+}
+
+// levelController changes the global level of zerolog, the loggers are values copied by With and cannot
+// share a level of their own
+type levelController struct{}
+
+func (levelController) SetLevel(level string) error {
+	l, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	zerolog.SetGlobalLevel(l)
+	return nil
+}
+
+// newLogger writes the lines of the configured encoding to the outputs, sampled when enabled
+func newLogger(lc config.LogConfig, outputs io.Writer) (zerolog.Logger, error) {
+	encoding, err := output.Encoding(lc)
+	if err != nil {
+		return zerolog.Logger{}, errors.Wrap(err, "")
+	}
+	w := outputs
+	if encoding == config.CONSOLE {
+		w = zerolog.ConsoleWriter{Out: w, NoColor: true}
+	}
+	log := zerolog.New(w).With().Timestamp().Logger()
+	if lc.EnableCaller {
+		// the callers are reported past the wrapper
+		log = log.With().CallerWithSkipFrameCount(zerolog.CallerSkipFrameCount + 1).Logger()
+	}
+	if sampler := output.NewSampler(lc.Sampling); sampler != nil {
+		log = log.Hook(samplingHook{sampler})
+	}
+	// the level is the global one, so that it can change at runtime
+	if err = (levelController{}).SetLevel(lc.Level); err != nil {
+		return log, errors.Wrap(err, "")
+	}
+	return log, nil
+}
+
+// samplingHook discards the lines the sampler does not allow, fatal ones are always written
+type samplingHook struct {
+	sampler *output.Sampler
+}
+
+func (h samplingHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level < zerolog.FatalLevel && !h.sampler.Allow(level.String(), msg, time.Now()) {
+		e.Discard()
+	}
+}
@@ -0,0 +1,19 @@
+package loggerfactory
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rahul-024/fund-transfer-poc/config"
+	"github.com/rahul-024/fund-transfer-poc/loggerfactory/zerolog"
+)
+
+// receiver for zerolog factory
+type ZerologFactory struct{}
+
+// build zerolog logger
+func (mf *ZerologFactory) Build(lc *config.LogConfig) error {
+	err := zerolog.RegisterLog(*lc)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+	return nil
+}
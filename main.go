@@ -1,69 +1,10 @@
 package main
 
-import (
-	"fmt"
-	"os"
-
-	"github.com/fsnotify/fsnotify"
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-	"github.com/pkg/errors"
-	"github.com/rahul-024/fund-transfer-poc/config"
-	logFactory "github.com/rahul-024/fund-transfer-poc/loggerfactory"
-	"github.com/rs/zerolog/log"
-	"github.com/spf13/viper"
-	"gorm.io/gorm"
-)
-
-func init() {
-	profile := initProfile()
-	LoadConfig(profile)
-}
-
-func initProfile() string {
-	var profile string
-	profile = os.Getenv("ENV_PROFILE")
-	if len(profile) <= 0 {
-		profile = "local"
-	}
-	fmt.Println("ENV_PROFILE: " + profile)
-	return profile
-}
-
-func LoadConfig(profile string) {
-	viper.AddConfigPath("./profiles")
-	viper.SetConfigName(profile)
-	viper.SetConfigType("yaml")
-	err := viper.ReadInConfig()
-	if err != nil {
-		panic(err)
-	}
-	err = viper.Unmarshal(&config.AppConf)
-	if err != nil {
-		panic(err)
-	}
-
-	viper.OnConfigChange(func(e fsnotify.Event) {
-		fmt.Println("Config file changed:", e.Name)
-		var err error
-		err = viper.ReadInConfig()
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		err = viper.Unmarshal(&config.AppConf)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-	})
-	viper.WatchConfig()
-}
+import "github.com/rahul-024/fund-transfer-poc/cmd"
 
 //	@title			Fund transfer service
 //	@version		1.0
-//	@description	A rest based service in Go using Gin framework.
+//	@description	A rest based service in Go using Gin framework. Errors are answered with RFC 7807 application/problem+json bodies carrying a stable code.
 //	@termsOfService	https://tos.iexceed.dev
 
 //	@contact.name	Iexceed technology solutions
@@ -76,44 +17,16 @@ func LoadConfig(profile string) {
 //	@host		localhost:8081
 //	@BasePath	/api/v1
 
-func main() {
-	db := config.ConnectDatabase(&config.AppConf)
-	runDBMigration(&config.AppConf)
-	loadLogger(config.AppConf.Log)
-	runGinServer(&config.AppConf, db)
-}
-
-func runDBMigration(appConfig *config.AppConfig) {
-	migration, err := migrate.New(appConfig.DbMigrationPath, appConfig.Datasource.Dsn)
-	if err != nil {
-		log.Fatal().Err(err).Msg("cannot create new migrate instance")
-	}
-
-	if err = migration.Up(); err != nil && err != migrate.ErrNoChange {
-		log.Fatal().Err(err).Msg("failed to run migrate up")
-	}
-
-	log.Info().Msg("db migrated successfully")
-}
-
-func runGinServer(appConfig *config.AppConfig, db *gorm.DB) {
-	server, err := config.NewServer(db)
-	if err != nil {
-		log.Fatal().Err(err).Msg("cannot create server")
-	}
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				JWT signed with HS256 or RS256, sent as "Bearer <token>". The roles claim (customer, teller, admin) decides what the caller may do.
 
-	err = server.Start(appConfig.ServerConfig.HttpServerAddress)
-	if err != nil {
-		log.Fatal().Err(err).Msg("cannot start server")
-	}
-}
+//	@securityDefinitions.apikey	APIKeyAuth
+//	@in							header
+//	@name						X-API-Key
+//	@description				API key of a machine client, granted scopes such as accounts:read or transfers:write
 
-// loads the logger
-func loadLogger(lc config.LogConfig) error {
-	loggerType := lc.Code
-	err := logFactory.GetLogFactoryBuilder(loggerType).Build(&lc)
-	if err != nil {
-		return errors.Wrap(err, "")
-	}
-	return nil
+func main() {
+	cmd.Execute()
 }
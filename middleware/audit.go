@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-024/fund-transfer-poc/audit"
+)
+
+// ActorKey is the gin context key holding the authenticated caller recorded in audit events
+const ActorKey = "actor"
+
+// AuditContextMiddleware : stores who is calling and from where in the request context so that
+// the audit events written by the repositories can pick it up. It runs after RequestIDMiddleware.
+func AuditContextMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		meta := audit.Meta{
+			Actor:     c.GetString(ActorKey),
+			RequestID: c.GetString(RequestIDKey),
+			SourceIP:  c.ClientIP(),
+		}
+		c.Request = c.Request.WithContext(audit.NewContext(c.Request.Context(), meta))
+		c.Next()
+	}
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-024/fund-transfer-poc/audit"
+	"github.com/rahul-024/fund-transfer-poc/auth"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/service"
+)
+
+const (
+	// ClaimsKey is the gin context key holding the claims of the bearer token or API key
+	ClaimsKey = "claims"
+	// APIKeyHeader is the header machine clients send their API key in
+	APIKeyHeader = "X-API-Key"
+)
+
+// JWTAuthMiddleware : rejects requests without a valid bearer token with 401. The subject becomes the actor of
+// the audit events and the principal is stored in the request context for the service layer.
+func JWTAuthMiddleware(verifier *auth.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := auth.BearerToken(c.GetHeader("Authorization"))
+		if !ok {
+			unauthorized(c, "missing bearer token")
+			return
+		}
+		principal, err := verifier.Verify(token)
+		if err != nil {
+			logger.FromContext(c.Request.Context()).With("error", err).Debug("rejected bearer token")
+			unauthorized(c, err.Error())
+			return
+		}
+		authenticated(c, principal)
+	}
+}
+
+// APIKeyAuthMiddleware : authenticates requests carrying an X-API-Key header with the key and hands the
+// others over to next, the bearer token authentication
+func APIKeyAuthMiddleware(keys service.APIKeyService, next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			next(c)
+			return
+		}
+		principal, err := keys.Authenticate(c.Request.Context(), key)
+		if errors.Is(err, service.ErrInvalidAPIKey) {
+			logger.FromContext(c.Request.Context()).With("error", err).Debug("rejected API key")
+			unauthorized(c, "invalid API key")
+			return
+		}
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		authenticated(c, principal)
+	}
+}
+
+// AnonymousMiddleware : runs every request as principal, for servers with authentication disabled. Only the
+// service layer sees the principal, the audit events and the rate limits still treat the requests as anonymous.
+func AnonymousMiddleware(principal auth.Principal) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// authenticated records the principal for the handlers, the service layer and the audit events
+func authenticated(c *gin.Context, principal auth.Principal) {
+	c.Set(ActorKey, principal.Subject)
+	c.Set(ClaimsKey, principal.Claims)
+	ctx := auth.NewContext(c.Request.Context(), principal)
+	// the audit metadata is stored before the caller is known
+	meta := audit.FromContext(ctx)
+	meta.Actor = principal.Subject
+	c.Request = c.Request.WithContext(audit.NewContext(ctx, meta))
+	c.Next()
+}
+
+func unauthorized(c *gin.Context, detail string) {
+	c.Header("WWW-Authenticate", `Bearer realm="fund-transfer"`)
+	AbortWithProblem(c, http.StatusUnauthorized, "UNAUTHENTICATED", detail)
+}
+
+// maxSignedBodySize bounds the body read to verify a signature
+const maxSignedBodySize = 1 << 20
+
+// SignedRequestMiddleware : authenticates requests carrying an X-Signature header as the partner that signed
+// them and hands the others over to next. Partners are granted the transfers:write scope on the accounts
+// they own, the accounts of owner partner:<id>. Requests with a stale timestamp, a wrong signature or a
+// reused nonce are rejected with 401.
+func SignedRequestMiddleware(verifier *auth.SignatureVerifier, next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		signature := c.GetHeader(auth.SignatureHeader)
+		if signature == "" {
+			next(c)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSignedBodySize))
+		if err != nil {
+			c.Error(fmt.Errorf("%w: reading body: %v", service.ErrInvalidRequest, err))
+			c.Abort()
+			return
+		}
+		// the handlers read the body again
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		partnerID, err := verifier.Verify(c.Request.Context(), auth.SignedRequest{
+			PartnerID: c.GetHeader(auth.PartnerHeader),
+			Method:    c.Request.Method,
+			URI:       c.Request.URL.RequestURI(),
+			Timestamp: c.GetHeader(auth.TimestampHeader),
+			Nonce:     c.GetHeader(auth.NonceHeader),
+			Signature: signature,
+			Body:      body,
+		})
+		if errors.Is(err, auth.ErrInvalidSignature) || errors.Is(err, auth.ErrReplayedRequest) {
+			logger.FromContext(c.Request.Context()).With("partner_id", c.GetHeader(auth.PartnerHeader), "error", err).
+				Debug("rejected signed request")
+			AbortWithProblem(c, http.StatusUnauthorized, "INVALID_SIGNATURE", err.Error())
+			return
+		}
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		subject := "partner:" + partnerID
+		authenticated(c, auth.Principal{
+			Subject: subject,
+			Claims: map[string]interface{}{
+				"sub":               subject,
+				service.ScopesClaim: []string{service.ScopeTransfersWrite},
+			},
+		})
+	}
+}
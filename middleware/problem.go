@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/service"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// Problem is the body of every error response, see RFC 7807. Code is a stable identifier clients can branch on,
+// detail is meant for humans and may change.
+type Problem struct {
+	Type     string `json:"type" example:"about:blank"`
+	Title    string `json:"title" example:"Not Found"`
+	Status   int    `json:"status" example:"404"`
+	Detail   string `json:"detail,omitempty" example:"account not found"`
+	Instance string `json:"instance,omitempty" example:"/api/v1/accounts/7"`
+	Code     string `json:"code" example:"ACCOUNT_NOT_FOUND"`
+} // @name Problem
+
+// kindStatus maps the kinds of domain errors to HTTP status codes
+var kindStatus = map[service.ErrorKind]int{
+	service.KindNotFound:          http.StatusNotFound,
+	service.KindValidationFailed:  http.StatusBadRequest,
+	service.KindInsufficientFunds: http.StatusUnprocessableEntity,
+	service.KindConflict:          http.StatusConflict,
+	service.KindLimitExceeded:     http.StatusUnprocessableEntity,
+	service.KindForbidden:         http.StatusForbidden,
+	service.KindUnauthenticated:   http.StatusUnauthorized,
+}
+
+// ErrorMiddleware : renders the last error a handler attached with c.Error as a problem+json response.
+// Domain errors keep their code and message, anything else is logged and answered with a generic 500
+// so that database errors do not leak to clients.
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+		var domainErr *service.Error
+		if !errors.As(err, &domainErr) {
+			logger.FromContext(c.Request.Context()).With("method", c.Request.Method, "path", c.Request.URL.Path, "error", err).
+				Error("request failed")
+			AbortWithProblem(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+			return
+		}
+		status, ok := kindStatus[domainErr.Kind]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		AbortWithProblem(c, status, domainErr.Code, err.Error())
+	}
+}
+
+// AbortWithProblem writes a problem+json response for the request and stops the handler chain
+func AbortWithProblem(c *gin.Context, status int, code string, detail string) {
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(status, Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		Code:     code,
+	})
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/ratelimit"
+)
+
+// RateLimitMiddleware : takes each request from the token bucket of its client for the route group and rejects
+// it with 429 once the bucket is empty. Clients are the authenticated caller, the API key, user or partner, and
+// the IP address for anonymous requests, so the middleware has to run after the authentication. The responses
+// carry the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers, and Retry-After when rejected.
+func RateLimitMiddleware(store ratelimit.Store, group string, limit ratelimit.Limit) gin.HandlerFunc {
+	return rateLimit(store, group, limit, func(c *gin.Context) string {
+		if actor := c.GetString(ActorKey); actor != "" {
+			return "actor:" + actor
+		}
+		return "ip:" + c.ClientIP()
+	})
+}
+
+// IPRateLimitMiddleware : takes each request from the token bucket of its IP address for the route group, like
+// RateLimitMiddleware. It runs before the authentication, so that requests with bad credentials or signatures
+// are turned away before they cost a token, API key or nonce check.
+func IPRateLimitMiddleware(store ratelimit.Store, group string, limit ratelimit.Limit) gin.HandlerFunc {
+	return rateLimit(store, group, limit, func(c *gin.Context) string {
+		return "unauthenticated-ip:" + c.ClientIP()
+	})
+}
+
+func rateLimit(store ratelimit.Store, group string, limit ratelimit.Limit, clientOf func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client := clientOf(c)
+		result, err := store.Take(c.Request.Context(), group+"|"+client, limit, time.Now())
+		if err != nil {
+			// the limits protect the service, they must not take it down with their store
+			logger.FromContext(c.Request.Context()).With("client", client, "group", group, "error", err).
+				Warn("rate limiting failed, letting the request through")
+			c.Next()
+			return
+		}
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", seconds(result.Reset))
+		if !result.Allowed {
+			c.Header("Retry-After", seconds(result.RetryAfter))
+			AbortWithProblem(c, http.StatusTooManyRequests, "RATE_LIMITED",
+				fmt.Sprintf("too many requests to %s, retry in %s", group, seconds(result.RetryAfter)+"s"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// seconds rounds a duration up to whole seconds, so that clients waiting that long find a request available
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+)
+
+const (
+	// RequestIDHeader carries the id correlating the log lines, audit events and outbox events of a request
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey is the gin context key holding the request id
+	RequestIDKey = "requestId"
+)
+
+// RequestIDMiddleware : accepts the X-Request-ID of the client or generates one, stores it in the request
+// context for the request-scoped logger and echoes it in the response
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !logger.ValidRequestID(requestID) {
+			requestID = logger.NewRequestID()
+		}
+		c.Set(RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logger.NewContext(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
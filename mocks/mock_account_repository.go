@@ -5,6 +5,7 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -36,142 +37,158 @@ func (m *MockAccountRepository) EXPECT() *MockAccountRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CountAll mocks base method.
+func (m *MockAccountRepository) CountAll(arg0 context.Context, arg1 repository.AccountQuery) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAll", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAll indicates an expected call of CountAll.
+func (mr *MockAccountRepositoryMockRecorder) CountAll(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAll", reflect.TypeOf((*MockAccountRepository)(nil).CountAll), arg0, arg1)
+}
+
 // DecrementBalance mocks base method.
-func (m *MockAccountRepository) DecrementBalance(arg0 int, arg1 float64) error {
+func (m *MockAccountRepository) DecrementBalance(arg0 context.Context, arg1 int, arg2 float64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DecrementBalance", arg0, arg1)
+	ret := m.ctrl.Call(m, "DecrementBalance", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DecrementBalance indicates an expected call of DecrementBalance.
-func (mr *MockAccountRepositoryMockRecorder) DecrementBalance(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockAccountRepositoryMockRecorder) DecrementBalance(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrementBalance", reflect.TypeOf((*MockAccountRepository)(nil).DecrementBalance), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrementBalance", reflect.TypeOf((*MockAccountRepository)(nil).DecrementBalance), arg0, arg1, arg2)
 }
 
 // DeleteAccountById mocks base method.
-func (m *MockAccountRepository) DeleteAccountById(id int) error {
+func (m *MockAccountRepository) DeleteAccountById(ctx context.Context, id int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteAccountById", id)
+	ret := m.ctrl.Call(m, "DeleteAccountById", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteAccountById indicates an expected call of DeleteAccountById.
-func (mr *MockAccountRepositoryMockRecorder) DeleteAccountById(id interface{}) *gomock.Call {
+func (mr *MockAccountRepositoryMockRecorder) DeleteAccountById(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountById", reflect.TypeOf((*MockAccountRepository)(nil).DeleteAccountById), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountById", reflect.TypeOf((*MockAccountRepository)(nil).DeleteAccountById), ctx, id)
 }
 
 // GetAccountById mocks base method.
-func (m *MockAccountRepository) GetAccountById(id int) (models.Account, error) {
+func (m *MockAccountRepository) GetAccountById(ctx context.Context, id int) (models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountById", id)
+	ret := m.ctrl.Call(m, "GetAccountById", ctx, id)
 	ret0, _ := ret[0].(models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAccountById indicates an expected call of GetAccountById.
-func (mr *MockAccountRepositoryMockRecorder) GetAccountById(id interface{}) *gomock.Call {
+func (mr *MockAccountRepositoryMockRecorder) GetAccountById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountById", reflect.TypeOf((*MockAccountRepository)(nil).GetAccountById), ctx, id)
+}
+
+// GetAccountsByIds mocks base method.
+func (m *MockAccountRepository) GetAccountsByIds(ctx context.Context, ids []int) ([]models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountsByIds", ctx, ids)
+	ret0, _ := ret[0].([]models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountsByIds indicates an expected call of GetAccountsByIds.
+func (mr *MockAccountRepositoryMockRecorder) GetAccountsByIds(ctx, ids interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountById", reflect.TypeOf((*MockAccountRepository)(nil).GetAccountById), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountsByIds", reflect.TypeOf((*MockAccountRepository)(nil).GetAccountsByIds), ctx, ids)
 }
 
 // GetAll mocks base method.
-func (m *MockAccountRepository) GetAll(pageId, pageSize int) ([]models.Account, error) {
+func (m *MockAccountRepository) GetAll(arg0 context.Context, arg1 repository.AccountQuery) ([]models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", pageId, pageSize)
+	ret := m.ctrl.Call(m, "GetAll", arg0, arg1)
 	ret0, _ := ret[0].([]models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockAccountRepositoryMockRecorder) GetAll(pageId, pageSize interface{}) *gomock.Call {
+func (mr *MockAccountRepositoryMockRecorder) GetAll(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockAccountRepository)(nil).GetAll), pageId, pageSize)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockAccountRepository)(nil).GetAll), arg0, arg1)
 }
 
 // IncrementBalance mocks base method.
-func (m *MockAccountRepository) IncrementBalance(arg0 int, arg1 float64) error {
+func (m *MockAccountRepository) IncrementBalance(arg0 context.Context, arg1 int, arg2 float64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IncrementBalance", arg0, arg1)
+	ret := m.ctrl.Call(m, "IncrementBalance", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // IncrementBalance indicates an expected call of IncrementBalance.
-func (mr *MockAccountRepositoryMockRecorder) IncrementBalance(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockAccountRepositoryMockRecorder) IncrementBalance(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementBalance", reflect.TypeOf((*MockAccountRepository)(nil).IncrementBalance), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementBalance", reflect.TypeOf((*MockAccountRepository)(nil).IncrementBalance), arg0, arg1, arg2)
 }
 
-// SaveAccount mocks base method.
-func (m *MockAccountRepository) SaveAccount(arg0 models.Account) (models.Account, error) {
+// PatchAccountById mocks base method.
+func (m *MockAccountRepository) PatchAccountById(arg0 context.Context, arg1 models.Account, arg2 map[string]interface{}) (models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveAccount", arg0)
+	ret := m.ctrl.Call(m, "PatchAccountById", arg0, arg1, arg2)
 	ret0, _ := ret[0].(models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SaveAccount indicates an expected call of SaveAccount.
-func (mr *MockAccountRepositoryMockRecorder) SaveAccount(arg0 interface{}) *gomock.Call {
+// PatchAccountById indicates an expected call of PatchAccountById.
+func (mr *MockAccountRepositoryMockRecorder) PatchAccountById(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAccount", reflect.TypeOf((*MockAccountRepository)(nil).SaveAccount), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchAccountById", reflect.TypeOf((*MockAccountRepository)(nil).PatchAccountById), arg0, arg1, arg2)
 }
 
-// SaveEntry mocks base method.
-func (m *MockAccountRepository) SaveEntry(arg0 *models.Entry) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveEntry", arg0)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// SaveEntry indicates an expected call of SaveEntry.
-func (mr *MockAccountRepositoryMockRecorder) SaveEntry(arg0 interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEntry", reflect.TypeOf((*MockAccountRepository)(nil).SaveEntry), arg0)
-}
-
-// SaveTransfer mocks base method.
-func (m *MockAccountRepository) SaveTransfer(arg0 *models.Transfer) (models.Transfer, error) {
+// SaveAccount mocks base method.
+func (m *MockAccountRepository) SaveAccount(arg0 context.Context, arg1 models.Account) (models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveTransfer", arg0)
-	ret0, _ := ret[0].(models.Transfer)
+	ret := m.ctrl.Call(m, "SaveAccount", arg0, arg1)
+	ret0, _ := ret[0].(models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SaveTransfer indicates an expected call of SaveTransfer.
-func (mr *MockAccountRepositoryMockRecorder) SaveTransfer(arg0 interface{}) *gomock.Call {
+// SaveAccount indicates an expected call of SaveAccount.
+func (mr *MockAccountRepositoryMockRecorder) SaveAccount(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTransfer", reflect.TypeOf((*MockAccountRepository)(nil).SaveTransfer), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAccount", reflect.TypeOf((*MockAccountRepository)(nil).SaveAccount), arg0, arg1)
 }
 
 // UpdateAccountById mocks base method.
-func (m *MockAccountRepository) UpdateAccountById(arg0, arg1 models.Account) (models.Account, error) {
+func (m *MockAccountRepository) UpdateAccountById(arg0 context.Context, arg1, arg2 models.Account) (models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAccountById", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdateAccountById", arg0, arg1, arg2)
 	ret0, _ := ret[0].(models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateAccountById indicates an expected call of UpdateAccountById.
-func (mr *MockAccountRepositoryMockRecorder) UpdateAccountById(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockAccountRepositoryMockRecorder) UpdateAccountById(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountById", reflect.TypeOf((*MockAccountRepository)(nil).UpdateAccountById), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountById", reflect.TypeOf((*MockAccountRepository)(nil).UpdateAccountById), arg0, arg1, arg2)
 }
 
 // WithTrx mocks base method.
-func (m *MockAccountRepository) WithTrx(arg0 *gorm.DB) repository.AccountRepositoryImpl {
+func (m *MockAccountRepository) WithTrx(arg0 *gorm.DB) repository.AccountRepository {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "WithTrx", arg0)
-	ret0, _ := ret[0].(repository.AccountRepositoryImpl)
+	ret0, _ := ret[0].(repository.AccountRepository)
 	return ret0
 }
 
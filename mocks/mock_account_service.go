@@ -5,13 +5,14 @@
 package mock
 
 import (
+	context "context"
+	json "encoding/json"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/rahul-024/fund-transfer-poc/models"
 	request "github.com/rahul-024/fund-transfer-poc/models/request"
 	service "github.com/rahul-024/fund-transfer-poc/service"
-	gorm "gorm.io/gorm"
 )
 
 // MockAccountService is a mock of AccountService interface.
@@ -37,147 +38,297 @@ func (m *MockAccountService) EXPECT() *MockAccountServiceMockRecorder {
 	return m.recorder
 }
 
+// AdjustBalance mocks base method.
+func (m *MockAccountService) AdjustBalance(ctx context.Context, id int, req *request.BalanceAdjustmentRequest) (models.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBalance", ctx, id, req)
+	ret0, _ := ret[0].(models.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustBalance indicates an expected call of AdjustBalance.
+func (mr *MockAccountServiceMockRecorder) AdjustBalance(ctx, id, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalance", reflect.TypeOf((*MockAccountService)(nil).AdjustBalance), ctx, id, req)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockAccountService) CreateTransfer(ctx context.Context, req *request.TransferRequest) (models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", ctx, req)
+	ret0, _ := ret[0].(models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockAccountServiceMockRecorder) CreateTransfer(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockAccountService)(nil).CreateTransfer), ctx, req)
+}
+
 // DecrementBalance mocks base method.
-func (m *MockAccountService) DecrementBalance(arg0 int, arg1 float64) error {
+func (m *MockAccountService) DecrementBalance(arg0 context.Context, arg1 int, arg2 float64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DecrementBalance", arg0, arg1)
+	ret := m.ctrl.Call(m, "DecrementBalance", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DecrementBalance indicates an expected call of DecrementBalance.
-func (mr *MockAccountServiceMockRecorder) DecrementBalance(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockAccountServiceMockRecorder) DecrementBalance(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrementBalance", reflect.TypeOf((*MockAccountService)(nil).DecrementBalance), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrementBalance", reflect.TypeOf((*MockAccountService)(nil).DecrementBalance), arg0, arg1, arg2)
 }
 
 // DeleteAccountById mocks base method.
-func (m *MockAccountService) DeleteAccountById(id int) error {
+func (m *MockAccountService) DeleteAccountById(ctx context.Context, id int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteAccountById", id)
+	ret := m.ctrl.Call(m, "DeleteAccountById", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteAccountById indicates an expected call of DeleteAccountById.
-func (mr *MockAccountServiceMockRecorder) DeleteAccountById(id interface{}) *gomock.Call {
+func (mr *MockAccountServiceMockRecorder) DeleteAccountById(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountById", reflect.TypeOf((*MockAccountService)(nil).DeleteAccountById), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccountById", reflect.TypeOf((*MockAccountService)(nil).DeleteAccountById), ctx, id)
 }
 
 // GetAccountById mocks base method.
-func (m *MockAccountService) GetAccountById(id int) (models.Account, error) {
+func (m *MockAccountService) GetAccountById(ctx context.Context, id int) (models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountById", id)
+	ret := m.ctrl.Call(m, "GetAccountById", ctx, id)
 	ret0, _ := ret[0].(models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAccountById indicates an expected call of GetAccountById.
-func (mr *MockAccountServiceMockRecorder) GetAccountById(id interface{}) *gomock.Call {
+func (mr *MockAccountServiceMockRecorder) GetAccountById(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountById", reflect.TypeOf((*MockAccountService)(nil).GetAccountById), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountById", reflect.TypeOf((*MockAccountService)(nil).GetAccountById), ctx, id)
 }
 
-// GetAll mocks base method.
-func (m *MockAccountService) GetAll(pageId, pageSize int) ([]models.Account, error) {
+// GetAccountsByIds mocks base method.
+func (m *MockAccountService) GetAccountsByIds(ctx context.Context, ids []int) ([]models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", pageId, pageSize)
+	ret := m.ctrl.Call(m, "GetAccountsByIds", ctx, ids)
 	ret0, _ := ret[0].([]models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// GetAccountsByIds indicates an expected call of GetAccountsByIds.
+func (mr *MockAccountServiceMockRecorder) GetAccountsByIds(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountsByIds", reflect.TypeOf((*MockAccountService)(nil).GetAccountsByIds), ctx, ids)
+}
+
+// GetAll mocks base method.
+func (m *MockAccountService) GetAll(ctx context.Context, req *request.ListAccountsRequest) (models.AccountPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, req)
+	ret0, _ := ret[0].(models.AccountPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // GetAll indicates an expected call of GetAll.
-func (mr *MockAccountServiceMockRecorder) GetAll(pageId, pageSize interface{}) *gomock.Call {
+func (mr *MockAccountServiceMockRecorder) GetAll(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockAccountService)(nil).GetAll), ctx, req)
+}
+
+// GetEntries mocks base method.
+func (m *MockAccountService) GetEntries(ctx context.Context, accountID int) ([]models.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntries", ctx, accountID)
+	ret0, _ := ret[0].([]models.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntries indicates an expected call of GetEntries.
+func (mr *MockAccountServiceMockRecorder) GetEntries(ctx, accountID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockAccountService)(nil).GetAll), pageId, pageSize)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntries", reflect.TypeOf((*MockAccountService)(nil).GetEntries), ctx, accountID)
+}
+
+// GetRecentEntries mocks base method.
+func (m *MockAccountService) GetRecentEntries(ctx context.Context, accountIDs []int, limit, beforeID int) (map[int][]models.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecentEntries", ctx, accountIDs, limit, beforeID)
+	ret0, _ := ret[0].(map[int][]models.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecentEntries indicates an expected call of GetRecentEntries.
+func (mr *MockAccountServiceMockRecorder) GetRecentEntries(ctx, accountIDs, limit, beforeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentEntries", reflect.TypeOf((*MockAccountService)(nil).GetRecentEntries), ctx, accountIDs, limit, beforeID)
+}
+
+// GetRecentTransfers mocks base method.
+func (m *MockAccountService) GetRecentTransfers(ctx context.Context, accountIDs []int, limit, beforeID int) (map[int][]models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecentTransfers", ctx, accountIDs, limit, beforeID)
+	ret0, _ := ret[0].(map[int][]models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecentTransfers indicates an expected call of GetRecentTransfers.
+func (mr *MockAccountServiceMockRecorder) GetRecentTransfers(ctx, accountIDs, limit, beforeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentTransfers", reflect.TypeOf((*MockAccountService)(nil).GetRecentTransfers), ctx, accountIDs, limit, beforeID)
+}
+
+// GetTransferById mocks base method.
+func (m *MockAccountService) GetTransferById(ctx context.Context, id int) (models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferById", ctx, id)
+	ret0, _ := ret[0].(models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferById indicates an expected call of GetTransferById.
+func (mr *MockAccountServiceMockRecorder) GetTransferById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferById", reflect.TypeOf((*MockAccountService)(nil).GetTransferById), ctx, id)
 }
 
 // IncrementBalance mocks base method.
-func (m *MockAccountService) IncrementBalance(arg0 int, arg1 float64) error {
+func (m *MockAccountService) IncrementBalance(arg0 context.Context, arg1 int, arg2 float64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IncrementBalance", arg0, arg1)
+	ret := m.ctrl.Call(m, "IncrementBalance", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // IncrementBalance indicates an expected call of IncrementBalance.
-func (mr *MockAccountServiceMockRecorder) IncrementBalance(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockAccountServiceMockRecorder) IncrementBalance(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementBalance", reflect.TypeOf((*MockAccountService)(nil).IncrementBalance), arg0, arg1, arg2)
+}
+
+// PatchAccountById mocks base method.
+func (m *MockAccountService) PatchAccountById(arg0 context.Context, arg1 models.Account, arg2 map[string]json.RawMessage) (models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchAccountById", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchAccountById indicates an expected call of PatchAccountById.
+func (mr *MockAccountServiceMockRecorder) PatchAccountById(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchAccountById", reflect.TypeOf((*MockAccountService)(nil).PatchAccountById), arg0, arg1, arg2)
+}
+
+// Reconcile mocks base method.
+func (m *MockAccountService) Reconcile(arg0 context.Context) ([]models.BalanceMismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", arg0)
+	ret0, _ := ret[0].([]models.BalanceMismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockAccountServiceMockRecorder) Reconcile(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementBalance", reflect.TypeOf((*MockAccountService)(nil).IncrementBalance), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockAccountService)(nil).Reconcile), arg0)
+}
+
+// RunInTx mocks base method.
+func (m *MockAccountService) RunInTx(ctx context.Context, fn func(service.AccountService) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunInTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunInTx indicates an expected call of RunInTx.
+func (mr *MockAccountServiceMockRecorder) RunInTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunInTx", reflect.TypeOf((*MockAccountService)(nil).RunInTx), ctx, fn)
 }
 
 // SaveAccount mocks base method.
-func (m *MockAccountService) SaveAccount(arg0 models.Account) (models.Account, error) {
+func (m *MockAccountService) SaveAccount(arg0 context.Context, arg1 models.Account) (models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveAccount", arg0)
+	ret := m.ctrl.Call(m, "SaveAccount", arg0, arg1)
 	ret0, _ := ret[0].(models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SaveAccount indicates an expected call of SaveAccount.
-func (mr *MockAccountServiceMockRecorder) SaveAccount(arg0 interface{}) *gomock.Call {
+func (mr *MockAccountServiceMockRecorder) SaveAccount(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAccount", reflect.TypeOf((*MockAccountService)(nil).SaveAccount), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAccount", reflect.TypeOf((*MockAccountService)(nil).SaveAccount), arg0, arg1)
 }
 
 // SaveEntry mocks base method.
-func (m *MockAccountService) SaveEntry(req *request.TransferRequest, dc string) error {
+func (m *MockAccountService) SaveEntry(ctx context.Context, req *request.TransferRequest, dc string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveEntry", req, dc)
+	ret := m.ctrl.Call(m, "SaveEntry", ctx, req, dc)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SaveEntry indicates an expected call of SaveEntry.
-func (mr *MockAccountServiceMockRecorder) SaveEntry(req, dc interface{}) *gomock.Call {
+func (mr *MockAccountServiceMockRecorder) SaveEntry(ctx, req, dc interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEntry", reflect.TypeOf((*MockAccountService)(nil).SaveEntry), req, dc)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEntry", reflect.TypeOf((*MockAccountService)(nil).SaveEntry), ctx, req, dc)
 }
 
 // SaveTransfer mocks base method.
-func (m *MockAccountService) SaveTransfer(req *request.TransferRequest) (models.Transfer, error) {
+func (m *MockAccountService) SaveTransfer(ctx context.Context, req *request.TransferRequest) (models.Transfer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveTransfer", req)
+	ret := m.ctrl.Call(m, "SaveTransfer", ctx, req)
 	ret0, _ := ret[0].(models.Transfer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SaveTransfer indicates an expected call of SaveTransfer.
-func (mr *MockAccountServiceMockRecorder) SaveTransfer(req interface{}) *gomock.Call {
+func (mr *MockAccountServiceMockRecorder) SaveTransfer(ctx, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTransfer", reflect.TypeOf((*MockAccountService)(nil).SaveTransfer), req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTransfer", reflect.TypeOf((*MockAccountService)(nil).SaveTransfer), ctx, req)
 }
 
-// UpdateAccountById mocks base method.
-func (m *MockAccountService) UpdateAccountById(arg0, arg1 models.Account) (models.Account, error) {
+// SetTransferLimit mocks base method.
+func (m *MockAccountService) SetTransferLimit(ctx context.Context, id int, req *request.TransferLimitRequest) (models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAccountById", arg0, arg1)
+	ret := m.ctrl.Call(m, "SetTransferLimit", ctx, id, req)
 	ret0, _ := ret[0].(models.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateAccountById indicates an expected call of UpdateAccountById.
-func (mr *MockAccountServiceMockRecorder) UpdateAccountById(arg0, arg1 interface{}) *gomock.Call {
+// SetTransferLimit indicates an expected call of SetTransferLimit.
+func (mr *MockAccountServiceMockRecorder) SetTransferLimit(ctx, id, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountById", reflect.TypeOf((*MockAccountService)(nil).UpdateAccountById), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTransferLimit", reflect.TypeOf((*MockAccountService)(nil).SetTransferLimit), ctx, id, req)
 }
 
-// WithTrx mocks base method.
-func (m *MockAccountService) WithTrx(arg0 *gorm.DB) service.AccountServiceImpl {
+// UpdateAccountById mocks base method.
+func (m *MockAccountService) UpdateAccountById(arg0 context.Context, arg1, arg2 models.Account) (models.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WithTrx", arg0)
-	ret0, _ := ret[0].(service.AccountServiceImpl)
-	return ret0
+	ret := m.ctrl.Call(m, "UpdateAccountById", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// WithTrx indicates an expected call of WithTrx.
-func (mr *MockAccountServiceMockRecorder) WithTrx(arg0 interface{}) *gomock.Call {
+// UpdateAccountById indicates an expected call of UpdateAccountById.
+func (mr *MockAccountServiceMockRecorder) UpdateAccountById(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTrx", reflect.TypeOf((*MockAccountService)(nil).WithTrx), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountById", reflect.TypeOf((*MockAccountService)(nil).UpdateAccountById), arg0, arg1, arg2)
 }
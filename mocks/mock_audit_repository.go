@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/audit_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/rahul-024/fund-transfer-poc/models"
+	repository "github.com/rahul-024/fund-transfer-poc/repository"
+	gorm "gorm.io/gorm"
+)
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetAuditEvents mocks base method.
+func (m *MockAuditRepository) GetAuditEvents(arg0 context.Context, arg1 repository.AuditQuery) ([]models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockAuditRepositoryMockRecorder) GetAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockAuditRepository)(nil).GetAuditEvents), arg0, arg1)
+}
+
+// SaveAuditEvent mocks base method.
+func (m *MockAuditRepository) SaveAuditEvent(arg0 *models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAuditEvent", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAuditEvent indicates an expected call of SaveAuditEvent.
+func (mr *MockAuditRepositoryMockRecorder) SaveAuditEvent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAuditEvent", reflect.TypeOf((*MockAuditRepository)(nil).SaveAuditEvent), arg0)
+}
+
+// WithTrx mocks base method.
+func (m *MockAuditRepository) WithTrx(arg0 *gorm.DB) repository.AuditRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTrx", arg0)
+	ret0, _ := ret[0].(repository.AuditRepository)
+	return ret0
+}
+
+// WithTrx indicates an expected call of WithTrx.
+func (mr *MockAuditRepositoryMockRecorder) WithTrx(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTrx", reflect.TypeOf((*MockAuditRepository)(nil).WithTrx), arg0)
+}
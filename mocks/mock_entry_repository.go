@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/entry_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/rahul-024/fund-transfer-poc/models"
+	repository "github.com/rahul-024/fund-transfer-poc/repository"
+	gorm "gorm.io/gorm"
+)
+
+// MockEntryRepository is a mock of EntryRepository interface.
+type MockEntryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEntryRepositoryMockRecorder
+}
+
+// MockEntryRepositoryMockRecorder is the mock recorder for MockEntryRepository.
+type MockEntryRepositoryMockRecorder struct {
+	mock *MockEntryRepository
+}
+
+// NewMockEntryRepository creates a new mock instance.
+func NewMockEntryRepository(ctrl *gomock.Controller) *MockEntryRepository {
+	mock := &MockEntryRepository{ctrl: ctrl}
+	mock.recorder = &MockEntryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEntryRepository) EXPECT() *MockEntryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetBalanceMismatches mocks base method.
+func (m *MockEntryRepository) GetBalanceMismatches(arg0 context.Context) ([]models.BalanceMismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceMismatches", arg0)
+	ret0, _ := ret[0].([]models.BalanceMismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceMismatches indicates an expected call of GetBalanceMismatches.
+func (mr *MockEntryRepositoryMockRecorder) GetBalanceMismatches(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceMismatches", reflect.TypeOf((*MockEntryRepository)(nil).GetBalanceMismatches), arg0)
+}
+
+// GetEntriesByAccountId mocks base method.
+func (m *MockEntryRepository) GetEntriesByAccountId(ctx context.Context, accountID int) ([]models.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntriesByAccountId", ctx, accountID)
+	ret0, _ := ret[0].([]models.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntriesByAccountId indicates an expected call of GetEntriesByAccountId.
+func (mr *MockEntryRepositoryMockRecorder) GetEntriesByAccountId(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntriesByAccountId", reflect.TypeOf((*MockEntryRepository)(nil).GetEntriesByAccountId), ctx, accountID)
+}
+
+// GetRecentEntries mocks base method.
+func (m *MockEntryRepository) GetRecentEntries(ctx context.Context, accountIDs []int, limit, beforeID int) (map[int][]models.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecentEntries", ctx, accountIDs, limit, beforeID)
+	ret0, _ := ret[0].(map[int][]models.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecentEntries indicates an expected call of GetRecentEntries.
+func (mr *MockEntryRepositoryMockRecorder) GetRecentEntries(ctx, accountIDs, limit, beforeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentEntries", reflect.TypeOf((*MockEntryRepository)(nil).GetRecentEntries), ctx, accountIDs, limit, beforeID)
+}
+
+// SaveEntry mocks base method.
+func (m *MockEntryRepository) SaveEntry(arg0 context.Context, arg1 *models.Entry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEntry", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveEntry indicates an expected call of SaveEntry.
+func (mr *MockEntryRepositoryMockRecorder) SaveEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEntry", reflect.TypeOf((*MockEntryRepository)(nil).SaveEntry), arg0, arg1)
+}
+
+// WithTrx mocks base method.
+func (m *MockEntryRepository) WithTrx(arg0 *gorm.DB) repository.EntryRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTrx", arg0)
+	ret0, _ := ret[0].(repository.EntryRepository)
+	return ret0
+}
+
+// WithTrx indicates an expected call of WithTrx.
+func (mr *MockEntryRepositoryMockRecorder) WithTrx(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTrx", reflect.TypeOf((*MockEntryRepository)(nil).WithTrx), arg0)
+}
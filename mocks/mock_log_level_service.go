@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service/log_level_service.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/rahul-024/fund-transfer-poc/models"
+	request "github.com/rahul-024/fund-transfer-poc/models/request"
+)
+
+// MockLogLevelService is a mock of LogLevelService interface.
+type MockLogLevelService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLogLevelServiceMockRecorder
+}
+
+// MockLogLevelServiceMockRecorder is the mock recorder for MockLogLevelService.
+type MockLogLevelServiceMockRecorder struct {
+	mock *MockLogLevelService
+}
+
+// NewMockLogLevelService creates a new mock instance.
+func NewMockLogLevelService(ctrl *gomock.Controller) *MockLogLevelService {
+	mock := &MockLogLevelService{ctrl: ctrl}
+	mock.recorder = &MockLogLevelServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLogLevelService) EXPECT() *MockLogLevelServiceMockRecorder {
+	return m.recorder
+}
+
+// GetLogLevel mocks base method.
+func (m *MockLogLevelService) GetLogLevel(ctx context.Context) (models.LogLevel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLogLevel", ctx)
+	ret0, _ := ret[0].(models.LogLevel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLogLevel indicates an expected call of GetLogLevel.
+func (mr *MockLogLevelServiceMockRecorder) GetLogLevel(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLogLevel", reflect.TypeOf((*MockLogLevelService)(nil).GetLogLevel), ctx)
+}
+
+// ResetLogLevel mocks base method.
+func (m *MockLogLevelService) ResetLogLevel(ctx context.Context) (models.LogLevel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLogLevel", ctx)
+	ret0, _ := ret[0].(models.LogLevel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetLogLevel indicates an expected call of ResetLogLevel.
+func (mr *MockLogLevelServiceMockRecorder) ResetLogLevel(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLogLevel", reflect.TypeOf((*MockLogLevelService)(nil).ResetLogLevel), ctx)
+}
+
+// SetLogLevel mocks base method.
+func (m *MockLogLevelService) SetLogLevel(ctx context.Context, req *request.LogLevelRequest) (models.LogLevel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLogLevel", ctx, req)
+	ret0, _ := ret[0].(models.LogLevel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLogLevel indicates an expected call of SetLogLevel.
+func (mr *MockLogLevelServiceMockRecorder) SetLogLevel(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLogLevel", reflect.TypeOf((*MockLogLevelService)(nil).SetLogLevel), ctx, req)
+}
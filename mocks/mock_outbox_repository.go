@@ -0,0 +1,121 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/outbox_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/rahul-024/fund-transfer-poc/models"
+	repository "github.com/rahul-024/fund-transfer-poc/repository"
+	gorm "gorm.io/gorm"
+)
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimPendingOutboxEvents mocks base method.
+func (m *MockOutboxRepository) ClaimPendingOutboxEvents(limit int, process func(repository.OutboxRepository, []models.OutboxEvent) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPendingOutboxEvents", limit, process)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimPendingOutboxEvents indicates an expected call of ClaimPendingOutboxEvents.
+func (mr *MockOutboxRepositoryMockRecorder) ClaimPendingOutboxEvents(limit, process interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPendingOutboxEvents", reflect.TypeOf((*MockOutboxRepository)(nil).ClaimPendingOutboxEvents), limit, process)
+}
+
+// MarkOutboxEventDeadLettered mocks base method.
+func (m *MockOutboxRepository) MarkOutboxEventDeadLettered(id int, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventDeadLettered", id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventDeadLettered indicates an expected call of MarkOutboxEventDeadLettered.
+func (mr *MockOutboxRepositoryMockRecorder) MarkOutboxEventDeadLettered(id, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventDeadLettered", reflect.TypeOf((*MockOutboxRepository)(nil).MarkOutboxEventDeadLettered), id, reason)
+}
+
+// MarkOutboxEventFailed mocks base method.
+func (m *MockOutboxRepository) MarkOutboxEventFailed(id int, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventFailed", id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventFailed indicates an expected call of MarkOutboxEventFailed.
+func (mr *MockOutboxRepositoryMockRecorder) MarkOutboxEventFailed(id, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventFailed", reflect.TypeOf((*MockOutboxRepository)(nil).MarkOutboxEventFailed), id, reason)
+}
+
+// MarkOutboxEventPublished mocks base method.
+func (m *MockOutboxRepository) MarkOutboxEventPublished(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventPublished", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventPublished indicates an expected call of MarkOutboxEventPublished.
+func (mr *MockOutboxRepositoryMockRecorder) MarkOutboxEventPublished(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventPublished", reflect.TypeOf((*MockOutboxRepository)(nil).MarkOutboxEventPublished), id)
+}
+
+// SaveOutboxEvent mocks base method.
+func (m *MockOutboxRepository) SaveOutboxEvent(arg0 *models.OutboxEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOutboxEvent", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOutboxEvent indicates an expected call of SaveOutboxEvent.
+func (mr *MockOutboxRepositoryMockRecorder) SaveOutboxEvent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOutboxEvent", reflect.TypeOf((*MockOutboxRepository)(nil).SaveOutboxEvent), arg0)
+}
+
+// WithTrx mocks base method.
+func (m *MockOutboxRepository) WithTrx(arg0 *gorm.DB) repository.OutboxRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTrx", arg0)
+	ret0, _ := ret[0].(repository.OutboxRepository)
+	return ret0
+}
+
+// WithTrx indicates an expected call of WithTrx.
+func (mr *MockOutboxRepositoryMockRecorder) WithTrx(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTrx", reflect.TypeOf((*MockOutboxRepository)(nil).WithTrx), arg0)
+}
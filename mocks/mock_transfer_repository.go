@@ -0,0 +1,97 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/transfer_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/rahul-024/fund-transfer-poc/models"
+	repository "github.com/rahul-024/fund-transfer-poc/repository"
+	gorm "gorm.io/gorm"
+)
+
+// MockTransferRepository is a mock of TransferRepository interface.
+type MockTransferRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransferRepositoryMockRecorder
+}
+
+// MockTransferRepositoryMockRecorder is the mock recorder for MockTransferRepository.
+type MockTransferRepositoryMockRecorder struct {
+	mock *MockTransferRepository
+}
+
+// NewMockTransferRepository creates a new mock instance.
+func NewMockTransferRepository(ctrl *gomock.Controller) *MockTransferRepository {
+	mock := &MockTransferRepository{ctrl: ctrl}
+	mock.recorder = &MockTransferRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransferRepository) EXPECT() *MockTransferRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetRecentTransfers mocks base method.
+func (m *MockTransferRepository) GetRecentTransfers(ctx context.Context, accountIDs []int, limit, beforeID int) (map[int][]models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecentTransfers", ctx, accountIDs, limit, beforeID)
+	ret0, _ := ret[0].(map[int][]models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecentTransfers indicates an expected call of GetRecentTransfers.
+func (mr *MockTransferRepositoryMockRecorder) GetRecentTransfers(ctx, accountIDs, limit, beforeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentTransfers", reflect.TypeOf((*MockTransferRepository)(nil).GetRecentTransfers), ctx, accountIDs, limit, beforeID)
+}
+
+// GetTransferById mocks base method.
+func (m *MockTransferRepository) GetTransferById(ctx context.Context, id int) (models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferById", ctx, id)
+	ret0, _ := ret[0].(models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferById indicates an expected call of GetTransferById.
+func (mr *MockTransferRepositoryMockRecorder) GetTransferById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferById", reflect.TypeOf((*MockTransferRepository)(nil).GetTransferById), ctx, id)
+}
+
+// SaveTransfer mocks base method.
+func (m *MockTransferRepository) SaveTransfer(arg0 context.Context, arg1 *models.Transfer) (models.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTransfer", arg0, arg1)
+	ret0, _ := ret[0].(models.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveTransfer indicates an expected call of SaveTransfer.
+func (mr *MockTransferRepositoryMockRecorder) SaveTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTransfer", reflect.TypeOf((*MockTransferRepository)(nil).SaveTransfer), arg0, arg1)
+}
+
+// WithTrx mocks base method.
+func (m *MockTransferRepository) WithTrx(arg0 *gorm.DB) repository.TransferRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTrx", arg0)
+	ret0, _ := ret[0].(repository.TransferRepository)
+	return ret0
+}
+
+// WithTrx indicates an expected call of WithTrx.
+func (mr *MockTransferRepositoryMockRecorder) WithTrx(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTrx", reflect.TypeOf((*MockTransferRepository)(nil).WithTrx), arg0)
+}
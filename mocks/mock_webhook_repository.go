@@ -0,0 +1,228 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/webhook_repository.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/rahul-024/fund-transfer-poc/models"
+	repository "github.com/rahul-024/fund-transfer-poc/repository"
+	gorm "gorm.io/gorm"
+)
+
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimDueWebhookDeliveries mocks base method.
+func (m *MockWebhookRepository) ClaimDueWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueWebhookDeliveries", ctx, now, lease, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueWebhookDeliveries indicates an expected call of ClaimDueWebhookDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) ClaimDueWebhookDeliveries(ctx, now, lease, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueWebhookDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).ClaimDueWebhookDeliveries), ctx, now, lease, limit)
+}
+
+// DeleteWebhookSubscriptionById mocks base method.
+func (m *MockWebhookRepository) DeleteWebhookSubscriptionById(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookSubscriptionById", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookSubscriptionById indicates an expected call of DeleteWebhookSubscriptionById.
+func (mr *MockWebhookRepositoryMockRecorder) DeleteWebhookSubscriptionById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookSubscriptionById", reflect.TypeOf((*MockWebhookRepository)(nil).DeleteWebhookSubscriptionById), ctx, id)
+}
+
+// GetActiveWebhookSubscriptions mocks base method.
+func (m *MockWebhookRepository) GetActiveWebhookSubscriptions(arg0 context.Context) ([]models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveWebhookSubscriptions", arg0)
+	ret0, _ := ret[0].([]models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveWebhookSubscriptions indicates an expected call of GetActiveWebhookSubscriptions.
+func (mr *MockWebhookRepositoryMockRecorder) GetActiveWebhookSubscriptions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveWebhookSubscriptions", reflect.TypeOf((*MockWebhookRepository)(nil).GetActiveWebhookSubscriptions), arg0)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockWebhookRepository) GetWebhookDeliveries(ctx context.Context, subscriptionID int) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, subscriptionID)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhookDeliveries(ctx, subscriptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhookDeliveries), ctx, subscriptionID)
+}
+
+// GetWebhookDeliveryAttempts mocks base method.
+func (m *MockWebhookRepository) GetWebhookDeliveryAttempts(ctx context.Context, deliveryID int) ([]models.WebhookDeliveryAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveryAttempts", ctx, deliveryID)
+	ret0, _ := ret[0].([]models.WebhookDeliveryAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveryAttempts indicates an expected call of GetWebhookDeliveryAttempts.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhookDeliveryAttempts(ctx, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveryAttempts", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhookDeliveryAttempts), ctx, deliveryID)
+}
+
+// GetWebhookDeliveryById mocks base method.
+func (m *MockWebhookRepository) GetWebhookDeliveryById(ctx context.Context, id int) (models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveryById", ctx, id)
+	ret0, _ := ret[0].(models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveryById indicates an expected call of GetWebhookDeliveryById.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhookDeliveryById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveryById", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhookDeliveryById), ctx, id)
+}
+
+// GetWebhookSubscriptionById mocks base method.
+func (m *MockWebhookRepository) GetWebhookSubscriptionById(ctx context.Context, id int) (models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscriptionById", ctx, id)
+	ret0, _ := ret[0].(models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscriptionById indicates an expected call of GetWebhookSubscriptionById.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhookSubscriptionById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscriptionById", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhookSubscriptionById), ctx, id)
+}
+
+// GetWebhookSubscriptions mocks base method.
+func (m *MockWebhookRepository) GetWebhookSubscriptions(arg0 context.Context) ([]models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscriptions", arg0)
+	ret0, _ := ret[0].([]models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscriptions indicates an expected call of GetWebhookSubscriptions.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhookSubscriptions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscriptions", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhookSubscriptions), arg0)
+}
+
+// SaveWebhookDelivery mocks base method.
+func (m *MockWebhookRepository) SaveWebhookDelivery(arg0 context.Context, arg1 *models.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebhookDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebhookDelivery indicates an expected call of SaveWebhookDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) SaveWebhookDelivery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhookDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).SaveWebhookDelivery), arg0, arg1)
+}
+
+// SaveWebhookDeliveryAttempt mocks base method.
+func (m *MockWebhookRepository) SaveWebhookDeliveryAttempt(arg0 context.Context, arg1 *models.WebhookDeliveryAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebhookDeliveryAttempt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebhookDeliveryAttempt indicates an expected call of SaveWebhookDeliveryAttempt.
+func (mr *MockWebhookRepositoryMockRecorder) SaveWebhookDeliveryAttempt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhookDeliveryAttempt", reflect.TypeOf((*MockWebhookRepository)(nil).SaveWebhookDeliveryAttempt), arg0, arg1)
+}
+
+// SaveWebhookSubscription mocks base method.
+func (m *MockWebhookRepository) SaveWebhookSubscription(arg0 context.Context, arg1 *models.WebhookSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebhookSubscription", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebhookSubscription indicates an expected call of SaveWebhookSubscription.
+func (mr *MockWebhookRepositoryMockRecorder) SaveWebhookSubscription(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhookSubscription", reflect.TypeOf((*MockWebhookRepository)(nil).SaveWebhookSubscription), arg0, arg1)
+}
+
+// UpdateWebhookDelivery mocks base method.
+func (m *MockWebhookRepository) UpdateWebhookDelivery(arg0 context.Context, arg1 *models.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookDelivery indicates an expected call of UpdateWebhookDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) UpdateWebhookDelivery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).UpdateWebhookDelivery), arg0, arg1)
+}
+
+// WithTrx mocks base method.
+func (m *MockWebhookRepository) WithTrx(arg0 *gorm.DB) repository.WebhookRepositoryImpl {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTrx", arg0)
+	ret0, _ := ret[0].(repository.WebhookRepositoryImpl)
+	return ret0
+}
+
+// WithTrx indicates an expected call of WithTrx.
+func (mr *MockWebhookRepositoryMockRecorder) WithTrx(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTrx", reflect.TypeOf((*MockWebhookRepository)(nil).WithTrx), arg0)
+}
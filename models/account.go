@@ -2,19 +2,29 @@ package models
 
 import "time"
 
+// Constants for the lifecycle status of an account
+const (
+	AccountStatusActive = "ACTIVE"
+	AccountStatusClosed = "CLOSED"
+)
+
 type Account struct {
 	Id        int       `json:"id" gorm:"primary_key"`
 	Currency  string    `json:"currency"`
 	Owner     string    `json:"owner"`
 	Balance   float64   `json:"balance"`
+	Status    string    `json:"status"`
 	CreatedAt time.Time `json:"created_at"`
+	// TransferLimit is the largest amount of one transfer out of the account, there is no limit when it is 0
+	TransferLimit float64 `json:"transfer_limit,omitempty"`
 }
 
 type Entry struct {
-	Id        int       `json:"id" gorm:"primary_key"`
-	AccountID int       `json:"account_id"`
-	Amount    float64   `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
+	Id         int       `json:"id" gorm:"primary_key"`
+	AccountID  int       `json:"account_id"`
+	Amount     float64   `json:"amount"`
+	ReasonCode string    `json:"reason_code,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type Transfer struct {
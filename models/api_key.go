@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// APIKey authenticates a machine client through the X-API-Key header. Only the SHA-256 hash of the key is
+// stored, the key itself is returned once when it is issued. Prefix identifies the key in listings and logs.
+// A key acts on the accounts of Owner with its scopes, or on every account when Role is teller.
+type APIKey struct {
+	Id         int        `json:"id" gorm:"primary_key"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json"`
+	Owner      string     `json:"owner,omitempty"`
+	Role       string     `json:"role,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// Key is only set on the response issuing the key
+	Key string `json:"key,omitempty" gorm:"-"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Constants for the operations recorded in the audit log
+const (
+	AuditAccountCreated  = "ACCOUNT_CREATED"
+	AuditAccountUpdated  = "ACCOUNT_UPDATED"
+	AuditAccountDeleted  = "ACCOUNT_DELETED"
+	AuditBalanceAdjusted = "BALANCE_ADJUSTED"
+	AuditTransferCreated = "TRANSFER_CREATED"
+)
+
+// Constants for the entity types recorded in the audit log
+const (
+	AuditEntityAccount  = "account"
+	AuditEntityTransfer = "transfer"
+)
+
+// AuditEvent is an append-only record of a state-changing operation.
+// Before and After hold JSON snapshots of the entity, either may be empty.
+type AuditEvent struct {
+	Id         int             `json:"id" gorm:"primary_key"`
+	Actor      string          `json:"actor"`
+	RequestID  string          `json:"request_id"`
+	Operation  string          `json:"operation"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int             `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After      json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	SourceIP   string          `json:"source_ip"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
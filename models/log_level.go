@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// LogLevel is the log level of the instance answering. An override set through the admin endpoint only applies
+// to that instance and expires at OverrideUntil, the configured level of the profile is in effect again then.
+type LogLevel struct {
+	Level           string     `json:"level"`
+	ConfiguredLevel string     `json:"configured_level"`
+	OverrideUntil   *time.Time `json:"override_until,omitempty"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent is a domain event written in the same transaction as the change it describes.
+// PublishedAt stays empty until the relay has handed the event to the sink. RequestID correlates the event
+// with the request that caused it, it is empty for the changes made by the command line tools.
+type OutboxEvent struct {
+	Id            int             `json:"id" gorm:"primary_key"`
+	EventType     string          `json:"type"`
+	SchemaVersion int             `json:"version"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int             `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"data" swaggertype:"object"`
+	RequestID     string          `json:"request_id,omitempty"`
+	Attempts      int             `json:"-"`
+	LastError     string          `json:"-"`
+	PublishedAt   *time.Time      `json:"-"`
+	// FailedAt is set when the event is dead-lettered after too many failed deliveries
+	FailedAt  *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"occurred_at"`
+	// Owner is the owner of the account, it routes the event to the webhook subscriptions of that owner
+	Owner string `json:"-"`
+}
+
+// TableName keeps the table name singular, the outbox is a single queue
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
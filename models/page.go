@@ -0,0 +1,10 @@
+package models
+
+// AccountPage is the response envelope for a page of accounts.
+// NextCursor is set in cursor mode and NextPageID in offset mode, both only when more rows follow.
+type AccountPage struct {
+	Data       []Account `json:"data"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	NextPageID int       `json:"next_page_id,omitempty"`
+	Total      *int64    `json:"total,omitempty"`
+} // @name AccountPage
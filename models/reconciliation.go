@@ -0,0 +1,8 @@
+package models
+
+// BalanceMismatch is an account whose stored balance differs from the sum of its ledger entries
+type BalanceMismatch struct {
+	AccountID     int     `json:"account_id"`
+	Balance       float64 `json:"balance"`
+	LedgerBalance float64 `json:"ledger_balance"`
+}
@@ -1,8 +1,37 @@
 package request
 
+import "time"
+
 type TransferRequest struct {
 	FromAccountID int     `json:"from_account_id" mapper:"fromAccountId"`
 	ToAccountID   int     `json:"to_account_id" mapper:"toAccountId"`
 	Amount        float64 `json:"amount" mapper:"amount"`
 	Currency      string  `json:"currency"`
 }
+
+// BalanceAdjustmentRequest is the only way to change an account balance outside of a transfer.
+// A positive amount credits the account and a negative amount debits it.
+type BalanceAdjustmentRequest struct {
+	Amount     float64 `json:"amount" binding:"required"`
+	ReasonCode string  `json:"reason_code" binding:"required"`
+} // @name BalanceAdjustmentRequest
+
+// TransferLimitRequest sets the largest amount of one transfer out of an account, 0 removes the limit
+type TransferLimitRequest struct {
+	Limit *float64 `json:"limit" binding:"required,gte=0"`
+} // @name TransferLimitRequest
+
+// ListAccountsRequest holds the pagination, filter and sort options for listing accounts.
+// Cursor and PageID are mutually exclusive, PageID selects the offset mode.
+type ListAccountsRequest struct {
+	Cursor       string    `form:"cursor"`
+	PageID       int       `form:"page_id" binding:"omitempty,min=1"`
+	PageSize     int       `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Owner        string    `form:"owner"`
+	Currency     string    `form:"currency"`
+	Status       string    `form:"status"`
+	CreatedFrom  time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo    time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort         string    `form:"sort"`
+	IncludeTotal bool      `form:"include_total"`
+} // @name ListAccountsRequest
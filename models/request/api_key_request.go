@@ -0,0 +1,10 @@
+package request
+
+// CreateAPIKeyRequest issues an API key granted the scopes, for example accounts:read or transfers:write.
+// The key acts on the accounts of Owner, or on every account with the teller Role, one of them is required.
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+	Owner  string   `json:"owner"`
+	Role   string   `json:"role" enums:"teller"`
+} // @name CreateAPIKeyRequest
@@ -0,0 +1,16 @@
+package request
+
+import "time"
+
+// ListAuditEventsRequest holds the filters compliance can apply to the audit log
+type ListAuditEventsRequest struct {
+	Actor      string    `form:"actor"`
+	Operation  string    `form:"operation"`
+	EntityType string    `form:"entity_type"`
+	EntityID   int       `form:"entity_id"`
+	RequestID  string    `form:"request_id"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	PageID     int       `form:"page_id" binding:"omitempty,min=1"`
+	PageSize   int       `form:"page_size" binding:"omitempty,min=1,max=100"`
+} // @name ListAuditEventsRequest
@@ -0,0 +1,7 @@
+package request
+
+// LogLevelRequest overrides the log level for TTLSeconds, 15 minutes when unset, up to a day
+type LogLevelRequest struct {
+	Level      string `json:"level" binding:"required,oneof=debug info warn error"`
+	TTLSeconds int    `json:"ttl_seconds" binding:"omitempty,min=1,max=86400"`
+} // @name LogLevelRequest
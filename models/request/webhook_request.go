@@ -0,0 +1,8 @@
+package request
+
+// CreateWebhookSubscriptionRequest registers a URL for event types, a secret is generated when empty
+type CreateWebhookSubscriptionRequest struct {
+	Url        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"event_types" binding:"required,min=1"`
+	Secret     string   `json:"secret"`
+} // @name CreateWebhookSubscriptionRequest
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// RequestNonce is a nonce a partner signed a request with, it is kept until the signature expires so that
+// the request cannot be replayed
+type RequestNonce struct {
+	Id        int       `json:"id" gorm:"primary_key"`
+	PartnerID string    `json:"partner_id"`
+	Nonce     string    `json:"nonce"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Constants for the status of a webhook delivery
+const (
+	DeliveryPending   = "PENDING"
+	DeliveryDelivered = "DELIVERED"
+	DeliveryFailed    = "FAILED"
+)
+
+// WebhookSubscription registers a partner URL for a set of event types, "*" subscribes to every type.
+// The secret signs the deliveries and is only returned when the subscription is created. A subscription
+// with an owner only receives the events of the accounts of that owner, the others receive every event.
+type WebhookSubscription struct {
+	Id         int       `json:"id" gorm:"primary_key"`
+	Url        string    `json:"url"`
+	EventTypes []string  `json:"event_types" gorm:"serializer:json"`
+	Owner      string    `json:"owner,omitempty"`
+	Secret     string    `json:"secret,omitempty"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Matches returns true if the subscription wants events of the given type
+func (s WebhookSubscription) Matches(eventType string) bool {
+	for _, t := range s.EventTypes {
+		if t == "*" || t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Sees returns true if the subscription receives the events of the accounts of owner
+func (s WebhookSubscription) Sees(owner string) bool {
+	return s.Owner == "" || s.Owner == owner
+}
+
+// WebhookDelivery is one event to be delivered to one subscription
+type WebhookDelivery struct {
+	Id             int             `json:"id" gorm:"primary_key"`
+	SubscriptionID int             `json:"subscription_id"`
+	OutboxEventID  int             `json:"outbox_event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// WebhookDeliveryAttempt records the outcome of one HTTP call made for a delivery
+type WebhookDeliveryAttempt struct {
+	Id         int       `json:"id" gorm:"primary_key"`
+	DeliveryID int       `json:"delivery_id"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,117 @@
+// Package outbox defines the domain events published to downstream systems and the relay
+// that drains the outbox table to a sink
+package outbox
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/models"
+)
+
+// Constants for the published event types
+const (
+	AccountCreated    = "AccountCreated"
+	AccountUpdated    = "AccountUpdated"
+	AccountDeleted    = "AccountDeleted"
+	BalanceAdjusted   = "BalanceAdjusted"
+	TransferCompleted = "TransferCompleted"
+)
+
+// AggregateAccount is the aggregate type of every event, events are ordered per account.
+// TransferCompleted is published once for each account of the transfer, consumers tell the two
+// copies apart by the aggregate id and deduplicate by transfer id.
+const AggregateAccount = "account"
+
+// schemaVersions holds the current payload schema version of every event type.
+// Bump the version and add a new schema file whenever a payload changes incompatibly.
+var schemaVersions = map[string]int{
+	AccountCreated:    1,
+	AccountUpdated:    1,
+	AccountDeleted:    1,
+	BalanceAdjusted:   1,
+	TransferCompleted: 1,
+}
+
+//go:embed schemas/*.json
+var schemas embed.FS
+
+// AccountPayload is the payload of AccountCreated and AccountUpdated
+type AccountPayload struct {
+	AccountID int       `json:"account_id"`
+	Owner     string    `json:"owner"`
+	Currency  string    `json:"currency"`
+	Balance   float64   `json:"balance"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AccountDeletedPayload is the payload of AccountDeleted
+type AccountDeletedPayload struct {
+	AccountID int `json:"account_id"`
+}
+
+// BalanceAdjustedPayload is the payload of BalanceAdjusted
+type BalanceAdjustedPayload struct {
+	AccountID  int     `json:"account_id"`
+	EntryID    int     `json:"entry_id"`
+	Amount     float64 `json:"amount"`
+	ReasonCode string  `json:"reason_code"`
+	Balance    float64 `json:"balance"`
+}
+
+// TransferCompletedPayload is the payload of TransferCompleted
+type TransferCompletedPayload struct {
+	TransferID    int       `json:"transfer_id"`
+	FromAccountID int       `json:"from_account_id"`
+	ToAccountID   int       `json:"to_account_id"`
+	Amount        float64   `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// NewAccountPayload builds the payload describing the state of an account
+func NewAccountPayload(account models.Account) AccountPayload {
+	return AccountPayload{
+		AccountID: account.Id,
+		Owner:     account.Owner,
+		Currency:  account.Currency,
+		Balance:   account.Balance,
+		Status:    account.Status,
+		CreatedAt: account.CreatedAt,
+	}
+}
+
+// NewEvent builds the outbox row for an event of the given type about an account
+func NewEvent(eventType string, accountID int, payload interface{}) (*models.OutboxEvent, error) {
+	version, ok := schemaVersions[eventType]
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %q", eventType)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &models.OutboxEvent{
+		EventType:     eventType,
+		SchemaVersion: version,
+		AggregateType: AggregateAccount,
+		AggregateID:   accountID,
+		Payload:       data,
+	}, nil
+}
+
+// Schema returns the JSON schema document of an event type at a version
+func Schema(eventType string, version int) ([]byte, error) {
+	return schemas.ReadFile(fmt.Sprintf("schemas/%s.v%d.json", eventType, version))
+}
+
+// EventTypes returns every event type with its current schema version
+func EventTypes() map[string]int {
+	types := make(map[string]int, len(schemaVersions))
+	for eventType, version := range schemaVersions {
+		types[eventType] = version
+	}
+	return types
+}
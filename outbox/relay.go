@@ -0,0 +1,105 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+)
+
+// RelayConfig holds the batching and retry policy of the relay
+type RelayConfig struct {
+	BatchSize    int
+	PollInterval time.Duration
+	// deliveries of an event before it is dead-lettered, 0 retries forever
+	MaxAttempts int
+}
+
+// Relay drains pending outbox events to a sink. Events are claimed with FOR UPDATE SKIP LOCKED so that
+// concurrent relays never deliver the same event at the same time, but the per-account ordering is only
+// guaranteed when a single relay runs against a database.
+type Relay struct {
+	outboxRepository repository.OutboxRepository
+	sink             Sink
+	config           RelayConfig
+}
+
+func NewRelay(r repository.OutboxRepository, sink Sink, config RelayConfig) *Relay {
+	return &Relay{
+		outboxRepository: r,
+		sink:             sink,
+		config:           config,
+	}
+}
+
+// Run drains the outbox every poll interval until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) {
+	logger.Log.With("poll_interval", r.config.PollInterval).Info("outbox relay started")
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("outbox relay stopped")
+			return
+		case <-ticker.C:
+			if _, err := r.Drain(ctx); err != nil {
+				logger.Log.With("error", err).Error("outbox relay pass failed")
+			}
+		}
+	}
+}
+
+// Drain publishes one batch of pending events in the order they were written and returns how many
+// were delivered. When delivery of an event fails, the later events of the same account are held
+// back until the next pass so that consumers never see them out of order. An event failing
+// MaxAttempts times is dead-lettered and no longer holds back the events of its account.
+func (r *Relay) Drain(ctx context.Context) (published int, err error) {
+	err = r.outboxRepository.ClaimPendingOutboxEvents(r.config.BatchSize, func(claimed repository.OutboxRepository, events []models.OutboxEvent) error {
+		blocked := make(map[int]bool)
+		for _, event := range events {
+			// the events already marked are committed, stopping rolls back nothing
+			if ctx.Err() != nil {
+				return nil
+			}
+			if blocked[event.AggregateID] {
+				continue
+			}
+			// the lines logged while publishing carry the id of the request that caused the event
+			eventCtx := ctx
+			if event.RequestID != "" {
+				eventCtx = logger.NewContext(ctx, event.RequestID)
+			}
+			if err := r.sink.Publish(eventCtx, event); err != nil {
+				if err := r.fail(eventCtx, claimed, event, err); err != nil {
+					return err
+				}
+				blocked[event.AggregateID] = r.config.MaxAttempts <= 0 || event.Attempts+1 < r.config.MaxAttempts
+				continue
+			}
+			if err := claimed.MarkOutboxEventPublished(event.Id); err != nil {
+				return err
+			}
+			published++
+		}
+		return nil
+	})
+	if err == nil {
+		err = ctx.Err()
+	}
+	return published, err
+}
+
+// fail records a failed delivery, dead-lettering the event once it used up its attempts
+func (r *Relay) fail(ctx context.Context, claimed repository.OutboxRepository, event models.OutboxEvent, cause error) error {
+	log := logger.FromContext(ctx).With("event_id", event.Id, "event_type", event.EventType, "account_id", event.AggregateID,
+		"attempts", event.Attempts+1, "error", cause)
+	if r.config.MaxAttempts > 0 && event.Attempts+1 >= r.config.MaxAttempts {
+		log.Error("outbox event dead-lettered")
+		return claimed.MarkOutboxEventDeadLettered(event.Id, cause.Error())
+	}
+	log.Warn("outbox event delivery failed")
+	return claimed.MarkOutboxEventFailed(event.Id, cause.Error())
+}
@@ -0,0 +1,147 @@
+package outbox_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mock "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/outbox"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+// recordingSink remembers the delivered event ids and fails the ids listed in failures
+type recordingSink struct {
+	delivered []int
+	failures  map[int]bool
+}
+
+func (s *recordingSink) Publish(_ context.Context, event models.OutboxEvent) error {
+	if s.failures[event.Id] {
+		return errors.New("sink unavailable")
+	}
+	s.delivered = append(s.delivered, event.Id)
+	return nil
+}
+
+func TestDrainKeepsOrderPerAccount(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With(gomock.Any()).Return(mockLogger).AnyTimes()
+	mockLogger.EXPECT().Warn("outbox event delivery failed").AnyTimes()
+
+	events := []models.OutboxEvent{
+		{Id: 1, AggregateID: 10},
+		{Id: 2, AggregateID: 20},
+		{Id: 3, AggregateID: 10},
+		{Id: 4, AggregateID: 20},
+	}
+	expectClaim(mockOutboxRepo, 50, events)
+	mockOutboxRepo.EXPECT().MarkOutboxEventPublished(1).Return(nil)
+	mockOutboxRepo.EXPECT().MarkOutboxEventFailed(2, "sink unavailable").Return(nil)
+	mockOutboxRepo.EXPECT().MarkOutboxEventPublished(3).Return(nil)
+
+	sink := &recordingSink{failures: map[int]bool{2: true}}
+	relay := outbox.NewRelay(mockOutboxRepo, sink, outbox.RelayConfig{BatchSize: 50, MaxAttempts: 5})
+	published, err := relay.Drain(context.Background())
+	assert.Equal(t, err, nil)
+	assert.Equal(t, published, 2)
+	// event 4 follows the failed event 2 of the same account and must wait for the next pass
+	assert.Equal(t, sink.delivered, []int{1, 3})
+}
+
+func TestDrainDeadLettersAfterMaxAttempts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With(gomock.Any()).Return(mockLogger).AnyTimes()
+	mockLogger.EXPECT().Error("outbox event dead-lettered")
+
+	events := []models.OutboxEvent{
+		{Id: 1, AggregateID: 10, Attempts: 4},
+		{Id: 2, AggregateID: 10},
+	}
+	expectClaim(mockOutboxRepo, 50, events)
+	mockOutboxRepo.EXPECT().MarkOutboxEventDeadLettered(1, "sink unavailable").Return(nil)
+	mockOutboxRepo.EXPECT().MarkOutboxEventPublished(2).Return(nil)
+
+	sink := &recordingSink{failures: map[int]bool{1: true}}
+	relay := outbox.NewRelay(mockOutboxRepo, sink, outbox.RelayConfig{BatchSize: 50, MaxAttempts: 5})
+	published, err := relay.Drain(context.Background())
+	assert.Equal(t, err, nil)
+	assert.Equal(t, published, 1)
+	// a dead letter no longer holds back the events of its account
+	assert.Equal(t, sink.delivered, []int{2})
+}
+
+// expectClaim hands the events to the relay as if they were locked in a transaction of the repository
+func expectClaim(mockOutboxRepo *mock.MockOutboxRepository, limit int, events []models.OutboxEvent) {
+	mockOutboxRepo.EXPECT().ClaimPendingOutboxEvents(limit, gomock.Any()).
+		DoAndReturn(func(_ int, process func(repository.OutboxRepository, []models.OutboxEvent) error) error {
+			return process(mockOutboxRepo, events)
+		})
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := outbox.NewWriterSink(&buf)
+	event, _ := outbox.NewEvent(outbox.AccountDeleted, 7, outbox.AccountDeletedPayload{AccountID: 7})
+	event.Id = 42
+	err := sink.Publish(context.Background(), *event)
+	assert.Equal(t, err, nil)
+
+	var line map[string]interface{}
+	err = json.Unmarshal(buf.Bytes(), &line)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, line["type"], outbox.AccountDeleted)
+	assert.Equal(t, line["version"], 1.0)
+	assert.Equal(t, line["data"], map[string]interface{}{"account_id": 7.0})
+}
+
+func TestHttpSink(t *testing.T) {
+	var received []byte
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Header.Get("Idempotency-Key"), "outbox-5")
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := outbox.NewHttpSink(server.URL, server.Client())
+	event := models.OutboxEvent{Id: 5, EventType: outbox.TransferCompleted, Payload: []byte(`{"transfer_id":1}`)}
+	err := sink.Publish(context.Background(), event)
+	assert.Equal(t, err, nil)
+	assert.NotEqual(t, len(received), 0)
+
+	status = http.StatusServiceUnavailable
+	err = sink.Publish(context.Background(), event)
+	assert.NotEqual(t, err, nil)
+}
+
+func TestEverySchemaIsPresent(t *testing.T) {
+	for eventType, version := range outbox.EventTypes() {
+		schema, err := outbox.Schema(eventType, version)
+		if err != nil {
+			t.Errorf("missing schema for %s v%d: %v", eventType, version, err)
+			continue
+		}
+		var doc map[string]interface{}
+		if err = json.Unmarshal(schema, &doc); err != nil {
+			t.Errorf("invalid schema for %s v%d: %v", eventType, version, err)
+		}
+		assert.Equal(t, doc["title"], eventType)
+	}
+}
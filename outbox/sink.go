@@ -0,0 +1,125 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/models"
+)
+
+// Constants for the sink codes, they need to match sink in outboxConfig
+const (
+	STDOUT string = "stdout"
+	FILE   string = "file"
+	HTTP   string = "http"
+)
+
+// Sink receives the events drained from the outbox. Delivery is at-least-once,
+// so a sink may see the same event id more than once and has to be idempotent.
+type Sink interface {
+	Publish(ctx context.Context, event models.OutboxEvent) error
+}
+
+// SinkConfig holds the settings of the sink implementations
+type SinkConfig struct {
+	Code     string
+	FilePath string
+	HttpUrl  string
+	Timeout  time.Duration
+}
+
+// NewSink builds the sink selected by the config code
+func NewSink(sc SinkConfig) (Sink, error) {
+	switch sc.Code {
+	case STDOUT, "":
+		return NewWriterSink(os.Stdout), nil
+	case FILE:
+		file, err := os.OpenFile(sc.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		return NewWriterSink(file), nil
+	case HTTP:
+		return NewHttpSink(sc.HttpUrl, &http.Client{Timeout: sc.Timeout}), nil
+	}
+	return nil, fmt.Errorf("unknown outbox sink %q", sc.Code)
+}
+
+// writerSink writes every event as one JSON line, it backs the stdout and file sinks
+type writerSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+func (s *writerSink) Publish(_ context.Context, event models.OutboxEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// httpSink POSTs every event as JSON, any non 2xx response is a failed delivery
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewHttpSink(url string, client *http.Client) Sink {
+	return &httpSink{url: url, client: client}
+}
+
+func (s *httpSink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", fmt.Sprintf("outbox-%d", event.Id))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("sink responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// multiSink hands every event to each sink in turn, a failure of any sink fails the event
+// so it is retried for all of them, the sinks have to be idempotent anyway
+type multiSink struct {
+	sinks []Sink
+}
+
+func NewMultiSink(sinks ...Sink) Sink {
+	return &multiSink{sinks: sinks}
+}
+
+func (s *multiSink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	for _, sink := range s.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Constants for the store codes, they need to match store in rateLimitConfig
+const (
+	MEMORY string = "memory"
+)
+
+// Limit is a token bucket: it holds up to Burst requests and refills Requests every Per
+type Limit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// Valid returns an error unless the bucket refills and holds at least one request
+func (l Limit) Valid() error {
+	if l.Requests <= 0 || l.Per <= 0 || l.Burst <= 0 {
+		return fmt.Errorf("rate limit of %d requests per %s with a burst of %d must be positive", l.Requests, l.Per, l.Burst)
+	}
+	return nil
+}
+
+// interval is the time the bucket takes to refill one request
+func (l Limit) interval() time.Duration {
+	return l.Per / time.Duration(l.Requests)
+}
+
+// Result is the state of a bucket after a request was taken from it
+type Result struct {
+	Allowed bool
+	// Limit is the size of the bucket
+	Limit int
+	// Remaining is the number of requests left in the bucket
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, zero when this one was
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets of the clients. The memory store limits each instance of the service on its own,
+// a shared store can implement Take atomically to limit the clients across instances.
+type Store interface {
+	// Take takes one request from the bucket of key, creating a full bucket for new keys
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// NewStore builds the store selected by the config code
+func NewStore(code string) (Store, error) {
+	switch code {
+	case MEMORY, "":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", code)
+	}
+}
+
+// bucket is the state of a token bucket: the bucket is empty until emptyUntil and refills from then on,
+// which needs a single timestamp per key
+type bucket struct {
+	emptyUntil time.Time
+}
+
+// MemoryStore keeps the buckets in a map, the full ones are swept once a minute
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}}
+}
+
+// sweepInterval is how often the memory store forgets the buckets that refilled
+const sweepInterval = time.Minute
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, b := range s.buckets {
+			if !b.emptyUntil.After(now) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{}
+		s.buckets[key] = b
+	}
+	interval := limit.interval()
+	capacity := time.Duration(limit.Burst) * interval
+	// a bucket that refilled longer ago than its capacity is full
+	emptyUntil := b.emptyUntil
+	if emptyUntil.Before(now.Add(-capacity)) {
+		emptyUntil = now.Add(-capacity)
+	}
+	taken := emptyUntil.Add(interval)
+	if taken.After(now) {
+		return Result{
+			Allowed:    false,
+			Limit:      limit.Burst,
+			Remaining:  0,
+			Reset:      emptyUntil.Sub(now) + capacity,
+			RetryAfter: taken.Sub(now),
+		}, nil
+	}
+	b.emptyUntil = taken
+	return Result{
+		Allowed:   true,
+		Limit:     limit.Burst,
+		Remaining: int(math.Floor(float64(now.Sub(taken)) / float64(interval))),
+		Reset:     taken.Sub(now) + capacity,
+	}, nil
+}
@@ -0,0 +1,53 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/ratelimit"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	limit := ratelimit.Limit{Requests: 2, Per: time.Second, Burst: 3}
+	now := time.Now()
+
+	//a new client starts with a full bucket
+	for remaining := 2; remaining >= 0; remaining-- {
+		result, err := store.Take(context.Background(), "alice", limit, now)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, result.Allowed, true)
+		assert.Equal(t, result.Limit, 3)
+		assert.Equal(t, result.Remaining, remaining)
+	}
+	result, _ := store.Take(context.Background(), "alice", limit, now)
+	assert.Equal(t, result.Allowed, false)
+	assert.Equal(t, result.RetryAfter, 500*time.Millisecond)
+	assert.Equal(t, result.Reset, 1500*time.Millisecond)
+
+	//other clients have their own bucket
+	result, _ = store.Take(context.Background(), "bob", limit, now)
+	assert.Equal(t, result.Allowed, true)
+
+	//the bucket refills one request every interval
+	result, _ = store.Take(context.Background(), "alice", limit, now.Add(500*time.Millisecond))
+	assert.Equal(t, result.Allowed, true)
+	assert.Equal(t, result.Remaining, 0)
+	result, _ = store.Take(context.Background(), "alice", limit, now.Add(500*time.Millisecond))
+	assert.Equal(t, result.Allowed, false)
+
+	//and holds no more than the burst
+	result, _ = store.Take(context.Background(), "alice", limit, now.Add(time.Hour))
+	assert.Equal(t, result.Allowed, true)
+	assert.Equal(t, result.Remaining, 2)
+}
+
+func TestLimitValid(t *testing.T) {
+	assert.Equal(t, ratelimit.Limit{Requests: 1, Per: time.Second, Burst: 1}.Valid(), nil)
+	assert.NotEqual(t, ratelimit.Limit{Requests: 1, Per: time.Second}.Valid(), nil)
+	assert.NotEqual(t, ratelimit.Limit{Per: time.Second, Burst: 1}.Valid(), nil)
+	_, err := ratelimit.NewStore("redis")
+	assert.NotEqual(t, err, nil)
+}
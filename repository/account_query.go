@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// AccountQuery describes which accounts are listed and in which order
+type AccountQuery struct {
+	Owner       string
+	Currency    string
+	Status      string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// SortColumn has to be validated by the caller, it is written into the query as is
+	SortColumn string
+	SortDesc   bool
+	// After restricts the result to the rows that follow the cursor in sort order
+	After  *Cursor
+	Offset int
+	Limit  int
+}
+
+// Cursor is the position of the last row of a page in keyset pagination.
+// Sort records the sort option the cursor was issued for so it cannot be replayed against another order.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v,omitempty"`
+	Id    int    `json:"id"`
+}
+
+// NewAccountCursor builds the cursor pointing after the given account for the sort column
+func NewAccountCursor(account models.Account, sort string, column string) Cursor {
+	cursor := Cursor{Sort: sort, Id: account.Id}
+	switch column {
+	case "created_at":
+		cursor.Value = account.CreatedAt.Format(time.RFC3339Nano)
+	case "owner":
+		cursor.Value = account.Owner
+	case "balance":
+		cursor.Value = strconv.FormatFloat(account.Balance, 'f', -1, 64)
+	}
+	return cursor
+}
+
+// Encode returns the opaque representation handed out to clients
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor previously produced by Encode
+func DecodeCursor(encoded string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err = json.Unmarshal(raw, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// value converts the cursor value back to the type of the sort column
+func (c Cursor) value(column string) (interface{}, error) {
+	switch column {
+	case "created_at":
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return t, nil
+	case "balance":
+		f, err := strconv.ParseFloat(c.Value, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return f, nil
+	}
+	return c.Value, nil
+}
+
+// filterAccounts applies the filters of the query without ordering or paging
+func filterAccounts(db *gorm.DB, query AccountQuery) *gorm.DB {
+	db = db.Model(&models.Account{})
+	if query.Owner != "" {
+		db = db.Where("owner = ?", query.Owner)
+	}
+	if query.Currency != "" {
+		db = db.Where("currency = ?", query.Currency)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if !query.CreatedFrom.IsZero() {
+		db = db.Where("created_at >= ?", query.CreatedFrom)
+	}
+	if !query.CreatedTo.IsZero() {
+		db = db.Where("created_at < ?", query.CreatedTo)
+	}
+	return db
+}
+
+// pageAccounts applies ordering, the keyset condition and the limit/offset of the query.
+// The id is always the last sort key so that the order is total and cursors are stable.
+// GORM wraps conditions containing OR in parentheses when combining them with the filters.
+func pageAccounts(db *gorm.DB, query AccountQuery) (*gorm.DB, error) {
+	direction, operator := "ASC", ">"
+	if query.SortDesc {
+		direction, operator = "DESC", "<"
+	}
+	column := query.SortColumn
+	if column == "" {
+		column = "id"
+	}
+	if query.After != nil {
+		if column == "id" {
+			db = db.Where(fmt.Sprintf("id %s ?", operator), query.After.Id)
+		} else {
+			value, err := query.After.value(column)
+			if err != nil {
+				return nil, err
+			}
+			db = db.Where(fmt.Sprintf("%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?)", column, operator),
+				value, value, query.After.Id)
+		}
+	}
+	if column != "id" {
+		db = db.Order(column + " " + direction)
+	}
+	db = db.Order("id " + direction)
+	return db.Limit(query.Limit).Offset(query.Offset), nil
+}
@@ -1,26 +1,34 @@
 package repository
 
 import (
+	"context"
+	"errors"
+
 	"github.com/rahul-024/fund-transfer-poc/logger"
 	"github.com/rahul-024/fund-transfer-poc/models"
 	"gorm.io/gorm"
 )
 
+// ErrInsufficientBalance is returned by DecrementBalance when the account does not exist or its balance does not
+// cover the amount, the balance is left unchanged
+var ErrInsufficientBalance = errors.New("balance does not cover the amount")
+
 type AccountRepositoryImpl struct {
 	DB *gorm.DB
 }
 
 type AccountRepository interface {
-	SaveAccount(models.Account) (models.Account, error)
-	GetAll(pageId int, pageSize int) ([]models.Account, error)
-	GetAccountById(id int) (models.Account, error)
-	DeleteAccountById(id int) error
-	UpdateAccountById(models.Account, models.Account) (models.Account, error)
-	SaveTransfer(*models.Transfer) (models.Transfer, error)
-	SaveEntry(*models.Entry) error
-	IncrementBalance(int, float64) error
-	DecrementBalance(int, float64) error
-	WithTrx(*gorm.DB) AccountRepositoryImpl
+	SaveAccount(context.Context, models.Account) (models.Account, error)
+	GetAll(context.Context, AccountQuery) ([]models.Account, error)
+	CountAll(context.Context, AccountQuery) (int64, error)
+	GetAccountById(ctx context.Context, id int) (models.Account, error)
+	GetAccountsByIds(ctx context.Context, ids []int) ([]models.Account, error)
+	DeleteAccountById(ctx context.Context, id int) error
+	UpdateAccountById(context.Context, models.Account, models.Account) (models.Account, error)
+	PatchAccountById(context.Context, models.Account, map[string]interface{}) (models.Account, error)
+	IncrementBalance(context.Context, int, float64) error
+	DecrementBalance(context.Context, int, float64) error
+	WithTrx(*gorm.DB) AccountRepository
 }
 
 func NewAccountRepository(db *gorm.DB) AccountRepository {
@@ -29,65 +37,88 @@ func NewAccountRepository(db *gorm.DB) AccountRepository {
 	}
 }
 
-func (a AccountRepositoryImpl) SaveAccount(account models.Account) (models.Account, error) {
-	logger.Log.Info("In func() SaveAccount :: REPO LAYER")
-	err := a.DB.Create(&account).Error
+func (a AccountRepositoryImpl) SaveAccount(ctx context.Context, account models.Account) (models.Account, error) {
+	logger.FromContext(ctx).Info("In func() SaveAccount :: REPO LAYER")
+	err := a.DB.WithContext(ctx).Create(&account).Error
 	return account, err
 }
 
-func (a AccountRepositoryImpl) GetAll(pageId int, pageSize int) (accounts []models.Account, err error) {
-	logger.Log.Info("In func() GetAll :: REPO LAYER")
-	err = a.DB.Limit(pageSize).Offset(pageId).Find(&accounts).Error
+func (a AccountRepositoryImpl) GetAll(ctx context.Context, query AccountQuery) (accounts []models.Account, err error) {
+	logger.FromContext(ctx).Info("In func() GetAll :: REPO LAYER")
+	db, err := pageAccounts(filterAccounts(a.DB.WithContext(ctx), query), query)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Find(&accounts).Error
 	return accounts, err
 }
 
-func (a AccountRepositoryImpl) GetAccountById(id int) (account models.Account, err error) {
-	logger.Log.Info("In func() GetAccountById :: REPO LAYER")
-	err = a.DB.Where("id=?", id).First(&account).Error
+// CountAll returns the number of accounts matching the filters of the query, ignoring paging
+func (a AccountRepositoryImpl) CountAll(ctx context.Context, query AccountQuery) (total int64, err error) {
+	logger.FromContext(ctx).Info("In func() CountAll :: REPO LAYER")
+	err = filterAccounts(a.DB.WithContext(ctx), query).Count(&total).Error
+	return total, err
+}
+
+func (a AccountRepositoryImpl) GetAccountById(ctx context.Context, id int) (account models.Account, err error) {
+	logger.FromContext(ctx).Info("In func() GetAccountById :: REPO LAYER")
+	err = a.DB.WithContext(ctx).Where("id=?", id).First(&account).Error
 	return account, err
 }
 
-func (a AccountRepositoryImpl) DeleteAccountById(id int) error {
-	logger.Log.Info("In func() DeleteAccountById :: REPO LAYER")
+// GetAccountsByIds loads several accounts in one query, ids without an account are skipped
+func (a AccountRepositoryImpl) GetAccountsByIds(ctx context.Context, ids []int) (accounts []models.Account, err error) {
+	logger.FromContext(ctx).Info("In func() GetAccountsByIds :: REPO LAYER")
+	err = a.DB.WithContext(ctx).Where("id IN ?", ids).Find(&accounts).Error
+	return accounts, err
+}
+
+func (a AccountRepositoryImpl) DeleteAccountById(ctx context.Context, id int) error {
+	logger.FromContext(ctx).Info("In func() DeleteAccountById :: REPO LAYER")
+	db := a.DB.WithContext(ctx)
 	var account models.Account
-	err := a.DB.Where("id=?", id).First(&account).Error
+	err := db.Where("id=?", id).First(&account).Error
 	if err != nil {
 		return err
 	}
-	err = a.DB.Delete(&account).Error
+	err = db.Delete(&account).Error
 	return err
 }
 
-func (a AccountRepositoryImpl) UpdateAccountById(originalAccount models.Account, changedAccount models.Account) (models.Account, error) {
-	logger.Log.Info("In func() UpdateAccountById :: REPO LAYER")
-	err := a.DB.Model(&originalAccount).Updates(&changedAccount).Error
+func (a AccountRepositoryImpl) UpdateAccountById(ctx context.Context, originalAccount models.Account, changedAccount models.Account) (models.Account, error) {
+	logger.FromContext(ctx).Info("In func() UpdateAccountById :: REPO LAYER")
+	err := a.DB.WithContext(ctx).Model(&originalAccount).Updates(&changedAccount).Error
 	return changedAccount, err
 }
 
-func (a AccountRepositoryImpl) SaveTransfer(transfer *models.Transfer) (models.Transfer, error) {
-	logger.Log.Info("In func() SaveTransfer :: REPO LAYER")
-	err := a.DB.Create(&transfer).Error
-	return *transfer, err
-}
-
-func (a AccountRepositoryImpl) SaveEntry(entry *models.Entry) error {
-	logger.Log.Info("In func() SaveEntry :: REPO LAYER")
-	err := a.DB.Create(&entry).Error
-	return err
+// PatchAccountById updates only the given columns, so zero values such as empty strings are written as well
+func (a AccountRepositoryImpl) PatchAccountById(ctx context.Context, account models.Account, changes map[string]interface{}) (models.Account, error) {
+	logger.FromContext(ctx).Info("In func() PatchAccountById :: REPO LAYER")
+	err := a.DB.WithContext(ctx).Model(&account).Updates(changes).Error
+	return account, err
 }
 
-func (a AccountRepositoryImpl) IncrementBalance(receiver int, amount float64) error {
-	logger.Log.Info("In func() IncrementBalance :: REPO LAYER")
-	return a.DB.Model(&models.Account{}).Where("id=?", receiver).Update("balance", gorm.Expr("balance + ?", amount)).Error
+func (a AccountRepositoryImpl) IncrementBalance(ctx context.Context, receiver int, amount float64) error {
+	logger.FromContext(ctx).Info("In func() IncrementBalance :: REPO LAYER")
+	return a.DB.WithContext(ctx).Model(&models.Account{}).Where("id=?", receiver).Update("balance", gorm.Expr("balance + ?", amount)).Error
 }
 
-func (a AccountRepositoryImpl) DecrementBalance(giver int, amount float64) error {
-	logger.Log.Info("In func() DecrementBalance :: REPO LAYER")
-	//return errors.New("something")
-	return a.DB.Model(&models.Account{}).Where("id=?", giver).Update("balance", gorm.Expr("balance - ?", amount)).Error
+// DecrementBalance checks the balance in the update itself, so that concurrent debits cannot overdraw an account
+// whatever the isolation level of the transaction
+func (a AccountRepositoryImpl) DecrementBalance(ctx context.Context, giver int, amount float64) error {
+	logger.FromContext(ctx).Info("In func() DecrementBalance :: REPO LAYER")
+	result := a.DB.WithContext(ctx).Model(&models.Account{}).Where("id=? AND balance >= ?", giver, amount).
+		Update("balance", gorm.Expr("balance - ?", amount))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientBalance
+	}
+	return nil
 }
 
-func (a AccountRepositoryImpl) WithTrx(trxHandle *gorm.DB) AccountRepositoryImpl {
+func (a AccountRepositoryImpl) WithTrx(trxHandle *gorm.DB) AccountRepository {
 	logger.Log.Info("In func() WithTrx :: REPO LAYER")
 	if trxHandle == nil {
 		logger.Log.Info("Transaction Database not found")
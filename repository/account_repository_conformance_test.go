@@ -0,0 +1,430 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/config"
+	"github.com/rahul-024/fund-transfer-poc/db/migration"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mockI "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"gopkg.in/go-playground/assert.v1"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// ledger holds the account, transfer and entry repositories under test and the database their transactions are begun on
+type ledger struct {
+	accounts  repository.AccountRepository
+	transfers repository.TransferRepository
+	entries   repository.EntryRepository
+	db        *gorm.DB
+}
+
+func (l ledger) withTrx(tx *gorm.DB) ledger {
+	return ledger{l.accounts.WithTrx(tx), l.transfers.WithTrx(tx), l.entries.WithTrx(tx), l.db}
+}
+
+// ledgerFactory returns empty repositories
+type ledgerFactory func(t *testing.T) ledger
+
+func TestGormAccountRepositoryConformance(t *testing.T) {
+	testAccountRepositoryConformance(t, func(t *testing.T) ledger {
+		db := openSqlite(t)
+		sqlDB, _ := db.DB()
+		m, err := migration.New(config.Datasource{DbType: "sqlite"}, "", sqlDB)
+		if err != nil {
+			t.Fatalf("Failed to create migrate instance: %v", err)
+		}
+		if err = m.Up(); err != nil {
+			t.Fatalf("Failed to migrate: %v", err)
+		}
+		return ledger{repository.NewAccountRepository(db), repository.NewTransferRepository(db),
+			repository.NewEntryRepository(db), db}
+	})
+}
+
+func TestMemoryAccountRepositoryConformance(t *testing.T) {
+	testAccountRepositoryConformance(t, func(t *testing.T) ledger {
+		db := openSqlite(t)
+		store := repository.NewMemoryStore(db)
+		return ledger{repository.NewMemoryAccountRepository(store), repository.NewMemoryTransferRepository(store),
+			repository.NewMemoryEntryRepository(store), db}
+	})
+}
+
+// openSqlite opens a sqlite file private to the test, WAL lets reads outside of a transaction see the last commit
+func openSqlite(t *testing.T) *gorm.DB {
+	dsn := "file:" + filepath.Join(t.TempDir(), "conformance.db") +
+		"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("Failed to open sqlite: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func saveAccounts(t *testing.T, repo repository.AccountRepository, accounts ...models.Account) []models.Account {
+	for i := range accounts {
+		if accounts[i].Status == "" {
+			accounts[i].Status = models.AccountStatusActive
+		}
+		saved, err := repo.SaveAccount(context.Background(), accounts[i])
+		if err != nil {
+			t.Fatalf("Failed to save account: %v", err)
+		}
+		accounts[i] = saved
+	}
+	return accounts
+}
+
+func accountIds(accounts []models.Account) []int {
+	ids := []int{}
+	for _, account := range accounts {
+		ids = append(ids, account.Id)
+	}
+	return ids
+}
+
+func transferIds(transfers []models.Transfer) []int {
+	ids := []int{}
+	for _, transfer := range transfers {
+		ids = append(ids, transfer.Id)
+	}
+	return ids
+}
+
+func entryIds(entries []models.Entry) []int {
+	ids := []int{}
+	for _, entry := range entries {
+		ids = append(ids, entry.Id)
+	}
+	return ids
+}
+
+func testAccountRepositoryConformance(t *testing.T, newLedger ledgerFactory) {
+	ctx := context.Background()
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+
+	t.Run("SaveAndGetAccount", func(t *testing.T) {
+		l := newLedger(t)
+		saved, err := l.accounts.SaveAccount(ctx, models.Account{Owner: "alice", Currency: "USD", Balance: 10, Status: models.AccountStatusActive})
+		assert.Equal(t, err, nil)
+		assert.NotEqual(t, saved.Id, 0)
+		assert.Equal(t, saved.CreatedAt.IsZero(), false)
+
+		account, err := l.accounts.GetAccountById(ctx, saved.Id)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, account.Id, saved.Id)
+		assert.Equal(t, account.Owner, "alice")
+		assert.Equal(t, account.Currency, "USD")
+		assert.Equal(t, account.Balance, float64(10))
+		assert.Equal(t, account.Status, models.AccountStatusActive)
+
+		_, err = l.accounts.GetAccountById(ctx, saved.Id+100)
+		assert.Equal(t, errors.Is(err, gorm.ErrRecordNotFound), true)
+	})
+
+	t.Run("GetAccountsByIds", func(t *testing.T) {
+		l := newLedger(t)
+		accounts := saveAccounts(t, l.accounts, models.Account{Owner: "alice", Currency: "USD"},
+			models.Account{Owner: "bob", Currency: "USD"}, models.Account{Owner: "carol", Currency: "USD"})
+		found, err := l.accounts.GetAccountsByIds(ctx, []int{accounts[2].Id, accounts[0].Id, accounts[2].Id + 100})
+		assert.Equal(t, err, nil)
+		ids := accountIds(found)
+		sort.Ints(ids)
+		assert.Equal(t, ids, []int{accounts[0].Id, accounts[2].Id})
+	})
+
+	t.Run("GetAllFiltersSortsAndPages", func(t *testing.T) {
+		l := newLedger(t)
+		created := time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)
+		accounts := saveAccounts(t, l.accounts,
+			models.Account{Owner: "alice", Currency: "USD", Balance: 30, CreatedAt: created},
+			models.Account{Owner: "bob", Currency: "EUR", Balance: 10, CreatedAt: created.Add(time.Hour)},
+			models.Account{Owner: "carol", Currency: "USD", Balance: 20, CreatedAt: created.Add(2 * time.Hour)},
+			models.Account{Owner: "dave", Currency: "USD", Balance: 20, CreatedAt: created.Add(3 * time.Hour), Status: models.AccountStatusClosed})
+		alice, bob, carol, dave := accounts[0], accounts[1], accounts[2], accounts[3]
+
+		cases := []struct {
+			name  string
+			query repository.AccountQuery
+			want  []int
+		}{
+			{"owner", repository.AccountQuery{Owner: "alice", Limit: 10}, []int{alice.Id}},
+			{"currency and status", repository.AccountQuery{Currency: "USD", Status: models.AccountStatusActive, Limit: 10},
+				[]int{alice.Id, carol.Id}},
+			{"created range", repository.AccountQuery{CreatedFrom: bob.CreatedAt, CreatedTo: dave.CreatedAt, Limit: 10},
+				[]int{bob.Id, carol.Id}},
+			{"balance descending", repository.AccountQuery{SortColumn: "balance", SortDesc: true, Limit: 10},
+				[]int{alice.Id, dave.Id, carol.Id, bob.Id}},
+			{"created descending", repository.AccountQuery{SortColumn: "created_at", SortDesc: true, Limit: 10},
+				[]int{dave.Id, carol.Id, bob.Id, alice.Id}},
+			{"after balance cursor", repository.AccountQuery{SortColumn: "balance", SortDesc: true, Limit: 10,
+				After: cursor(dave, "-balance", "balance")}, []int{carol.Id, bob.Id}},
+			{"after created cursor", repository.AccountQuery{SortColumn: "created_at", Limit: 10,
+				After: cursor(bob, "created_at", "created_at")}, []int{carol.Id, dave.Id}},
+			{"after id cursor", repository.AccountQuery{Limit: 2, After: cursor(alice, "id", "id")}, []int{bob.Id, carol.Id}},
+			{"offset", repository.AccountQuery{SortColumn: "owner", Offset: 1, Limit: 2}, []int{bob.Id, carol.Id}},
+		}
+		for _, c := range cases {
+			found, err := l.accounts.GetAll(ctx, c.query)
+			assert.Equal(t, err, nil)
+			if got := accountIds(found); !equalIds(got, c.want) {
+				t.Errorf("%s: expected accounts %v, got %v", c.name, c.want, got)
+			}
+		}
+
+		total, err := l.accounts.CountAll(ctx, repository.AccountQuery{Currency: "USD", Offset: 1, Limit: 1})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, total, int64(3))
+
+		_, err = l.accounts.GetAll(ctx, repository.AccountQuery{SortColumn: "balance", Limit: 10,
+			After: &repository.Cursor{Sort: "balance", Value: "not a number"}})
+		assert.Equal(t, err, repository.ErrInvalidCursor)
+	})
+
+	t.Run("UpdateAndPatchAccount", func(t *testing.T) {
+		l := newLedger(t)
+		alice := saveAccounts(t, l.accounts, models.Account{Owner: "alice", Currency: "USD", Balance: 5})[0]
+
+		_, err := l.accounts.UpdateAccountById(ctx, alice, models.Account{Owner: "alicia"})
+		assert.Equal(t, err, nil)
+		account, _ := l.accounts.GetAccountById(ctx, alice.Id)
+		assert.Equal(t, account.Owner, "alicia")
+		assert.Equal(t, account.Currency, "USD")
+		assert.Equal(t, account.Balance, float64(5))
+
+		patched, err := l.accounts.PatchAccountById(ctx, account, map[string]interface{}{"status": models.AccountStatusClosed})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, patched.Status, models.AccountStatusClosed)
+		assert.Equal(t, patched.Owner, "alicia")
+		account, _ = l.accounts.GetAccountById(ctx, alice.Id)
+		assert.Equal(t, account.Status, models.AccountStatusClosed)
+		assert.Equal(t, account.Owner, "alicia")
+
+		_, err = l.accounts.PatchAccountById(ctx, account, map[string]interface{}{"transfer_limit": float64(50)})
+		assert.Equal(t, err, nil)
+		account, _ = l.accounts.GetAccountById(ctx, alice.Id)
+		assert.Equal(t, account.TransferLimit, float64(50))
+	})
+
+	t.Run("DeleteAccount", func(t *testing.T) {
+		l := newLedger(t)
+		alice := saveAccounts(t, l.accounts, models.Account{Owner: "alice", Currency: "USD"})[0]
+
+		assert.Equal(t, l.accounts.DeleteAccountById(ctx, alice.Id), nil)
+		_, err := l.accounts.GetAccountById(ctx, alice.Id)
+		assert.Equal(t, errors.Is(err, gorm.ErrRecordNotFound), true)
+		assert.Equal(t, errors.Is(l.accounts.DeleteAccountById(ctx, alice.Id), gorm.ErrRecordNotFound), true)
+	})
+
+	t.Run("Transfers", func(t *testing.T) {
+		l := newLedger(t)
+		accounts := saveAccounts(t, l.accounts, models.Account{Owner: "alice", Currency: "USD"},
+			models.Account{Owner: "bob", Currency: "USD"}, models.Account{Owner: "carol", Currency: "USD"})
+		a1, a2, a3 := accounts[0].Id, accounts[1].Id, accounts[2].Id
+		var transfers []models.Transfer
+		for _, pair := range [][2]int{{a1, a2}, {a2, a3}, {a1, a3}, {a3, a1}} {
+			transfer, err := l.transfers.SaveTransfer(ctx, &models.Transfer{FromAccountID: pair[0], ToAccountID: pair[1], Amount: 5})
+			assert.Equal(t, err, nil)
+			assert.NotEqual(t, transfer.Id, 0)
+			transfers = append(transfers, transfer)
+		}
+		t1, t2, t3, t4 := transfers[0].Id, transfers[1].Id, transfers[2].Id, transfers[3].Id
+
+		transfer, err := l.transfers.GetTransferById(ctx, t2)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, transfer.FromAccountID, a2)
+		assert.Equal(t, transfer.ToAccountID, a3)
+		assert.Equal(t, transfer.Amount, float64(5))
+		_, err = l.transfers.GetTransferById(ctx, t4+100)
+		assert.Equal(t, errors.Is(err, gorm.ErrRecordNotFound), true)
+
+		recent, err := l.transfers.GetRecentTransfers(ctx, []int{a1}, 2, 0)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, len(recent), 1)
+		assert.Equal(t, transferIds(recent[a1]), []int{t4, t3})
+
+		recent, err = l.transfers.GetRecentTransfers(ctx, []int{a1, a2}, 10, t4)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, len(recent), 2)
+		assert.Equal(t, transferIds(recent[a1]), []int{t3, t1})
+		assert.Equal(t, transferIds(recent[a2]), []int{t2, t1})
+	})
+
+	t.Run("Entries", func(t *testing.T) {
+		l := newLedger(t)
+		accounts := saveAccounts(t, l.accounts, models.Account{Owner: "alice", Currency: "USD"},
+			models.Account{Owner: "bob", Currency: "USD"}, models.Account{Owner: "carol", Currency: "USD"})
+		a1, a2, a3 := accounts[0].Id, accounts[1].Id, accounts[2].Id
+		var entries []models.Entry
+		for _, e := range []models.Entry{{AccountID: a1, Amount: 10}, {AccountID: a2, Amount: -5}, {AccountID: a1, Amount: -3}} {
+			entry := e
+			assert.Equal(t, l.entries.SaveEntry(ctx, &entry), nil)
+			assert.NotEqual(t, entry.Id, 0)
+			entries = append(entries, entry)
+		}
+		e1, e2, e3 := entries[0].Id, entries[1].Id, entries[2].Id
+
+		found, err := l.entries.GetEntriesByAccountId(ctx, a1)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, entryIds(found), []int{e1, e3})
+		found, err = l.entries.GetEntriesByAccountId(ctx, a3)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, len(found), 0)
+
+		recent, err := l.entries.GetRecentEntries(ctx, []int{a1, a2, a3}, 1, 0)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, len(recent), 2)
+		assert.Equal(t, entryIds(recent[a1]), []int{e3})
+		assert.Equal(t, entryIds(recent[a2]), []int{e2})
+
+		recent, err = l.entries.GetRecentEntries(ctx, []int{a1}, 10, e3)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, entryIds(recent[a1]), []int{e1})
+	})
+
+	t.Run("BalancesAndMismatches", func(t *testing.T) {
+		l := newLedger(t)
+		accounts := saveAccounts(t, l.accounts, models.Account{Owner: "alice", Currency: "USD"}, models.Account{Owner: "bob", Currency: "USD"})
+		a1, a2 := accounts[0].Id, accounts[1].Id
+
+		assert.Equal(t, l.accounts.IncrementBalance(ctx, a1, 10), nil)
+		assert.Equal(t, l.accounts.IncrementBalance(ctx, a2, 6), nil)
+		assert.Equal(t, l.accounts.DecrementBalance(ctx, a2, 2), nil)
+		assert.Equal(t, l.accounts.IncrementBalance(ctx, a2+100, 1), nil)
+		assert.Equal(t, l.entries.SaveEntry(ctx, &models.Entry{AccountID: a1, Amount: 10}), nil)
+
+		//debits never overdraw, nor touch unknown accounts
+		assert.Equal(t, errors.Is(l.accounts.DecrementBalance(ctx, a2, 5), repository.ErrInsufficientBalance), true)
+		assert.Equal(t, errors.Is(l.accounts.DecrementBalance(ctx, a2+100, 1), repository.ErrInsufficientBalance), true)
+
+		account, _ := l.accounts.GetAccountById(ctx, a1)
+		assert.Equal(t, account.Balance, float64(10))
+		account, _ = l.accounts.GetAccountById(ctx, a2)
+		assert.Equal(t, account.Balance, float64(4))
+
+		mismatches, err := l.entries.GetBalanceMismatches(ctx)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, mismatches, []models.BalanceMismatch{{AccountID: a2, Balance: 4, LedgerBalance: 0}})
+	})
+
+	t.Run("ConcurrentDebits", func(t *testing.T) {
+		l := newLedger(t)
+		alice := saveAccounts(t, l.accounts, models.Account{Owner: "alice", Currency: "USD", Balance: 100})[0]
+
+		//two transactions debit the whole balance, the second one while the first has not committed yet:
+		//the database makes it wait for the first commit, the memory store refuses its commit
+		first, second := l.db.Begin(), l.db.Begin()
+		assert.Equal(t, l.accounts.WithTrx(first).DecrementBalance(ctx, alice.Id, 100), nil)
+		debited := make(chan error)
+		go func() {
+			debited <- l.accounts.WithTrx(second).DecrementBalance(ctx, alice.Id, 100)
+		}()
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, first.Commit().Error, nil)
+		err := <-debited
+		if err == nil {
+			err = second.Commit().Error
+		} else {
+			second.Rollback()
+		}
+		assert.Equal(t, errors.Is(err, repository.ErrInsufficientBalance), true)
+
+		account, _ := l.accounts.GetAccountById(ctx, alice.Id)
+		assert.Equal(t, account.Balance, float64(0))
+	})
+
+	t.Run("WithTrxCommit", func(t *testing.T) {
+		l := newLedger(t)
+		tx := l.db.Begin()
+		saved, err := l.accounts.WithTrx(tx).SaveAccount(ctx, models.Account{Owner: "alice", Currency: "USD", Status: models.AccountStatusActive})
+		assert.Equal(t, err, nil)
+		assert.Equal(t, l.accounts.WithTrx(tx).IncrementBalance(ctx, saved.Id, 5), nil)
+
+		account, err := l.accounts.WithTrx(tx).GetAccountById(ctx, saved.Id)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, account.Balance, float64(5))
+		_, err = l.accounts.GetAccountById(ctx, saved.Id)
+		assert.Equal(t, errors.Is(err, gorm.ErrRecordNotFound), true)
+
+		assert.Equal(t, tx.Commit().Error, nil)
+		account, err = l.accounts.GetAccountById(ctx, saved.Id)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, account.Balance, float64(5))
+	})
+
+	t.Run("WithTrxRollback", func(t *testing.T) {
+		l := newLedger(t)
+		alice := saveAccounts(t, l.accounts, models.Account{Owner: "alice", Currency: "USD", Balance: 10})[0]
+
+		tx := l.db.Begin()
+		txL := l.withTrx(tx)
+		assert.Equal(t, txL.accounts.DecrementBalance(ctx, alice.Id, 4), nil)
+		assert.Equal(t, txL.entries.SaveEntry(ctx, &models.Entry{AccountID: alice.Id, Amount: -4}), nil)
+		assert.Equal(t, txL.accounts.DeleteAccountById(ctx, alice.Id), nil)
+		_, err := txL.accounts.GetAccountById(ctx, alice.Id)
+		assert.Equal(t, errors.Is(err, gorm.ErrRecordNotFound), true)
+
+		assert.Equal(t, tx.Rollback().Error, nil)
+		account, err := l.accounts.GetAccountById(ctx, alice.Id)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, account.Balance, float64(10))
+		entries, _ := l.entries.GetEntriesByAccountId(ctx, alice.Id)
+		assert.Equal(t, len(entries), 0)
+	})
+
+	t.Run("CancelledContext", func(t *testing.T) {
+		l := newLedger(t)
+		alice := saveAccounts(t, l.accounts, models.Account{Owner: "alice", Currency: "USD"})[0]
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := l.accounts.GetAccountById(cancelled, alice.Id)
+		assert.Equal(t, errors.Is(err, context.Canceled), true)
+		err = l.entries.SaveEntry(cancelled, &models.Entry{AccountID: alice.Id, Amount: 1})
+		assert.Equal(t, errors.Is(err, context.Canceled), true)
+		_, err = l.transfers.GetRecentTransfers(cancelled, []int{alice.Id}, 1, 0)
+		assert.Equal(t, errors.Is(err, context.Canceled), true)
+		entries, _ := l.entries.GetEntriesByAccountId(ctx, alice.Id)
+		assert.Equal(t, len(entries), 0)
+	})
+
+	t.Run("WithTrxNil", func(t *testing.T) {
+		l := newLedger(t)
+		saved := saveAccounts(t, l.accounts.WithTrx(nil), models.Account{Owner: "alice", Currency: "USD"})[0]
+		_, err := l.accounts.GetAccountById(ctx, saved.Id)
+		assert.Equal(t, err, nil)
+	})
+}
+
+func cursor(account models.Account, sort string, column string) *repository.Cursor {
+	c := repository.NewAccountCursor(account, sort, column)
+	return &c
+}
+
+func equalIds(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,7 @@
 package repository_test
 
 import (
+	"context"
 	"database/sql"
 	"regexp"
 	"testing"
@@ -37,19 +38,20 @@ func TestSaveAccount(t *testing.T) {
 		Currency:  "USD",
 		Owner:     "John",
 		Balance:   24.0,
+		Status:    "ACTIVE",
 		CreatedAt: time.Now(),
 	}
 	gdb, mock = mockDbConnection()
 	accountRepositoryImpl := repository.NewAccountRepository(gdb)
-	const sqlInsertAccount = `INSERT INTO "accounts" ("currency","owner","balance","created_at") 
-						VALUES ($1,$2,$3,$4) RETURNING "id"`
+	const sqlInsertAccount = `INSERT INTO "accounts" ("currency","owner","balance","status","created_at","transfer_limit") 
+						VALUES ($1,$2,$3,$4,$5,$6) RETURNING "id"`
 	const newId = 1
 	mock.ExpectBegin() // start transaction
 	mock.ExpectQuery(regexp.QuoteMeta(sqlInsertAccount)).
-		WithArgs(account.Currency, account.Owner, account.Balance, account.CreatedAt).
+		WithArgs(account.Currency, account.Owner, account.Balance, account.Status, account.CreatedAt, account.TransferLimit).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newId))
 	mock.ExpectCommit() // commit transaction
-	accountRepositoryImpl.SaveAccount(account)
+	accountRepositoryImpl.SaveAccount(context.Background(), account)
 	err := mock.ExpectationsWereMet()
 	if err != nil {
 		t.Errorf("Failed to meet expectations, got error: %v", err)
@@ -68,15 +70,63 @@ func TestGetAll(t *testing.T) {
 		AddRow(2, "EUR", "Mike", 30, time.Now())
 
 	accountRepositoryImpl := repository.NewAccountRepository(gdb)
-	const sqlSelectFirst5 = `SELECT * FROM "accounts" LIMIT 5 OFFSET 1`
-	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectFirst5)).WillReturnRows(rows)
-	accountRepositoryImpl.GetAll(1, 5)
+	const sqlSelectSecondPage = `SELECT * FROM "accounts" ORDER BY id ASC LIMIT 5 OFFSET 5`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectSecondPage)).WillReturnRows(rows)
+	accountRepositoryImpl.GetAll(context.Background(), repository.AccountQuery{SortColumn: "id", Limit: 5, Offset: 5})
 	err := mock.ExpectationsWereMet()
 	if err != nil {
 		t.Errorf("Failed to meet expectations, got error: %v", err)
 	}
 }
 
+func TestGetAllAfterCursor(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info("In func() GetAll :: REPO LAYER")
+	gdb, mock = mockDbConnection()
+	createdAt := time.Date(2023, time.Month(1), 12, 9, 0, 0, 0, time.UTC)
+	rows := sqlmock.
+		NewRows([]string{"id", "currency", "owner", "balance", "status", "created_at"}).
+		AddRow(3, "USD", "John", 24, "ACTIVE", createdAt)
+
+	accountRepositoryImpl := repository.NewAccountRepository(gdb)
+	cursor := repository.NewAccountCursor(models.Account{Id: 7, CreatedAt: createdAt}, "-created_at", "created_at")
+	const sqlSelectAfterCursor = `SELECT * FROM "accounts" WHERE owner = $1 AND currency = $2 ` +
+		`AND (created_at < $3 OR (created_at = $4 AND id < $5)) ORDER BY created_at DESC,id DESC LIMIT 3`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectAfterCursor)).
+		WithArgs("John", "USD", createdAt, createdAt, 7).WillReturnRows(rows)
+	accounts, err := accountRepositoryImpl.GetAll(context.Background(), repository.AccountQuery{
+		Owner: "John", Currency: "USD", SortColumn: "created_at", SortDesc: true, After: &cursor, Limit: 3,
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(accounts), 1)
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestCountAll(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info("In func() CountAll :: REPO LAYER")
+	gdb, mock = mockDbConnection()
+
+	accountRepositoryImpl := repository.NewAccountRepository(gdb)
+	const sqlCountByStatus = `SELECT count(*) FROM "accounts" WHERE status = $1`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlCountByStatus)).
+		WithArgs("ACTIVE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+	total, err := accountRepositoryImpl.CountAll(context.Background(), repository.AccountQuery{Status: "ACTIVE", Limit: 5, Offset: 5})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, total, int64(12))
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
 func TestGetAccountById(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	mockLogger := mockI.NewMockLogger(mockCtrl)
@@ -91,7 +141,7 @@ func TestGetAccountById(t *testing.T) {
 	const sqlSelectByAccountId = `SELECT * FROM "accounts" WHERE id=$1 ORDER BY "accounts"."id" LIMIT 1`
 	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectByAccountId)).
 		WithArgs(1).WillReturnRows(rows)
-	accountRepositoryImpl.GetAccountById(1)
+	accountRepositoryImpl.GetAccountById(context.Background(), 1)
 	err := mock.ExpectationsWereMet()
 	if err != nil {
 		t.Errorf("Failed to meet expectations, got error: %v", err)
@@ -117,7 +167,7 @@ func TestDeleteAccountById(t *testing.T) {
 	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta(sqlDeleteByAccountId)).WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
-	accountRepositoryImpl.DeleteAccountById(1)
+	accountRepositoryImpl.DeleteAccountById(context.Background(), 1)
 
 	err := mock.ExpectationsWereMet()
 	if err != nil {
@@ -128,7 +178,7 @@ func TestDeleteAccountById(t *testing.T) {
 	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta(sqlDeleteByAccountId)).WithArgs(2)
 	mock.ExpectCommit()
-	err = accountRepositoryImpl.DeleteAccountById(2)
+	err = accountRepositoryImpl.DeleteAccountById(context.Background(), 2)
 	assert.NotEqual(t, err, nil)
 }
 
@@ -160,70 +210,41 @@ func TestUpdateAccountById(t *testing.T) {
 		WithArgs(changedAccount.Currency, changedAccount.Owner, changedAccount.Balance, originalAccount.CreatedAt, 1).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
-	accountRepositoryImpl.UpdateAccountById(originalAccount, changedAccount)
-
-	err := mock.ExpectationsWereMet()
-	if err != nil {
-		t.Errorf("Failed to meet expectations, got error: %v", err)
-	}
-}
-
-func TestSaveTransfer(t *testing.T) {
-	mockCtrl := gomock.NewController(t)
-	mockLogger := mockI.NewMockLogger(mockCtrl)
-	logger.SetLogger(mockLogger)
-	mockLogger.EXPECT().Info("In func() SaveTransfer :: REPO LAYER")
-	gdb, mock = mockDbConnection()
-	accountRepositoryImpl := repository.NewAccountRepository(gdb)
+	accountRepositoryImpl.UpdateAccountById(context.Background(), originalAccount, changedAccount)
 
-	transfer := models.Transfer{
-		FromAccountID: 1,
-		ToAccountID:   2,
-		Amount:        20.0,
-		CreatedAt:     time.Now(),
-	}
-
-	const sqlInsertTransfer = `INSERT INTO "transfers" ("from_account_id","to_account_id","amount","created_at") 
-						VALUES ($1,$2,$3,$4) RETURNING "id"`
-
-	const newId = 1
-	mock.ExpectBegin() // start transaction
-	mock.ExpectQuery(regexp.QuoteMeta(sqlInsertTransfer)).
-		WithArgs(transfer.FromAccountID, transfer.ToAccountID, transfer.Amount, transfer.CreatedAt).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newId))
-	mock.ExpectCommit() // commit transaction
-	accountRepositoryImpl.SaveTransfer(&transfer)
 	err := mock.ExpectationsWereMet()
 	if err != nil {
 		t.Errorf("Failed to meet expectations, got error: %v", err)
 	}
 }
 
-func TestSaveEntry(t *testing.T) {
+func TestPatchAccountById(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	mockLogger := mockI.NewMockLogger(mockCtrl)
 	logger.SetLogger(mockLogger)
-	mockLogger.EXPECT().Info("In func() SaveEntry :: REPO LAYER")
+	mockLogger.EXPECT().Info("In func() PatchAccountById :: REPO LAYER")
 	gdb, mock = mockDbConnection()
 	accountRepositoryImpl := repository.NewAccountRepository(gdb)
 
-	entry := models.Entry{
-		AccountID: 1,
-		Amount:    20.0,
-		CreatedAt: time.Now(),
+	account := models.Account{
+		Id:       1,
+		Currency: "USD",
+		Owner:    "John",
+		Balance:  10.0,
 	}
 
-	const sqlInsertEntry = `INSERT INTO "entries" ("account_id","amount","created_at") 
-						VALUES ($1,$2,$3) RETURNING "id"`
+	const sqlPatchByAccountId = `UPDATE "accounts" SET "owner"=$1 WHERE "id" = $2`
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(sqlPatchByAccountId)).
+		WithArgs("Mike", 1).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	patched, err := accountRepositoryImpl.PatchAccountById(context.Background(), account, map[string]interface{}{"owner": "Mike"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, patched.Owner, "Mike")
+	assert.Equal(t, patched.Balance, 10.0)
 
-	const newId = 1
-	mock.ExpectBegin() // start transaction
-	mock.ExpectQuery(regexp.QuoteMeta(sqlInsertEntry)).
-		WithArgs(entry.AccountID, entry.Amount, entry.CreatedAt).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newId))
-	mock.ExpectCommit() // commit transaction
-	accountRepositoryImpl.SaveEntry(&entry)
-	err := mock.ExpectationsWereMet()
+	err = mock.ExpectationsWereMet()
 	if err != nil {
 		t.Errorf("Failed to meet expectations, got error: %v", err)
 	}
@@ -251,7 +272,7 @@ func TestIncrementBalance(t *testing.T) {
 		WithArgs(14.0, account.Id).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit() // commit transaction
-	accountRepositoryImpl.IncrementBalance(2, 14.0)
+	accountRepositoryImpl.IncrementBalance(context.Background(), 2, 14.0)
 	err := mock.ExpectationsWereMet()
 	if err != nil {
 		t.Errorf("Failed to meet expectations, got error: %v", err)
@@ -274,13 +295,22 @@ func TestDecrementBalance(t *testing.T) {
 		CreatedAt: time.Date(2021, time.Month(2), 21, 1, 10, 30, 0, time.UTC),
 	}
 
-	const sqlIncrementBalByAccountId = `UPDATE "accounts" SET "balance"=balance - $1 WHERE id=$2`
+	const sqlIncrementBalByAccountId = `UPDATE "accounts" SET "balance"=balance - $1 WHERE id=$2 AND balance >= $3`
 	mock.ExpectBegin() // start transaction
 	mock.ExpectExec(regexp.QuoteMeta(sqlIncrementBalByAccountId)).
-		WithArgs(10.0, account.Id).
+		WithArgs(10.0, account.Id, 10.0).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit() // commit transaction
-	accountRepositoryImpl.DecrementBalance(1, 10.0)
+	assert.Equal(t, accountRepositoryImpl.DecrementBalance(context.Background(), 1, 10.0), nil)
+
+	//the balance does not cover the amount, no row is updated
+	mockLogger.EXPECT().Info("In func() DecrementBalance :: REPO LAYER")
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(sqlIncrementBalByAccountId)).
+		WithArgs(30.0, account.Id, 30.0).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	assert.Equal(t, accountRepositoryImpl.DecrementBalance(context.Background(), 1, 30.0), repository.ErrInsufficientBalance)
 	err := mock.ExpectationsWereMet()
 	if err != nil {
 		t.Errorf("Failed to meet expectations, got error: %v", err)
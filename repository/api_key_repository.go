@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+type APIKeyRepository interface {
+	SaveAPIKey(context.Context, *models.APIKey) error
+	GetAPIKeys(context.Context) ([]models.APIKey, error)
+	GetAPIKeyById(ctx context.Context, id int) (models.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int, at time.Time) error
+	TouchAPIKey(ctx context.Context, id int, at time.Time) error
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return APIKeyRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (a APIKeyRepositoryImpl) SaveAPIKey(ctx context.Context, key *models.APIKey) error {
+	logger.FromContext(ctx).Info("In func() SaveAPIKey :: REPO LAYER")
+	return a.DB.WithContext(ctx).Create(key).Error
+}
+
+func (a APIKeyRepositoryImpl) GetAPIKeys(ctx context.Context) (keys []models.APIKey, err error) {
+	logger.FromContext(ctx).Info("In func() GetAPIKeys :: REPO LAYER")
+	err = a.DB.WithContext(ctx).Order("id ASC").Find(&keys).Error
+	return keys, err
+}
+
+func (a APIKeyRepositoryImpl) GetAPIKeyById(ctx context.Context, id int) (key models.APIKey, err error) {
+	logger.FromContext(ctx).Info("In func() GetAPIKeyById :: REPO LAYER")
+	err = a.DB.WithContext(ctx).Where("id=?", id).First(&key).Error
+	return key, err
+}
+
+func (a APIKeyRepositoryImpl) GetAPIKeyByHash(ctx context.Context, hash string) (key models.APIKey, err error) {
+	logger.FromContext(ctx).Info("In func() GetAPIKeyByHash :: REPO LAYER")
+	err = a.DB.WithContext(ctx).Where("key_hash=?", hash).First(&key).Error
+	return key, err
+}
+
+// RevokeAPIKey keeps the time of the first revocation when the key is revoked again
+func (a APIKeyRepositoryImpl) RevokeAPIKey(ctx context.Context, id int, at time.Time) error {
+	logger.FromContext(ctx).Info("In func() RevokeAPIKey :: REPO LAYER")
+	return a.DB.WithContext(ctx).Model(&models.APIKey{}).Where("id=? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+func (a APIKeyRepositoryImpl) TouchAPIKey(ctx context.Context, id int, at time.Time) error {
+	logger.FromContext(ctx).Info("In func() TouchAPIKey :: REPO LAYER")
+	return a.DB.WithContext(ctx).Model(&models.APIKey{}).Where("id=?", id).Update("last_used_at", at).Error
+}
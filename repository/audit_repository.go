@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/audit"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"gorm.io/gorm"
+)
+
+type AuditRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// AuditQuery describes which audit events are listed, newest first
+type AuditQuery struct {
+	Actor      string
+	Operation  string
+	EntityType string
+	EntityID   int
+	RequestID  string
+	From       time.Time
+	To         time.Time
+	Offset     int
+	Limit      int
+}
+
+type AuditRepository interface {
+	SaveAuditEvent(*models.AuditEvent) error
+	GetAuditEvents(context.Context, AuditQuery) ([]models.AuditEvent, error)
+	WithTrx(*gorm.DB) AuditRepository
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return AuditRepositoryImpl{
+		DB: db,
+	}
+}
+
+// SaveAuditEvent appends an event to the audit log. Actor, request id and source ip are taken
+// from the audit metadata of the context the DB handle (usually the request transaction) carries.
+func (a AuditRepositoryImpl) SaveAuditEvent(event *models.AuditEvent) error {
+	logger.FromContext(a.DB.Statement.Context).Info("In func() SaveAuditEvent :: REPO LAYER")
+	meta := audit.FromContext(a.DB.Statement.Context)
+	event.Actor = meta.Actor
+	event.RequestID = meta.RequestID
+	event.SourceIP = meta.SourceIP
+	return a.DB.Create(event).Error
+}
+
+func (a AuditRepositoryImpl) GetAuditEvents(ctx context.Context, query AuditQuery) (events []models.AuditEvent, err error) {
+	logger.FromContext(ctx).Info("In func() GetAuditEvents :: REPO LAYER")
+	db := a.DB.WithContext(ctx).Model(&models.AuditEvent{})
+	if query.Actor != "" {
+		db = db.Where("actor = ?", query.Actor)
+	}
+	if query.Operation != "" {
+		db = db.Where("operation = ?", query.Operation)
+	}
+	if query.EntityType != "" {
+		db = db.Where("entity_type = ?", query.EntityType)
+	}
+	if query.EntityID != 0 {
+		db = db.Where("entity_id = ?", query.EntityID)
+	}
+	if query.RequestID != "" {
+		db = db.Where("request_id = ?", query.RequestID)
+	}
+	if !query.From.IsZero() {
+		db = db.Where("created_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		db = db.Where("created_at < ?", query.To)
+	}
+	err = db.Order("id DESC").Limit(query.Limit).Offset(query.Offset).Find(&events).Error
+	return events, err
+}
+
+func (a AuditRepositoryImpl) WithTrx(trxHandle *gorm.DB) AuditRepository {
+	logger.Log.Info("In func() WithTrx :: REPO LAYER")
+	if trxHandle == nil {
+		logger.Log.Info("Transaction Database not found")
+		return a
+	}
+	a.DB = trxHandle
+	return a
+}
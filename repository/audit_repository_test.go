@@ -0,0 +1,77 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/audit"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mockI "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestSaveAuditEvent(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info("In func() SaveAuditEvent :: REPO LAYER")
+	gdb, mock = mockDbConnection()
+	ctx := audit.NewContext(context.Background(), audit.Meta{Actor: "teller-7", RequestID: "req-1", SourceIP: "10.0.0.1"})
+	auditRepositoryImpl := repository.NewAuditRepository(gdb.WithContext(ctx))
+
+	event := models.AuditEvent{
+		Operation:  models.AuditAccountUpdated,
+		EntityType: models.AuditEntityAccount,
+		EntityID:   1,
+		Before:     []byte(`{"owner":"John"}`),
+		After:      []byte(`{"owner":"Mike"}`),
+		CreatedAt:  time.Now(),
+	}
+
+	const sqlInsertAuditEvent = `INSERT INTO "audit_events" ("actor","request_id","operation","entity_type","entity_id","before","after","source_ip","created_at") 
+						VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING "id"`
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(sqlInsertAuditEvent)).
+		WithArgs("teller-7", "req-1", event.Operation, event.EntityType, event.EntityID, event.Before, event.After,
+			"10.0.0.1", event.CreatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+	err := auditRepositoryImpl.SaveAuditEvent(&event)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, event.Id, 1)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestGetAuditEvents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info("In func() GetAuditEvents :: REPO LAYER")
+	gdb, mock = mockDbConnection()
+	auditRepositoryImpl := repository.NewAuditRepository(gdb)
+	rows := sqlmock.
+		NewRows([]string{"id", "actor", "operation", "entity_type", "entity_id"}).
+		AddRow(4, "teller-7", "ACCOUNT_DELETED", "account", 2)
+
+	const sqlSelectByEntity = `SELECT * FROM "audit_events" WHERE entity_type = $1 AND entity_id = $2 ORDER BY id DESC LIMIT 10 OFFSET 10`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectByEntity)).
+		WithArgs("account", 2).WillReturnRows(rows)
+	events, err := auditRepositoryImpl.GetAuditEvents(context.Background(), repository.AuditQuery{EntityType: "account", EntityID: 2, Limit: 10, Offset: 10})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, events[0].Actor, "teller-7")
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"gorm.io/gorm"
+)
+
+// reconcileTolerance absorbs the rounding of float balances when comparing them with the ledger
+const reconcileTolerance = 0.005
+
+type EntryRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+type EntryRepository interface {
+	SaveEntry(context.Context, *models.Entry) error
+	GetEntriesByAccountId(ctx context.Context, accountID int) ([]models.Entry, error)
+	GetRecentEntries(ctx context.Context, accountIDs []int, limit int, beforeID int) (map[int][]models.Entry, error)
+	GetBalanceMismatches(context.Context) ([]models.BalanceMismatch, error)
+	WithTrx(*gorm.DB) EntryRepository
+}
+
+func NewEntryRepository(db *gorm.DB) EntryRepository {
+	return EntryRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (a EntryRepositoryImpl) SaveEntry(ctx context.Context, entry *models.Entry) error {
+	logger.FromContext(ctx).Info("In func() SaveEntry :: REPO LAYER")
+	err := a.DB.WithContext(ctx).Create(&entry).Error
+	return err
+}
+
+func (a EntryRepositoryImpl) GetEntriesByAccountId(ctx context.Context, accountID int) (entries []models.Entry, err error) {
+	logger.FromContext(ctx).Info("In func() GetEntriesByAccountId :: REPO LAYER")
+	err = a.DB.WithContext(ctx).Where("account_id = ?", accountID).Order("id ASC").Find(&entries).Error
+	return entries, err
+}
+
+// GetRecentEntries returns, for each account, at most limit entries newest first.
+// Only entries with an id lower than beforeID are returned when it is set.
+func (a EntryRepositoryImpl) GetRecentEntries(ctx context.Context, accountIDs []int, limit int, beforeID int) (map[int][]models.Entry, error) {
+	logger.FromContext(ctx).Info("In func() GetRecentEntries :: REPO LAYER")
+	db := a.DB.WithContext(ctx)
+	ranked := db.Model(&models.Entry{}).
+		Select("entries.*, ROW_NUMBER() OVER (PARTITION BY account_id ORDER BY id DESC) AS entry_rank").
+		Where("account_id IN ?", accountIDs)
+	if beforeID > 0 {
+		ranked = ranked.Where("id < ?", beforeID)
+	}
+	var entries []models.Entry
+	err := db.Table("(?) AS ranked", ranked).Where("entry_rank <= ?", limit).
+		Order("id DESC").Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	grouped := make(map[int][]models.Entry, len(accountIDs))
+	for _, entry := range entries {
+		grouped[entry.AccountID] = append(grouped[entry.AccountID], entry)
+	}
+	return grouped, nil
+}
+
+// GetBalanceMismatches returns the accounts whose balance is not the sum of their entries
+func (a EntryRepositoryImpl) GetBalanceMismatches(ctx context.Context) (mismatches []models.BalanceMismatch, err error) {
+	logger.FromContext(ctx).Info("In func() GetBalanceMismatches :: REPO LAYER")
+	err = a.DB.WithContext(ctx).Model(&models.Account{}).
+		Select("accounts.id AS account_id, accounts.balance, COALESCE(SUM(entries.amount), 0) AS ledger_balance").
+		Joins("LEFT JOIN entries ON entries.account_id = accounts.id").
+		Group("accounts.id, accounts.balance").
+		Having("ABS(accounts.balance - COALESCE(SUM(entries.amount), 0)) > ?", reconcileTolerance).
+		Order("accounts.id").
+		Scan(&mismatches).Error
+	return mismatches, err
+}
+
+func (a EntryRepositoryImpl) WithTrx(trxHandle *gorm.DB) EntryRepository {
+	logger.Log.Info("In func() WithTrx :: REPO LAYER")
+	if trxHandle == nil {
+		logger.Log.Info("Transaction Database not found")
+		return a
+	}
+	a.DB = trxHandle
+	return a
+}
@@ -0,0 +1,106 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mockI "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestSaveEntry(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info("In func() SaveEntry :: REPO LAYER")
+	gdb, mock = mockDbConnection()
+	entryRepositoryImpl := repository.NewEntryRepository(gdb)
+
+	entry := models.Entry{
+		AccountID: 1,
+		Amount:    20.0,
+		CreatedAt: time.Now(),
+	}
+
+	const sqlInsertEntry = `INSERT INTO "entries" ("account_id","amount","reason_code","created_at") 
+						VALUES ($1,$2,$3,$4) RETURNING "id"`
+
+	const newId = 1
+	mock.ExpectBegin() // start transaction
+	mock.ExpectQuery(regexp.QuoteMeta(sqlInsertEntry)).
+		WithArgs(entry.AccountID, entry.Amount, entry.ReasonCode, entry.CreatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newId))
+	mock.ExpectCommit() // commit transaction
+	entryRepositoryImpl.SaveEntry(context.Background(), &entry)
+	err := mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestGetEntriesByAccountId(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info("In func() GetEntriesByAccountId :: REPO LAYER")
+	gdb, mock = mockDbConnection()
+	rows := sqlmock.
+		NewRows([]string{"id", "account_id", "amount", "reason_code", "created_at"}).
+		AddRow(1, 1, 100, "OPENING_BALANCE", time.Now()).
+		AddRow(2, 1, -40, "", time.Now())
+
+	entryRepositoryImpl := repository.NewEntryRepository(gdb)
+	const sqlSelectEntries = `SELECT * FROM "entries" WHERE account_id = $1 ORDER BY id ASC`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectEntries)).
+		WithArgs(1).WillReturnRows(rows)
+	entries, err := entryRepositoryImpl.GetEntriesByAccountId(context.Background(), 1)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(entries), 2)
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestGetBalanceMismatches(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info("In func() GetBalanceMismatches :: REPO LAYER")
+	gdb, mock = mockDbConnection()
+	rows := sqlmock.NewRows([]string{"account_id", "balance", "ledger_balance"}).AddRow(3, 100, 80)
+
+	entryRepositoryImpl := repository.NewEntryRepository(gdb)
+	const sqlSelectMismatches = `SELECT accounts.id AS account_id, accounts.balance, COALESCE(SUM(entries.amount), 0) AS ledger_balance ` +
+		`FROM "accounts" LEFT JOIN entries ON entries.account_id = accounts.id GROUP BY accounts.id, accounts.balance ` +
+		`HAVING ABS(accounts.balance - COALESCE(SUM(entries.amount), 0)) > $1 ORDER BY accounts.id`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectMismatches)).WithArgs(0.005).WillReturnRows(rows)
+	mismatches, err := entryRepositoryImpl.GetBalanceMismatches(context.Background())
+	assert.Equal(t, err, nil)
+	assert.Equal(t, mismatches, []models.BalanceMismatch{{AccountID: 3, Balance: 100, LedgerBalance: 80}})
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestEntryWithTrx(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info("In func() WithTrx :: REPO LAYER")
+	gdb, mock = mockDbConnection()
+	entryRepositoryImpl := repository.NewEntryRepository(gdb)
+	assert.Equal(t, entryRepositoryImpl.WithTrx(gdb), entryRepositoryImpl)
+
+	mockLogger.EXPECT().Info("In func() WithTrx :: REPO LAYER")
+	mockLogger.EXPECT().Info("Transaction Database not found")
+	assert.Equal(t, entryRepositoryImpl.WithTrx(nil), entryRepositoryImpl)
+}
@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"gorm.io/gorm"
+)
+
+// MemoryAccountRepositoryImpl keeps the accounts in a MemoryStore
+type MemoryAccountRepositoryImpl struct {
+	memoryRepository
+}
+
+func NewMemoryAccountRepository(store *MemoryStore) AccountRepository {
+	return MemoryAccountRepositoryImpl{memoryRepository{store: store}}
+}
+
+func (a MemoryAccountRepositoryImpl) SaveAccount(ctx context.Context, account models.Account) (models.Account, error) {
+	logger.FromContext(ctx).Info("In func() SaveAccount :: REPO LAYER")
+	account.Id = a.store.nextID(&a.store.lastAccountID)
+	if account.CreatedAt.IsZero() {
+		account.CreatedAt = time.Now()
+	}
+	saved := account
+	err := a.write(ctx, nil, func(d *memoryData) {
+		d.accounts[saved.Id] = saved
+	})
+	return account, err
+}
+
+func (a MemoryAccountRepositoryImpl) GetAll(ctx context.Context, query AccountQuery) ([]models.Account, error) {
+	logger.FromContext(ctx).Info("In func() GetAll :: REPO LAYER")
+	var accounts []models.Account
+	err := a.read(ctx, func(d memoryData) {
+		accounts = filterMemoryAccounts(d, query)
+	})
+	if err != nil {
+		return nil, err
+	}
+	column := query.SortColumn
+	if column == "" {
+		column = "id"
+	}
+	var after interface{}
+	if query.After != nil && column != "id" {
+		if after, err = query.After.value(column); err != nil {
+			return nil, err
+		}
+	}
+	// compare orders by the sort column and then by id, as pageAccounts does in SQL
+	compare := func(account models.Account, value interface{}, id int) int {
+		var c int
+		switch column {
+		case "created_at":
+			c = compareTime(account.CreatedAt, value.(time.Time))
+		case "owner":
+			c = strings.Compare(account.Owner, value.(string))
+		case "balance":
+			c = compareFloat(account.Balance, value.(float64))
+		}
+		if c == 0 {
+			c = account.Id - id
+		}
+		if query.SortDesc {
+			c = -c
+		}
+		return c
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return compare(accounts[i], sortValue(accounts[j], column), accounts[j].Id) < 0
+	})
+	if query.After != nil {
+		from := sort.Search(len(accounts), func(i int) bool {
+			return compare(accounts[i], after, query.After.Id) > 0
+		})
+		accounts = accounts[from:]
+	}
+	if query.Offset > 0 {
+		accounts = accounts[min(query.Offset, len(accounts)):]
+	}
+	if query.Limit >= 0 {
+		accounts = accounts[:min(query.Limit, len(accounts))]
+	}
+	return accounts, nil
+}
+
+// CountAll returns the number of accounts matching the filters of the query, ignoring paging
+func (a MemoryAccountRepositoryImpl) CountAll(ctx context.Context, query AccountQuery) (total int64, err error) {
+	logger.FromContext(ctx).Info("In func() CountAll :: REPO LAYER")
+	err = a.read(ctx, func(d memoryData) {
+		total = int64(len(filterMemoryAccounts(d, query)))
+	})
+	return total, err
+}
+
+func filterMemoryAccounts(d memoryData, query AccountQuery) []models.Account {
+	accounts := make([]models.Account, 0, len(d.accounts))
+	for _, account := range d.accounts {
+		switch {
+		case query.Owner != "" && account.Owner != query.Owner,
+			query.Currency != "" && account.Currency != query.Currency,
+			query.Status != "" && account.Status != query.Status,
+			!query.CreatedFrom.IsZero() && account.CreatedAt.Before(query.CreatedFrom),
+			!query.CreatedTo.IsZero() && !account.CreatedAt.Before(query.CreatedTo):
+			continue
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts
+}
+
+func sortValue(account models.Account, column string) interface{} {
+	switch column {
+	case "created_at":
+		return account.CreatedAt
+	case "owner":
+		return account.Owner
+	case "balance":
+		return account.Balance
+	}
+	return nil
+}
+
+func compareTime(a time.Time, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+func compareFloat(a float64, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (a MemoryAccountRepositoryImpl) GetAccountById(ctx context.Context, id int) (account models.Account, err error) {
+	logger.FromContext(ctx).Info("In func() GetAccountById :: REPO LAYER")
+	found := false
+	if err = a.read(ctx, func(d memoryData) {
+		account, found = d.accounts[id]
+	}); err != nil {
+		return account, err
+	}
+	if !found {
+		return account, gorm.ErrRecordNotFound
+	}
+	return account, nil
+}
+
+// GetAccountsByIds returns the accounts ordered by id, ids without an account are skipped
+func (a MemoryAccountRepositoryImpl) GetAccountsByIds(ctx context.Context, ids []int) ([]models.Account, error) {
+	logger.FromContext(ctx).Info("In func() GetAccountsByIds :: REPO LAYER")
+	accounts := []models.Account{}
+	err := a.read(ctx, func(d memoryData) {
+		for _, id := range sortedIDs(ids) {
+			if account, ok := d.accounts[id]; ok {
+				accounts = append(accounts, account)
+			}
+		}
+	})
+	return accounts, err
+}
+
+func (a MemoryAccountRepositoryImpl) DeleteAccountById(ctx context.Context, id int) error {
+	logger.FromContext(ctx).Info("In func() DeleteAccountById :: REPO LAYER")
+	return a.write(ctx, func(d memoryData) error {
+		if _, ok := d.accounts[id]; !ok {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	}, func(d *memoryData) {
+		delete(d.accounts, id)
+	})
+}
+
+// UpdateAccountById writes the non-zero fields of the changed account, like gorm Updates with a struct
+func (a MemoryAccountRepositoryImpl) UpdateAccountById(ctx context.Context, originalAccount models.Account, changedAccount models.Account) (models.Account, error) {
+	logger.FromContext(ctx).Info("In func() UpdateAccountById :: REPO LAYER")
+	id, changed := originalAccount.Id, changedAccount
+	err := a.write(ctx, nil, func(d *memoryData) {
+		account, ok := d.accounts[id]
+		if !ok {
+			return
+		}
+		if changed.Currency != "" {
+			account.Currency = changed.Currency
+		}
+		if changed.Owner != "" {
+			account.Owner = changed.Owner
+		}
+		if changed.Balance != 0 {
+			account.Balance = changed.Balance
+		}
+		if changed.Status != "" {
+			account.Status = changed.Status
+		}
+		if !changed.CreatedAt.IsZero() {
+			account.CreatedAt = changed.CreatedAt
+		}
+		d.accounts[id] = account
+	})
+	return changedAccount, err
+}
+
+// PatchAccountById updates only the given columns, so zero values such as empty strings are written as well
+func (a MemoryAccountRepositoryImpl) PatchAccountById(ctx context.Context, account models.Account, changes map[string]interface{}) (models.Account, error) {
+	logger.FromContext(ctx).Info("In func() PatchAccountById :: REPO LAYER")
+	for column, value := range changes {
+		if err := setAccountColumn(&account, column, value); err != nil {
+			return account, err
+		}
+	}
+	id, patch := account.Id, changes
+	err := a.write(ctx, nil, func(d *memoryData) {
+		stored, ok := d.accounts[id]
+		if !ok {
+			return
+		}
+		for column, value := range patch {
+			setAccountColumn(&stored, column, value)
+		}
+		d.accounts[id] = stored
+	})
+	return account, err
+}
+
+func setAccountColumn(account *models.Account, column string, value interface{}) error {
+	var ok bool
+	switch column {
+	case "currency":
+		account.Currency, ok = value.(string)
+	case "owner":
+		account.Owner, ok = value.(string)
+	case "status":
+		account.Status, ok = value.(string)
+	case "balance":
+		account.Balance, ok = value.(float64)
+	case "transfer_limit":
+		account.TransferLimit, ok = value.(float64)
+	default:
+		return fmt.Errorf("no such column: %s", column)
+	}
+	if !ok {
+		return fmt.Errorf("invalid value %v for column %s", value, column)
+	}
+	return nil
+}
+
+func (a MemoryAccountRepositoryImpl) IncrementBalance(ctx context.Context, receiver int, amount float64) error {
+	logger.FromContext(ctx).Info("In func() IncrementBalance :: REPO LAYER")
+	return a.write(ctx, nil, func(d *memoryData) {
+		if account, ok := d.accounts[receiver]; ok {
+			account.Balance += amount
+			d.accounts[receiver] = account
+		}
+	})
+}
+
+func (a MemoryAccountRepositoryImpl) DecrementBalance(ctx context.Context, giver int, amount float64) error {
+	logger.FromContext(ctx).Info("In func() DecrementBalance :: REPO LAYER")
+	check := func(d memoryData) error {
+		if account, ok := d.accounts[giver]; !ok || account.Balance < amount {
+			return ErrInsufficientBalance
+		}
+		return nil
+	}
+	return a.write(ctx, check, func(d *memoryData) {
+		if account, ok := d.accounts[giver]; ok {
+			account.Balance -= amount
+			d.accounts[giver] = account
+		}
+	})
+}
+
+func (a MemoryAccountRepositoryImpl) WithTrx(trxHandle *gorm.DB) AccountRepository {
+	logger.Log.Info("In func() WithTrx :: REPO LAYER")
+	a.memoryRepository = a.withTrx(trxHandle)
+	return a
+}
@@ -0,0 +1,109 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mockI "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestMemoryAccountRepositoryConcurrentWrites(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+	ctx := context.Background()
+	db := openSqlite(t)
+	repo := repository.NewMemoryAccountRepository(repository.NewMemoryStore(db))
+	alice := saveAccounts(t, repo, models.Account{Owner: "alice", Currency: "USD"})[0]
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			repo.IncrementBalance(ctx, alice.Id, 1)
+		}()
+		go func() {
+			defer wg.Done()
+			tx := db.Begin()
+			repo.WithTrx(tx).IncrementBalance(ctx, alice.Id, 1)
+			tx.Commit()
+		}()
+	}
+	wg.Wait()
+
+	account, _ := repo.GetAccountById(ctx, alice.Id)
+	assert.Equal(t, account.Balance, float64(40))
+}
+
+// TestMemoryAccountRepositoryInterleavedTransactions checks that a commit keeps the changes
+// committed by another transaction after its snapshot was taken, across the repositories sharing the store
+func TestMemoryAccountRepositoryInterleavedTransactions(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+	ctx := context.Background()
+	db := openSqlite(t)
+	store := repository.NewMemoryStore(db)
+	repo, entries := repository.NewMemoryAccountRepository(store), repository.NewMemoryEntryRepository(store)
+	alice := saveAccounts(t, repo, models.Account{Owner: "alice", Currency: "USD"})[0]
+
+	first, second := db.Begin(), db.Begin()
+	assert.Equal(t, repo.WithTrx(first).IncrementBalance(ctx, alice.Id, 5), nil)
+	assert.Equal(t, repo.WithTrx(second).IncrementBalance(ctx, alice.Id, 7), nil)
+	bob, err := repo.WithTrx(second).SaveAccount(ctx, models.Account{Owner: "bob", Currency: "USD"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, entries.WithTrx(second).SaveEntry(ctx, &models.Entry{AccountID: alice.Id, Amount: 7}), nil)
+	first.Commit()
+	second.Commit()
+
+	account, _ := repo.GetAccountById(ctx, alice.Id)
+	assert.Equal(t, account.Balance, float64(12))
+	_, err = repo.GetAccountById(ctx, bob.Id)
+	assert.Equal(t, err, nil)
+	found, _ := entries.GetEntriesByAccountId(ctx, alice.Id)
+	assert.Equal(t, len(found), 1)
+}
+
+// TestMemoryAccountRepositoryInterleavedDebits checks that a debit checked against the snapshot of its
+// transaction is checked again at commit, against the debits committed since
+func TestMemoryAccountRepositoryInterleavedDebits(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+	ctx := context.Background()
+	db := openSqlite(t)
+	store := repository.NewMemoryStore(db)
+	repo, entries := repository.NewMemoryAccountRepository(store), repository.NewMemoryEntryRepository(store)
+	alice := saveAccounts(t, repo, models.Account{Owner: "alice", Currency: "USD", Balance: 100})[0]
+
+	first, second := db.Begin(), db.Begin()
+	assert.Equal(t, repo.WithTrx(first).DecrementBalance(ctx, alice.Id, 100), nil)
+	assert.Equal(t, repo.WithTrx(second).DecrementBalance(ctx, alice.Id, 100), nil)
+	assert.Equal(t, entries.WithTrx(second).SaveEntry(ctx, &models.Entry{AccountID: alice.Id, Amount: -100}), nil)
+	assert.Equal(t, first.Commit().Error, nil)
+	//the balance no longer covers the second debit, none of its changes is applied
+	assert.Equal(t, errors.Is(second.Commit().Error, repository.ErrInsufficientBalance), true)
+
+	account, _ := repo.GetAccountById(ctx, alice.Id)
+	assert.Equal(t, account.Balance, float64(0))
+	found, _ := entries.GetEntriesByAccountId(ctx, alice.Id)
+	assert.Equal(t, len(found), 0)
+
+	//the store is usable after the refused commit
+	third := db.Begin()
+	assert.Equal(t, repo.WithTrx(third).IncrementBalance(ctx, alice.Id, 5), nil)
+	assert.Equal(t, third.Commit().Error, nil)
+	account, _ = repo.GetAccountById(ctx, alice.Id)
+	assert.Equal(t, account.Balance, float64(5))
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"gorm.io/gorm"
+)
+
+// MemoryEntryRepositoryImpl keeps the ledger entries in a MemoryStore
+type MemoryEntryRepositoryImpl struct {
+	memoryRepository
+}
+
+func NewMemoryEntryRepository(store *MemoryStore) EntryRepository {
+	return MemoryEntryRepositoryImpl{memoryRepository{store: store}}
+}
+
+func (a MemoryEntryRepositoryImpl) SaveEntry(ctx context.Context, entry *models.Entry) error {
+	logger.FromContext(ctx).Info("In func() SaveEntry :: REPO LAYER")
+	entry.Id = a.store.nextID(&a.store.lastEntryID)
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	saved := *entry
+	return a.write(ctx, nil, func(d *memoryData) {
+		d.entries[saved.Id] = saved
+	})
+}
+
+func (a MemoryEntryRepositoryImpl) GetEntriesByAccountId(ctx context.Context, accountID int) ([]models.Entry, error) {
+	logger.FromContext(ctx).Info("In func() GetEntriesByAccountId :: REPO LAYER")
+	entries := []models.Entry{}
+	err := a.read(ctx, func(d memoryData) {
+		for _, entry := range d.entries {
+			if entry.AccountID == accountID {
+				entries = append(entries, entry)
+			}
+		}
+	})
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Id < entries[j].Id })
+	return entries, err
+}
+
+// GetRecentEntries returns, for each account, at most limit entries newest first.
+// Only entries with an id lower than beforeID are returned when it is set.
+func (a MemoryEntryRepositoryImpl) GetRecentEntries(ctx context.Context, accountIDs []int, limit int, beforeID int) (map[int][]models.Entry, error) {
+	logger.FromContext(ctx).Info("In func() GetRecentEntries :: REPO LAYER")
+	wanted := make(map[int]bool, len(accountIDs))
+	for _, id := range accountIDs {
+		wanted[id] = true
+	}
+	var entries []models.Entry
+	err := a.read(ctx, func(d memoryData) {
+		for _, entry := range d.entries {
+			if wanted[entry.AccountID] && (beforeID <= 0 || entry.Id < beforeID) {
+				entries = append(entries, entry)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Id > entries[j].Id })
+	grouped := make(map[int][]models.Entry, len(accountIDs))
+	for _, entry := range entries {
+		if len(grouped[entry.AccountID]) < limit {
+			grouped[entry.AccountID] = append(grouped[entry.AccountID], entry)
+		}
+	}
+	return grouped, nil
+}
+
+// GetBalanceMismatches returns the accounts whose balance is not the sum of their entries
+func (a MemoryEntryRepositoryImpl) GetBalanceMismatches(ctx context.Context) ([]models.BalanceMismatch, error) {
+	logger.FromContext(ctx).Info("In func() GetBalanceMismatches :: REPO LAYER")
+	var mismatches []models.BalanceMismatch
+	err := a.read(ctx, func(d memoryData) {
+		ledger := make(map[int]float64, len(d.accounts))
+		for _, entry := range d.entries {
+			ledger[entry.AccountID] += entry.Amount
+		}
+		for id, account := range d.accounts {
+			if math.Abs(account.Balance-ledger[id]) > reconcileTolerance {
+				mismatches = append(mismatches, models.BalanceMismatch{
+					AccountID:     id,
+					Balance:       account.Balance,
+					LedgerBalance: ledger[id],
+				})
+			}
+		}
+	})
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].AccountID < mismatches[j].AccountID })
+	return mismatches, err
+}
+
+func (a MemoryEntryRepositoryImpl) WithTrx(trxHandle *gorm.DB) EntryRepository {
+	logger.Log.Info("In func() WithTrx :: REPO LAYER")
+	a.memoryRepository = a.withTrx(trxHandle)
+	return a
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"gorm.io/gorm"
+)
+
+// MemoryStore keeps accounts, transfers and entries in memory for the memory repositories, for demos and tests.
+// It is safe for concurrent use and follows the gorm transactions handed to WithTrx: a transaction works on
+// a snapshot of the data taken when it is first used, its changes are applied to the shared data when the
+// gorm transaction commits and dropped when it rolls back. The checks of the changes, like the balance of a
+// debit, run again on the shared data before the commit, which is refused when one no longer holds.
+// Ids are never reused, like database sequences.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	data memoryData
+	// transactions in progress by the gorm connection they run on
+	txs                                        map[gorm.ConnPool]*memoryTx
+	lastAccountID, lastTransferID, lastEntryID int
+}
+
+// memoryData is the content of the store, or of the snapshot of a transaction
+type memoryData struct {
+	accounts  map[int]models.Account
+	transfers map[int]models.Transfer
+	entries   map[int]models.Entry
+}
+
+func (d memoryData) clone() memoryData {
+	c := memoryData{
+		accounts:  make(map[int]models.Account, len(d.accounts)),
+		transfers: make(map[int]models.Transfer, len(d.transfers)),
+		entries:   make(map[int]models.Entry, len(d.entries)),
+	}
+	for id, account := range d.accounts {
+		c.accounts[id] = account
+	}
+	for id, transfer := range d.transfers {
+		c.transfers[id] = transfer
+	}
+	for id, entry := range d.entries {
+		c.entries[id] = entry
+	}
+	return c
+}
+
+// memoryTx records its changes so that they can be replayed on the shared data at commit,
+// which keeps changes committed by other transactions in the meantime
+type memoryTx struct {
+	mu      sync.Mutex
+	data    memoryData
+	changes []memoryChange
+	// prepared is set once the changes were checked against the shared data, the store stays locked
+	// until the commit ends. committed is the shared data with the changes applied, nil without checks.
+	prepared  bool
+	committed *memoryData
+}
+
+// memoryChange is a change of a transaction and the check it has to pass, nil when it always applies
+type memoryChange struct {
+	check  func(memoryData) error
+	change func(*memoryData)
+}
+
+// NewMemoryStore returns an empty store. db is only used to follow the transactions begun on it,
+// which requires a gorm plugin the store installs on db.
+func NewMemoryStore(db *gorm.DB) *MemoryStore {
+	if err := useTxHooks(db); err != nil {
+		panic(fmt.Sprintf("cannot follow the transactions of the database: %v", err))
+	}
+	return &MemoryStore{
+		data: memoryData{
+			accounts:  map[int]models.Account{},
+			transfers: map[int]models.Transfer{},
+			entries:   map[int]models.Entry{},
+		},
+		txs: map[gorm.ConnPool]*memoryTx{},
+	}
+}
+
+// memoryRepository is embedded by the memory repositories, tx is set on the ones bound to a transaction
+type memoryRepository struct {
+	store *MemoryStore
+	tx    *memoryTx
+}
+
+// read runs fn on the data the repository sees, the snapshot of its transaction or the shared data
+func (r memoryRepository) read(ctx context.Context, fn func(memoryData)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.tx != nil {
+		r.tx.mu.Lock()
+		defer r.tx.mu.Unlock()
+		fn(r.tx.data)
+		return nil
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	fn(r.store.data)
+	return nil
+}
+
+// write applies change to the snapshot of the transaction, to be replayed at commit, or to the shared data outside of one.
+// check runs on the same data before, under the same lock, and aborts the change when it fails. It runs again on
+// the shared data when the transaction commits.
+func (r memoryRepository) write(ctx context.Context, check func(memoryData) error, change func(*memoryData)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.tx != nil {
+		r.tx.mu.Lock()
+		defer r.tx.mu.Unlock()
+		if check != nil {
+			if err := check(r.tx.data); err != nil {
+				return err
+			}
+		}
+		change(&r.tx.data)
+		r.tx.changes = append(r.tx.changes, memoryChange{check: check, change: change})
+		return nil
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if check != nil {
+		if err := check(r.store.data); err != nil {
+			return err
+		}
+	}
+	change(&r.store.data)
+	return nil
+}
+
+func (s *MemoryStore) nextID(last *int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	*last++
+	return *last
+}
+
+// withTrx binds the repository to the transaction trxHandle runs in, all repositories bound to the same
+// transaction share its snapshot
+func (r memoryRepository) withTrx(trxHandle *gorm.DB) memoryRepository {
+	if trxHandle == nil {
+		logger.Log.Info("Transaction Database not found")
+		return r
+	}
+	store, conn := r.store, trxHandle.Statement.ConnPool
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if tx, ok := store.txs[conn]; ok {
+		r.tx = tx
+		return r
+	}
+	tx := &memoryTx{data: store.data.clone()}
+	registered := afterTx(trxHandle, func() error {
+		// the store is locked until the commit or the rollback, no other transaction commits in between
+		store.mu.Lock()
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+		if err := tx.prepare(store.data); err != nil {
+			store.mu.Unlock()
+			return err
+		}
+		tx.prepared = true
+		return nil
+	}, func() {
+		defer store.mu.Unlock()
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+		if tx.committed != nil {
+			store.data = *tx.committed
+		} else {
+			for _, c := range tx.changes {
+				c.change(&store.data)
+			}
+		}
+		delete(store.txs, conn)
+	}, func() {
+		tx.mu.Lock()
+		prepared := tx.prepared
+		tx.mu.Unlock()
+		if !prepared {
+			store.mu.Lock()
+		}
+		defer store.mu.Unlock()
+		delete(store.txs, conn)
+	})
+	if !registered {
+		logger.Log.Info("Transaction Database not found")
+		return r
+	}
+	store.txs[conn] = tx
+	r.tx = tx
+	return r
+}
+
+// prepare replays the changes and their checks on a copy of shared, to be swapped in at commit. The copy is
+// only made when a change has a check, the changes are replayed on the shared data at commit otherwise.
+func (tx *memoryTx) prepare(shared memoryData) error {
+	checked := false
+	for _, c := range tx.changes {
+		checked = checked || c.check != nil
+	}
+	if !checked {
+		return nil
+	}
+	data := shared.clone()
+	for _, c := range tx.changes {
+		if c.check != nil {
+			if err := c.check(data); err != nil {
+				return err
+			}
+		}
+		c.change(&data)
+	}
+	tx.committed = &data
+	return nil
+}
+
+// sortedIDs returns the distinct ids in ascending order
+func sortedIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	sorted := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			sorted = append(sorted, id)
+		}
+	}
+	sort.Ints(sorted)
+	return sorted
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
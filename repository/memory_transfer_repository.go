@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"gorm.io/gorm"
+)
+
+// MemoryTransferRepositoryImpl keeps the transfers in a MemoryStore
+type MemoryTransferRepositoryImpl struct {
+	memoryRepository
+}
+
+func NewMemoryTransferRepository(store *MemoryStore) TransferRepository {
+	return MemoryTransferRepositoryImpl{memoryRepository{store: store}}
+}
+
+func (a MemoryTransferRepositoryImpl) SaveTransfer(ctx context.Context, transfer *models.Transfer) (models.Transfer, error) {
+	logger.FromContext(ctx).Info("In func() SaveTransfer :: REPO LAYER")
+	transfer.Id = a.store.nextID(&a.store.lastTransferID)
+	if transfer.CreatedAt.IsZero() {
+		transfer.CreatedAt = time.Now()
+	}
+	saved := *transfer
+	err := a.write(ctx, nil, func(d *memoryData) {
+		d.transfers[saved.Id] = saved
+	})
+	return *transfer, err
+}
+
+func (a MemoryTransferRepositoryImpl) GetTransferById(ctx context.Context, id int) (transfer models.Transfer, err error) {
+	logger.FromContext(ctx).Info("In func() GetTransferById :: REPO LAYER")
+	found := false
+	if err = a.read(ctx, func(d memoryData) {
+		transfer, found = d.transfers[id]
+	}); err != nil {
+		return transfer, err
+	}
+	if !found {
+		return transfer, gorm.ErrRecordNotFound
+	}
+	return transfer, nil
+}
+
+// GetRecentTransfers returns, for each account, at most limit transfers sent or received by it, newest first.
+// Only transfers with an id lower than beforeID are returned when it is set.
+func (a MemoryTransferRepositoryImpl) GetRecentTransfers(ctx context.Context, accountIDs []int, limit int, beforeID int) (map[int][]models.Transfer, error) {
+	logger.FromContext(ctx).Info("In func() GetRecentTransfers :: REPO LAYER")
+	wanted := make(map[int]bool, len(accountIDs))
+	for _, id := range accountIDs {
+		wanted[id] = true
+	}
+	var transfers []models.Transfer
+	err := a.read(ctx, func(d memoryData) {
+		for _, transfer := range d.transfers {
+			if (wanted[transfer.FromAccountID] || wanted[transfer.ToAccountID]) && (beforeID <= 0 || transfer.Id < beforeID) {
+				transfers = append(transfers, transfer)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(transfers, func(i, j int) bool { return transfers[i].Id > transfers[j].Id })
+	return groupRecentTransfers(transfers, accountIDs, limit), nil
+}
+
+func (a MemoryTransferRepositoryImpl) WithTrx(trxHandle *gorm.DB) TransferRepository {
+	logger.Log.Info("In func() WithTrx :: REPO LAYER")
+	a.memoryRepository = a.withTrx(trxHandle)
+	return a
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NonceRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// NonceRepository stores the nonces of signed requests, it implements auth.NonceStore
+type NonceRepository interface {
+	UseNonce(ctx context.Context, partnerID string, nonce string, expiresAt time.Time) (bool, error)
+	DeleteExpiredNonces(ctx context.Context, now time.Time) (int64, error)
+}
+
+func NewNonceRepository(db *gorm.DB) NonceRepository {
+	return NonceRepositoryImpl{
+		DB: db,
+	}
+}
+
+// UseNonce relies on the unique key of partner and nonce, so that concurrent requests with the same nonce
+// cannot both see it as fresh
+func (a NonceRepositoryImpl) UseNonce(ctx context.Context, partnerID string, nonce string, expiresAt time.Time) (bool, error) {
+	logger.FromContext(ctx).Info("In func() UseNonce :: REPO LAYER")
+	result := a.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.RequestNonce{PartnerID: partnerID, Nonce: nonce, ExpiresAt: expiresAt})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (a NonceRepositoryImpl) DeleteExpiredNonces(ctx context.Context, now time.Time) (int64, error) {
+	logger.FromContext(ctx).Info("In func() DeleteExpiredNonces :: REPO LAYER")
+	result := a.DB.WithContext(ctx).Where("expires_at < ?", now).Delete(&models.RequestNonce{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+type OutboxRepository interface {
+	SaveOutboxEvent(*models.OutboxEvent) error
+	ClaimPendingOutboxEvents(limit int, process func(OutboxRepository, []models.OutboxEvent) error) error
+	MarkOutboxEventPublished(id int) error
+	MarkOutboxEventFailed(id int, reason string) error
+	MarkOutboxEventDeadLettered(id int, reason string) error
+	WithTrx(*gorm.DB) OutboxRepository
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return OutboxRepositoryImpl{
+		DB: db,
+	}
+}
+
+// SaveOutboxEvent appends an event to the outbox with the request id of the context the DB handle carries
+func (a OutboxRepositoryImpl) SaveOutboxEvent(event *models.OutboxEvent) error {
+	logger.FromContext(a.DB.Statement.Context).Info("In func() SaveOutboxEvent :: REPO LAYER")
+	event.RequestID = logger.RequestID(a.DB.Statement.Context)
+	return a.DB.Create(event).Error
+}
+
+// ClaimPendingOutboxEvents locks the oldest pending events in the order they were written and hands them to
+// process along with a repository bound to the same transaction. The rows stay locked until process returns,
+// rows locked by another relay are skipped so that no event is delivered twice at the same time.
+func (a OutboxRepositoryImpl) ClaimPendingOutboxEvents(limit int, process func(OutboxRepository, []models.OutboxEvent) error) error {
+	logger.FromContext(a.DB.Statement.Context).Debug("In func() ClaimPendingOutboxEvents :: REPO LAYER")
+	return a.DB.Transaction(func(tx *gorm.DB) error {
+		var events []models.OutboxEvent
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND failed_at IS NULL").Order("id ASC").Limit(limit).Find(&events).Error
+		if err != nil {
+			return err
+		}
+		return process(a.WithTrx(tx), events)
+	})
+}
+
+func (a OutboxRepositoryImpl) MarkOutboxEventPublished(id int) error {
+	logger.FromContext(a.DB.Statement.Context).Info("In func() MarkOutboxEventPublished :: REPO LAYER")
+	return a.DB.Model(&models.OutboxEvent{}).Where("id=?", id).Updates(map[string]interface{}{
+		"published_at": time.Now(),
+		"attempts":     gorm.Expr("attempts + 1"),
+		"last_error":   "",
+	}).Error
+}
+
+func (a OutboxRepositoryImpl) MarkOutboxEventFailed(id int, reason string) error {
+	logger.FromContext(a.DB.Statement.Context).Info("In func() MarkOutboxEventFailed :: REPO LAYER")
+	return a.DB.Model(&models.OutboxEvent{}).Where("id=?", id).Updates(map[string]interface{}{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": reason,
+	}).Error
+}
+
+// MarkOutboxEventDeadLettered counts the last failed delivery and takes the event out of the pending ones
+func (a OutboxRepositoryImpl) MarkOutboxEventDeadLettered(id int, reason string) error {
+	logger.FromContext(a.DB.Statement.Context).Info("In func() MarkOutboxEventDeadLettered :: REPO LAYER")
+	return a.DB.Model(&models.OutboxEvent{}).Where("id=?", id).Updates(map[string]interface{}{
+		"failed_at":  time.Now(),
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": reason,
+	}).Error
+}
+
+func (a OutboxRepositoryImpl) WithTrx(trxHandle *gorm.DB) OutboxRepository {
+	logger.Log.Info("In func() WithTrx :: REPO LAYER")
+	if trxHandle == nil {
+		logger.Log.Info("Transaction Database not found")
+		return a
+	}
+	a.DB = trxHandle
+	return a
+}
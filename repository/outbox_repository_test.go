@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mockI "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestSaveOutboxEvent(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	//the request-scoped logger adds the request id to the lines
+	mockLogger.EXPECT().With(logger.RequestIDField, "req-1").Return(mockLogger)
+	mockLogger.EXPECT().Info("In func() SaveOutboxEvent :: REPO LAYER")
+	gdb, mock = mockDbConnection()
+	ctx := logger.NewContext(context.Background(), "req-1")
+	outboxRepositoryImpl := repository.NewOutboxRepository(gdb.WithContext(ctx))
+
+	event := models.OutboxEvent{
+		EventType:     "AccountCreated",
+		SchemaVersion: 1,
+		AggregateType: "account",
+		AggregateID:   1,
+		Payload:       []byte(`{"account_id":1}`),
+		CreatedAt:     time.Now(),
+		Owner:         "alice",
+	}
+
+	const sqlInsertOutboxEvent = `INSERT INTO "outbox" ("event_type","schema_version","aggregate_type","aggregate_id","payload","request_id","attempts","last_error","published_at","failed_at","created_at","owner") 
+						VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING "id"`
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(sqlInsertOutboxEvent)).
+		WithArgs(event.EventType, event.SchemaVersion, event.AggregateType, event.AggregateID, event.Payload, "req-1",
+			0, "", nil, nil, event.CreatedAt, "alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+	err := outboxRepositoryImpl.SaveOutboxEvent(&event)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, event.RequestID, "req-1")
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestClaimPendingOutboxEvents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Debug("In func() ClaimPendingOutboxEvents :: REPO LAYER")
+	mockLogger.EXPECT().Info("In func() WithTrx :: REPO LAYER")
+	mockLogger.EXPECT().Info("In func() MarkOutboxEventPublished :: REPO LAYER")
+	gdb, mock = mockDbConnection()
+	outboxRepositoryImpl := repository.NewOutboxRepository(gdb)
+
+	//the events locked by another relay are skipped, the marks are made in the claiming transaction
+	const sqlClaimOutboxEvents = `SELECT * FROM "outbox" WHERE published_at IS NULL AND failed_at IS NULL ORDER BY id ASC LIMIT 10 FOR UPDATE SKIP LOCKED`
+	const sqlMarkOutboxEventPublished = `UPDATE "outbox" SET "attempts"=attempts + 1,"last_error"=$1,"published_at"=$2 WHERE id=$3`
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(sqlClaimOutboxEvents)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "aggregate_id"}).AddRow(1, 10))
+	mock.ExpectExec(regexp.QuoteMeta(sqlMarkOutboxEventPublished)).
+		WithArgs("", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	err := outboxRepositoryImpl.ClaimPendingOutboxEvents(10, func(claimed repository.OutboxRepository, events []models.OutboxEvent) error {
+		assert.Equal(t, len(events), 1)
+		return claimed.MarkOutboxEventPublished(events[0].Id)
+	})
+	assert.Equal(t, err, nil)
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
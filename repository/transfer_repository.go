@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"gorm.io/gorm"
+)
+
+type TransferRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+type TransferRepository interface {
+	SaveTransfer(context.Context, *models.Transfer) (models.Transfer, error)
+	GetTransferById(ctx context.Context, id int) (models.Transfer, error)
+	GetRecentTransfers(ctx context.Context, accountIDs []int, limit int, beforeID int) (map[int][]models.Transfer, error)
+	WithTrx(*gorm.DB) TransferRepository
+}
+
+func NewTransferRepository(db *gorm.DB) TransferRepository {
+	return TransferRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (a TransferRepositoryImpl) SaveTransfer(ctx context.Context, transfer *models.Transfer) (models.Transfer, error) {
+	logger.FromContext(ctx).Info("In func() SaveTransfer :: REPO LAYER")
+	err := a.DB.WithContext(ctx).Create(&transfer).Error
+	return *transfer, err
+}
+
+func (a TransferRepositoryImpl) GetTransferById(ctx context.Context, id int) (transfer models.Transfer, err error) {
+	logger.FromContext(ctx).Info("In func() GetTransferById :: REPO LAYER")
+	err = a.DB.WithContext(ctx).Where("id=?", id).First(&transfer).Error
+	return transfer, err
+}
+
+// GetRecentTransfers returns, for each account, at most limit transfers sent or received by it, newest first.
+// Only transfers with an id lower than beforeID are returned when it is set.
+// The newest limit sent and the newest limit received transfers of each account are ranked in SQL and merged here,
+// which keeps it a single query for all accounts.
+func (a TransferRepositoryImpl) GetRecentTransfers(ctx context.Context, accountIDs []int, limit int, beforeID int) (map[int][]models.Transfer, error) {
+	logger.FromContext(ctx).Info("In func() GetRecentTransfers :: REPO LAYER")
+	db := a.DB.WithContext(ctx)
+	ranked := db.Model(&models.Transfer{}).
+		Select("transfers.*, "+
+			"ROW_NUMBER() OVER (PARTITION BY from_account_id ORDER BY id DESC) AS sent_rank, "+
+			"ROW_NUMBER() OVER (PARTITION BY to_account_id ORDER BY id DESC) AS received_rank").
+		Where("from_account_id IN ? OR to_account_id IN ?", accountIDs, accountIDs)
+	if beforeID > 0 {
+		ranked = ranked.Where("id < ?", beforeID)
+	}
+	var transfers []models.Transfer
+	err := db.Table("(?) AS ranked", ranked).
+		Where("from_account_id IN ? AND sent_rank <= ?", accountIDs, limit).
+		Or("to_account_id IN ? AND received_rank <= ?", accountIDs, limit).
+		Order("id DESC").Find(&transfers).Error
+	if err != nil {
+		return nil, err
+	}
+	return groupRecentTransfers(transfers, accountIDs, limit), nil
+}
+
+// groupRecentTransfers hands each of the accounts at most limit of the transfers, which are sorted newest first
+func groupRecentTransfers(transfers []models.Transfer, accountIDs []int, limit int) map[int][]models.Transfer {
+	wanted := make(map[int]bool, len(accountIDs))
+	for _, id := range accountIDs {
+		wanted[id] = true
+	}
+	grouped := make(map[int][]models.Transfer, len(accountIDs))
+	for _, transfer := range transfers {
+		for _, id := range []int{transfer.FromAccountID, transfer.ToAccountID} {
+			if wanted[id] && len(grouped[id]) < limit {
+				grouped[id] = append(grouped[id], transfer)
+			}
+		}
+	}
+	return grouped
+}
+
+func (a TransferRepositoryImpl) WithTrx(trxHandle *gorm.DB) TransferRepository {
+	logger.Log.Info("In func() WithTrx :: REPO LAYER")
+	if trxHandle == nil {
+		logger.Log.Info("Transaction Database not found")
+		return a
+	}
+	a.DB = trxHandle
+	return a
+}
@@ -0,0 +1,95 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mockI "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestSaveTransfer(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info("In func() SaveTransfer :: REPO LAYER")
+	gdb, mock = mockDbConnection()
+	transferRepositoryImpl := repository.NewTransferRepository(gdb)
+
+	transfer := models.Transfer{
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        20.0,
+		CreatedAt:     time.Now(),
+	}
+
+	const sqlInsertTransfer = `INSERT INTO "transfers" ("from_account_id","to_account_id","amount","created_at") 
+						VALUES ($1,$2,$3,$4) RETURNING "id"`
+
+	const newId = 1
+	mock.ExpectBegin() // start transaction
+	mock.ExpectQuery(regexp.QuoteMeta(sqlInsertTransfer)).
+		WithArgs(transfer.FromAccountID, transfer.ToAccountID, transfer.Amount, transfer.CreatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newId))
+	mock.ExpectCommit() // commit transaction
+	transferRepositoryImpl.SaveTransfer(context.Background(), &transfer)
+	err := mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestGetRecentTransfers(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info("In func() GetRecentTransfers :: REPO LAYER")
+	gdb, mock = mockDbConnection()
+	rows := sqlmock.
+		NewRows([]string{"id", "from_account_id", "to_account_id", "amount", "created_at"}).
+		AddRow(9, 1, 2, 5, time.Now()).
+		AddRow(8, 3, 1, 7, time.Now()).
+		AddRow(7, 2, 4, 1, time.Now())
+
+	transferRepositoryImpl := repository.NewTransferRepository(gdb)
+	const sqlSelectRecentTransfers = `SELECT * FROM (SELECT transfers.*, ` +
+		`ROW_NUMBER() OVER (PARTITION BY from_account_id ORDER BY id DESC) AS sent_rank, ` +
+		`ROW_NUMBER() OVER (PARTITION BY to_account_id ORDER BY id DESC) AS received_rank ` +
+		`FROM "transfers" WHERE (from_account_id IN ($1,$2) OR to_account_id IN ($3,$4)) AND id < $5) AS ranked ` +
+		`WHERE (from_account_id IN ($6,$7) AND sent_rank <= $8) OR (to_account_id IN ($9,$10) AND received_rank <= $11) ` +
+		`ORDER BY id DESC`
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectRecentTransfers)).
+		WithArgs(1, 2, 1, 2, 10, 1, 2, 2, 1, 2, 2).WillReturnRows(rows)
+	transfers, err := transferRepositoryImpl.GetRecentTransfers(context.Background(), []int{1, 2}, 2, 10)
+	assert.Equal(t, err, nil)
+	// the sent and received transfers are merged and cut to the limit per account
+	assert.Equal(t, len(transfers[1]), 2)
+	assert.Equal(t, transfers[1][1].Id, 8)
+	assert.Equal(t, len(transfers[2]), 2)
+	assert.Equal(t, transfers[2][1].Id, 7)
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
+
+func TestTransferWithTrx(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info("In func() WithTrx :: REPO LAYER")
+	gdb, mock = mockDbConnection()
+	transferRepositoryImpl := repository.NewTransferRepository(gdb)
+	assert.Equal(t, transferRepositoryImpl.WithTrx(gdb), transferRepositoryImpl)
+
+	mockLogger.EXPECT().Info("In func() WithTrx :: REPO LAYER")
+	mockLogger.EXPECT().Info("Transaction Database not found")
+	assert.Equal(t, transferRepositoryImpl.WithTrx(nil), transferRepositoryImpl)
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// txHooks is a gorm plugin that lets repositories keeping their data outside the database follow its transactions.
+// It wraps the connection pool so that every transaction begun on the database runs the callbacks registered
+// for it with afterTx before it commits, and once it commits or rolls back.
+type txHooks struct{}
+
+func (txHooks) Name() string {
+	return "repository:tx_hooks"
+}
+
+func (txHooks) Initialize(db *gorm.DB) error {
+	pool := &hookedPool{ConnPool: db.ConnPool}
+	db.ConnPool = pool
+	db.Statement.ConnPool = pool
+	return nil
+}
+
+// useTxHooks installs the plugin unless it already is on the database
+func useTxHooks(db *gorm.DB) error {
+	if err := db.Use(txHooks{}); err != nil && !errors.Is(err, gorm.ErrRegistered) {
+		return err
+	}
+	return nil
+}
+
+// afterTx registers callbacks for the end of the transaction tx runs in. prepare runs before the database
+// commits and may refuse the commit, the transaction is rolled back then. It reports false when tx is not
+// a transaction begun on a database with the plugin, in which case the callbacks are never run.
+func afterTx(tx *gorm.DB, prepare func() error, onCommit func(), onRollback func()) bool {
+	hooked, ok := tx.Statement.ConnPool.(*hookedTx)
+	if !ok {
+		return false
+	}
+	hooked.mu.Lock()
+	defer hooked.mu.Unlock()
+	hooked.prepare = append(hooked.prepare, prepare)
+	hooked.onCommit = append(hooked.onCommit, onCommit)
+	hooked.onRollback = append(hooked.onRollback, onRollback)
+	return true
+}
+
+type hookedPool struct {
+	gorm.ConnPool
+}
+
+func (p *hookedPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	var (
+		tx  gorm.ConnPool
+		err error
+	)
+	switch beginner := p.ConnPool.(type) {
+	case gorm.TxBeginner:
+		var sqlTx *sql.Tx
+		if sqlTx, err = beginner.BeginTx(ctx, opts); err == nil {
+			tx = sqlTx
+		}
+	case gorm.ConnPoolBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	default:
+		err = gorm.ErrInvalidTransaction
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &hookedTx{ConnPool: tx}, nil
+}
+
+// GetDBConn keeps gorm.DB.DB() working on the wrapped pool
+func (p *hookedPool) GetDBConn() (*sql.DB, error) {
+	if connector, ok := p.ConnPool.(gorm.GetDBConnector); ok {
+		return connector.GetDBConn()
+	}
+	if sqlDB, ok := p.ConnPool.(*sql.DB); ok {
+		return sqlDB, nil
+	}
+	return nil, gorm.ErrInvalidDB
+}
+
+type hookedTx struct {
+	gorm.ConnPool
+	mu         sync.Mutex
+	prepare    []func() error
+	onCommit   []func()
+	onRollback []func()
+}
+
+// Commit runs the commit callbacks, or the rollback callbacks when a prepare callback refuses the commit or
+// the commit fails because the changes are lost then
+func (t *hookedTx) Commit() error {
+	t.mu.Lock()
+	prepare := t.prepare
+	t.prepare = nil
+	t.mu.Unlock()
+	for _, callback := range prepare {
+		if err := callback(); err != nil {
+			t.ConnPool.(gorm.TxCommitter).Rollback()
+			t.end(false)
+			return err
+		}
+	}
+	err := t.ConnPool.(gorm.TxCommitter).Commit()
+	if err != nil {
+		t.end(false)
+		return err
+	}
+	t.end(true)
+	return nil
+}
+
+func (t *hookedTx) Rollback() error {
+	err := t.ConnPool.(gorm.TxCommitter).Rollback()
+	t.end(false)
+	return err
+}
+
+// end runs the callbacks once, a rollback after a commit does nothing
+func (t *hookedTx) end(committed bool) {
+	t.mu.Lock()
+	callbacks := t.onRollback
+	if committed {
+		callbacks = t.onCommit
+	}
+	t.onCommit, t.onRollback = nil, nil
+	t.mu.Unlock()
+	for _, callback := range callbacks {
+		callback()
+	}
+}
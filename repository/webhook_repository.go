@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+type WebhookRepository interface {
+	SaveWebhookSubscription(context.Context, *models.WebhookSubscription) error
+	GetWebhookSubscriptions(context.Context) ([]models.WebhookSubscription, error)
+	GetActiveWebhookSubscriptions(context.Context) ([]models.WebhookSubscription, error)
+	GetWebhookSubscriptionById(ctx context.Context, id int) (models.WebhookSubscription, error)
+	DeleteWebhookSubscriptionById(ctx context.Context, id int) error
+	SaveWebhookDelivery(context.Context, *models.WebhookDelivery) error
+	UpdateWebhookDelivery(context.Context, *models.WebhookDelivery) error
+	GetWebhookDeliveryById(ctx context.Context, id int) (models.WebhookDelivery, error)
+	GetWebhookDeliveries(ctx context.Context, subscriptionID int) ([]models.WebhookDelivery, error)
+	ClaimDueWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	SaveWebhookDeliveryAttempt(context.Context, *models.WebhookDeliveryAttempt) error
+	GetWebhookDeliveryAttempts(ctx context.Context, deliveryID int) ([]models.WebhookDeliveryAttempt, error)
+	WithTrx(*gorm.DB) WebhookRepositoryImpl
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return WebhookRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (a WebhookRepositoryImpl) SaveWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	logger.FromContext(ctx).Info("In func() SaveWebhookSubscription :: REPO LAYER")
+	return a.DB.WithContext(ctx).Create(subscription).Error
+}
+
+func (a WebhookRepositoryImpl) GetWebhookSubscriptions(ctx context.Context) (subscriptions []models.WebhookSubscription, err error) {
+	logger.FromContext(ctx).Info("In func() GetWebhookSubscriptions :: REPO LAYER")
+	err = a.DB.WithContext(ctx).Order("id ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (a WebhookRepositoryImpl) GetActiveWebhookSubscriptions(ctx context.Context) (subscriptions []models.WebhookSubscription, err error) {
+	logger.FromContext(ctx).Info("In func() GetActiveWebhookSubscriptions :: REPO LAYER")
+	err = a.DB.WithContext(ctx).Where("active = ?", true).Order("id ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (a WebhookRepositoryImpl) GetWebhookSubscriptionById(ctx context.Context, id int) (subscription models.WebhookSubscription, err error) {
+	logger.FromContext(ctx).Info("In func() GetWebhookSubscriptionById :: REPO LAYER")
+	err = a.DB.WithContext(ctx).Where("id=?", id).First(&subscription).Error
+	return subscription, err
+}
+
+func (a WebhookRepositoryImpl) DeleteWebhookSubscriptionById(ctx context.Context, id int) error {
+	logger.FromContext(ctx).Info("In func() DeleteWebhookSubscriptionById :: REPO LAYER")
+	result := a.DB.WithContext(ctx).Where("id=?", id).Delete(&models.WebhookSubscription{})
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// SaveWebhookDelivery ignores a delivery that already exists for the subscription and outbox event,
+// the outbox relay delivers at-least-once and may hand over the same event again
+func (a WebhookRepositoryImpl) SaveWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	logger.FromContext(ctx).Info("In func() SaveWebhookDelivery :: REPO LAYER")
+	return a.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(delivery).Error
+}
+
+func (a WebhookRepositoryImpl) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	logger.FromContext(ctx).Info("In func() UpdateWebhookDelivery :: REPO LAYER")
+	return a.DB.WithContext(ctx).Save(delivery).Error
+}
+
+func (a WebhookRepositoryImpl) GetWebhookDeliveryById(ctx context.Context, id int) (delivery models.WebhookDelivery, err error) {
+	logger.FromContext(ctx).Info("In func() GetWebhookDeliveryById :: REPO LAYER")
+	err = a.DB.WithContext(ctx).Where("id=?", id).First(&delivery).Error
+	return delivery, err
+}
+
+func (a WebhookRepositoryImpl) GetWebhookDeliveries(ctx context.Context, subscriptionID int) (deliveries []models.WebhookDelivery, err error) {
+	logger.FromContext(ctx).Info("In func() GetWebhookDeliveries :: REPO LAYER")
+	err = a.DB.WithContext(ctx).Where("subscription_id = ?", subscriptionID).Order("id DESC").Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ClaimDueWebhookDeliveries returns the pending deliveries whose next attempt is due, oldest first, and leases
+// them by moving their next attempt to the end of the lease. Rows locked by another dispatcher are skipped and a
+// claimed delivery is not due again before its lease runs out, so that no delivery is sent twice at the same time.
+// The dispatcher schedules the next attempt when it records the outcome.
+func (a WebhookRepositoryImpl) ClaimDueWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) (deliveries []models.WebhookDelivery, err error) {
+	logger.FromContext(ctx).Debug("In func() ClaimDueWebhookDeliveries :: REPO LAYER")
+	err = a.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+			Order("next_attempt_at ASC, id ASC").Limit(limit).Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+		leasedUntil := now.Add(lease)
+		ids := make([]int, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].Id
+			deliveries[i].NextAttemptAt = leasedUntil
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", leasedUntil).Error
+	})
+	return deliveries, err
+}
+
+func (a WebhookRepositoryImpl) SaveWebhookDeliveryAttempt(ctx context.Context, attempt *models.WebhookDeliveryAttempt) error {
+	logger.FromContext(ctx).Info("In func() SaveWebhookDeliveryAttempt :: REPO LAYER")
+	return a.DB.WithContext(ctx).Create(attempt).Error
+}
+
+func (a WebhookRepositoryImpl) GetWebhookDeliveryAttempts(ctx context.Context, deliveryID int) (attempts []models.WebhookDeliveryAttempt, err error) {
+	logger.FromContext(ctx).Info("In func() GetWebhookDeliveryAttempts :: REPO LAYER")
+	err = a.DB.WithContext(ctx).Where("delivery_id = ?", deliveryID).Order("id ASC").Find(&attempts).Error
+	return attempts, err
+}
+
+func (a WebhookRepositoryImpl) WithTrx(trxHandle *gorm.DB) WebhookRepositoryImpl {
+	logger.Log.Info("In func() WithTrx :: REPO LAYER")
+	if trxHandle == nil {
+		logger.Log.Info("Transaction Database not found")
+		return a
+	}
+	a.DB = trxHandle
+	return a
+}
@@ -0,0 +1,48 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mockI "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestClaimDueWebhookDeliveries(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mockI.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Debug("In func() ClaimDueWebhookDeliveries :: REPO LAYER")
+	gdb, mock = mockDbConnection()
+	webhookRepositoryImpl := repository.NewWebhookRepository(gdb)
+
+	//the deliveries locked by another dispatcher are skipped, the claimed ones are leased in the same transaction
+	const sqlClaimDeliveries = `SELECT * FROM "webhook_deliveries" WHERE status = $1 AND next_attempt_at <= $2 ` +
+		`ORDER BY next_attempt_at ASC, id ASC LIMIT 10 FOR UPDATE SKIP LOCKED`
+	const sqlLeaseDeliveries = `UPDATE "webhook_deliveries" SET "next_attempt_at"=$1 WHERE id IN ($2,$3)`
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(sqlClaimDeliveries)).
+		WithArgs(models.DeliveryPending, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "subscription_id"}).AddRow(1, 3).AddRow(2, 3))
+	mock.ExpectExec(regexp.QuoteMeta(sqlLeaseDeliveries)).
+		WithArgs(now.Add(time.Minute), 1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	deliveries, err := webhookRepositoryImpl.ClaimDueWebhookDeliveries(context.Background(), now, time.Minute, 10)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(deliveries), 2)
+	assert.Equal(t, deliveries[0].NextAttemptAt, now.Add(time.Minute))
+
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("Failed to meet expectations, got error: %v", err)
+	}
+}
@@ -1,14 +1,44 @@
 package service
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/devfeel/mapper"
 	"github.com/rahul-024/fund-transfer-poc/logger"
 	"github.com/rahul-024/fund-transfer-poc/models"
 	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/outbox"
 	"github.com/rahul-024/fund-transfer-poc/repository"
+	"github.com/rahul-024/fund-transfer-poc/util"
 	"gorm.io/gorm"
 )
 
+const (
+	defaultPageSize = 10
+	defaultSort     = "id"
+)
+
+// sortableAccountColumns maps the sort options accepted when listing accounts to their columns
+var sortableAccountColumns = map[string]string{
+	"id":         "id",
+	"created_at": "created_at",
+	"owner":      "owner",
+	"balance":    "balance",
+}
+
+// patchableAccountFields maps the JSON fields a client may change through a merge patch to their columns.
+// Balance is deliberately absent, it can only change through transfers and adjustments.
+var patchableAccountFields = map[string]string{
+	"owner":    "owner",
+	"currency": "currency",
+}
+
 func init() {
 
 	mapper.Register(&request.TransferRequest{})
@@ -16,69 +46,736 @@ func init() {
 }
 
 type AccountServiceImpl struct {
-	accountRepository repository.AccountRepository
+	unitOfWork UnitOfWork
+	// isolation of the transactions moving money, the other ones run with the default of the database
+	isolation sql.IsolationLevel
+	// tx is the transaction the repositories are bound to, the mutations join it instead of beginning their own
+	tx                 *gorm.DB
+	accountRepository  repository.AccountRepository
+	transferRepository repository.TransferRepository
+	entryRepository    repository.EntryRepository
+	auditRepository    repository.AuditRepository
+	outboxRepository   repository.OutboxRepository
+	policy             Policy
 }
 
 type AccountService interface {
-	SaveAccount(models.Account) (models.Account, error)
-	GetAll(pageId int, pageSize int) ([]models.Account, error)
-	GetAccountById(id int) (models.Account, error)
-	DeleteAccountById(id int) error
-	UpdateAccountById(models.Account, models.Account) (models.Account, error)
-	WithTrx(*gorm.DB) AccountServiceImpl
-	SaveTransfer(req *request.TransferRequest) (models.Transfer, error)
-	SaveEntry(req *request.TransferRequest, dc string) error
-	IncrementBalance(int, float64) error
-	DecrementBalance(int, float64) error
-}
-
-func NewAccountService(r repository.AccountRepository) AccountService {
+	SaveAccount(context.Context, models.Account) (models.Account, error)
+	GetAll(ctx context.Context, req *request.ListAccountsRequest) (models.AccountPage, error)
+	GetAccountById(ctx context.Context, id int) (models.Account, error)
+	DeleteAccountById(ctx context.Context, id int) error
+	UpdateAccountById(context.Context, models.Account, models.Account) (models.Account, error)
+	PatchAccountById(context.Context, models.Account, map[string]json.RawMessage) (models.Account, error)
+	AdjustBalance(ctx context.Context, id int, req *request.BalanceAdjustmentRequest) (models.Entry, error)
+	SetTransferLimit(ctx context.Context, id int, req *request.TransferLimitRequest) (models.Account, error)
+	RunInTx(ctx context.Context, fn func(AccountService) error) error
+	SaveTransfer(ctx context.Context, req *request.TransferRequest) (models.Transfer, error)
+	CreateTransfer(ctx context.Context, req *request.TransferRequest) (models.Transfer, error)
+	GetEntries(ctx context.Context, accountID int) ([]models.Entry, error)
+	GetAccountsByIds(ctx context.Context, ids []int) ([]models.Account, error)
+	GetTransferById(ctx context.Context, id int) (models.Transfer, error)
+	GetRecentTransfers(ctx context.Context, accountIDs []int, limit int, beforeID int) (map[int][]models.Transfer, error)
+	GetRecentEntries(ctx context.Context, accountIDs []int, limit int, beforeID int) (map[int][]models.Entry, error)
+	Reconcile(context.Context) ([]models.BalanceMismatch, error)
+	SaveEntry(ctx context.Context, req *request.TransferRequest, dc string) error
+	IncrementBalance(context.Context, int, float64) error
+	DecrementBalance(context.Context, int, float64) error
+}
+
+func NewAccountService(uow UnitOfWork, r repository.AccountRepository, tr repository.TransferRepository,
+	er repository.EntryRepository, ar repository.AuditRepository, or repository.OutboxRepository, p Policy,
+	isolation sql.IsolationLevel) AccountService {
 	return AccountServiceImpl{
-		accountRepository: r,
+		unitOfWork:         uow,
+		isolation:          isolation,
+		accountRepository:  r,
+		transferRepository: tr,
+		entryRepository:    er,
+		auditRepository:    ar,
+		outboxRepository:   or,
+		policy:             p,
+	}
+}
+
+// RunInTx runs fn on the service bound to one transaction, for callers combining several mutations.
+// The transaction commits when fn returns no error and rolls back otherwise.
+func (a AccountServiceImpl) RunInTx(ctx context.Context, fn func(AccountService) error) error {
+	logger.FromContext(ctx).Info("In func() RunInTx :: SERVICE LAYER")
+	return a.inTx(ctx, nil, func(tx AccountServiceImpl) error {
+		return fn(tx)
+	})
+}
+
+// inTx runs fn on the service bound to a transaction. A service already bound to one joins it, otherwise
+// the unit of work begins a new one with opts, so that every mutation is atomic on its own and within a larger one.
+func (a AccountServiceImpl) inTx(ctx context.Context, opts *sql.TxOptions, fn func(AccountServiceImpl) error) error {
+	if a.tx != nil {
+		return fn(a)
 	}
+	err := a.unitOfWork.RunInTx(ctx, opts, func(tx *gorm.DB) error {
+		return fn(a.withTrx(tx))
+	})
+	if KindOf(err) == KindInternal {
+		// the memory repositories check the debits again at commit and may refuse it
+		err = insufficientFunds(err)
+	}
+	return err
 }
 
-// WithTrx enables repository with transaction
-func (a AccountServiceImpl) WithTrx(trxHandle *gorm.DB) AccountServiceImpl {
-	logger.Log.Info("In func() WithTrx :: SERVICE LAYER")
+// isolated returns the options of the transactions moving money, nil keeps the default of the database
+func (a AccountServiceImpl) isolated() *sql.TxOptions {
+	if a.isolation == sql.LevelDefault {
+		return nil
+	}
+	return &sql.TxOptions{Isolation: a.isolation}
+}
+
+// withTrx binds the repositories to the transaction, audit and outbox events are written in the same transaction
+func (a AccountServiceImpl) withTrx(trxHandle *gorm.DB) AccountServiceImpl {
+	a.tx = trxHandle
 	a.accountRepository = a.accountRepository.WithTrx(trxHandle)
+	a.transferRepository = a.transferRepository.WithTrx(trxHandle)
+	a.entryRepository = a.entryRepository.WithTrx(trxHandle)
+	a.auditRepository = a.auditRepository.WithTrx(trxHandle)
+	a.outboxRepository = a.outboxRepository.WithTrx(trxHandle)
 	return a
 }
 
-func (a AccountServiceImpl) SaveAccount(account models.Account) (models.Account, error) {
-	logger.Log.Info("In func() SaveAccount :: SERVICE LAYER")
-	return a.accountRepository.SaveAccount(account)
+// publishEvent writes a domain event about an account of owner to the outbox, the relay delivers it once the
+// transaction commits
+func (a AccountServiceImpl) publishEvent(eventType string, accountID int, owner string, payload interface{}) error {
+	event, err := outbox.NewEvent(eventType, accountID, payload)
+	if err != nil {
+		return err
+	}
+	event.Owner = owner
+	return a.outboxRepository.SaveOutboxEvent(event)
 }
 
-func (a AccountServiceImpl) GetAll(pageId int, pageSize int) ([]models.Account, error) {
-	logger.Log.Info("In func() GetAll :: SERVICE LAYER")
-	return a.accountRepository.GetAll(pageId, pageSize)
+// recordAudit appends an audit event with JSON snapshots of the entity before and after the change
+func (a AccountServiceImpl) recordAudit(operation string, entityType string, entityID int, before interface{}, after interface{}) error {
+	event := &models.AuditEvent{Operation: operation, EntityType: entityType, EntityID: entityID}
+	var err error
+	if before != nil {
+		if event.Before, err = json.Marshal(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if event.After, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+	return a.auditRepository.SaveAuditEvent(event)
 }
 
-func (a AccountServiceImpl) GetAccountById(id int) (models.Account, error) {
-	logger.Log.Info("In func() GetAccountById :: SERVICE LAYER")
-	return a.accountRepository.GetAccountById(id)
+func (a AccountServiceImpl) SaveAccount(ctx context.Context, account models.Account) (saved models.Account, err error) {
+	logger.FromContext(ctx).Info("In func() SaveAccount :: SERVICE LAYER")
+	err = a.inTx(ctx, nil, func(tx AccountServiceImpl) (err error) {
+		saved, err = tx.saveAccount(ctx, account)
+		return err
+	})
+	return saved, err
 }
 
-func (a AccountServiceImpl) DeleteAccountById(id int) error {
-	logger.Log.Info("In func() DeleteAccountById :: SERVICE LAYER")
-	return a.accountRepository.DeleteAccountById(id)
+func (a AccountServiceImpl) saveAccount(ctx context.Context, account models.Account) (models.Account, error) {
+	if err := authorize(ctx, a.policy, ActionOpenAccount, account.Owner); err != nil {
+		return account, err
+	}
+	// every API opens accounts through here, they all accept the same currencies
+	if !util.IsSupportedCurrency(account.Currency) {
+		return account, fmt.Errorf("%w: currency %q is not supported", ErrInvalidRequest, account.Currency)
+	}
+	if account.Status == "" {
+		account.Status = models.AccountStatusActive
+	}
+	account, err := a.accountRepository.SaveAccount(ctx, account)
+	if err != nil {
+		return account, err
+	}
+	if err = a.recordAudit(models.AuditAccountCreated, models.AuditEntityAccount, account.Id, nil, account); err != nil {
+		return account, err
+	}
+	return account, a.publishEvent(outbox.AccountCreated, account.Id, account.Owner, outbox.NewAccountPayload(account))
 }
 
-func (a AccountServiceImpl) UpdateAccountById(originalAccount models.Account, changedAccount models.Account) (models.Account, error) {
-	logger.Log.Info("In func() UpdateAccountById :: SERVICE LAYER")
-	return a.accountRepository.UpdateAccountById(originalAccount, changedAccount)
+// GetAll returns one page of accounts, either after a cursor or at a page id
+func (a AccountServiceImpl) GetAll(ctx context.Context, req *request.ListAccountsRequest) (models.AccountPage, error) {
+	logger.FromContext(ctx).Info("In func() GetAll :: SERVICE LAYER")
+	page := models.AccountPage{Data: []models.Account{}}
+	query, err := newAccountQuery(req)
+	if err != nil {
+		return page, err
+	}
+	// customers only see their own accounts
+	owner, err := a.policy.Scope(ctx, ActionReadAccount)
+	if err != nil {
+		return page, err
+	}
+	if owner != "" {
+		if req.Owner != "" && req.Owner != owner {
+			return page, fmt.Errorf("%w: cannot list the accounts of %s", ErrForbidden, req.Owner)
+		}
+		query.Owner = owner
+	}
+	pageSize := query.Limit
+	// one extra row tells whether there is a next page
+	query.Limit++
+	accounts, err := a.accountRepository.GetAll(ctx, query)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return page, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+	if err != nil {
+		return page, err
+	}
+	if len(accounts) > pageSize {
+		accounts = accounts[:pageSize]
+		if req.PageID > 0 {
+			page.NextPageID = req.PageID + 1
+		} else {
+			sort := req.Sort
+			if sort == "" {
+				sort = defaultSort
+			}
+			page.NextCursor = repository.NewAccountCursor(accounts[pageSize-1], sort, query.SortColumn).Encode()
+		}
+	}
+	if accounts != nil {
+		page.Data = accounts
+	}
+	if req.IncludeTotal {
+		total, err := a.accountRepository.CountAll(ctx, query)
+		if err != nil {
+			return page, err
+		}
+		page.Total = &total
+	}
+	return page, nil
 }
 
-func (a AccountServiceImpl) SaveTransfer(req *request.TransferRequest) (models.Transfer, error) {
-	logger.Log.Info("In func() SaveTransfer :: SERVICE LAYER")
+// newAccountQuery validates the listing options and turns them into a repository query
+func newAccountQuery(req *request.ListAccountsRequest) (repository.AccountQuery, error) {
+	query := repository.AccountQuery{
+		Owner:       req.Owner,
+		Currency:    req.Currency,
+		Status:      req.Status,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		Limit:       req.PageSize,
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultPageSize
+	}
+	if req.Currency != "" && !util.IsSupportedCurrency(req.Currency) {
+		return query, fmt.Errorf("%w: currency %q is not supported", ErrInvalidQuery, req.Currency)
+	}
+	if req.Status != "" && req.Status != models.AccountStatusActive && req.Status != models.AccountStatusClosed {
+		return query, fmt.Errorf("%w: status %q is not supported", ErrInvalidQuery, req.Status)
+	}
+	sort := req.Sort
+	if sort == "" {
+		sort = defaultSort
+	}
+	column, ok := sortableAccountColumns[strings.TrimPrefix(sort, "-")]
+	if !ok {
+		return query, fmt.Errorf("%w: cannot sort by %q", ErrInvalidQuery, sort)
+	}
+	query.SortColumn = column
+	query.SortDesc = strings.HasPrefix(sort, "-")
+
+	if req.Cursor != "" && req.PageID > 0 {
+		return query, fmt.Errorf("%w: cursor and page_id cannot be combined", ErrInvalidQuery)
+	}
+	if req.PageID > 0 {
+		query.Offset = (req.PageID - 1) * query.Limit
+	}
+	if req.Cursor != "" {
+		cursor, err := repository.DecodeCursor(req.Cursor)
+		if err != nil {
+			return query, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+		}
+		if cursor.Sort != sort {
+			return query, fmt.Errorf("%w: cursor was issued for sort %q", ErrInvalidQuery, cursor.Sort)
+		}
+		query.After = cursor
+	}
+	return query, nil
+}
+
+func (a AccountServiceImpl) GetAccountById(ctx context.Context, id int) (models.Account, error) {
+	ctx = logger.WithFields(ctx, "account_id", id)
+	logger.FromContext(ctx).Info("In func() GetAccountById :: SERVICE LAYER")
+	account, err := a.accountRepository.GetAccountById(ctx, id)
+	if err != nil {
+		return account, notFound(err, ErrAccountNotFound)
+	}
+	return account, authorize(ctx, a.policy, ActionReadAccount, account.Owner)
+}
+
+func (a AccountServiceImpl) DeleteAccountById(ctx context.Context, id int) error {
+	ctx = logger.WithFields(ctx, "account_id", id)
+	logger.FromContext(ctx).Info("In func() DeleteAccountById :: SERVICE LAYER")
+	return a.inTx(ctx, nil, func(tx AccountServiceImpl) error {
+		return tx.deleteAccountById(ctx, id)
+	})
+}
+
+func (a AccountServiceImpl) deleteAccountById(ctx context.Context, id int) error {
+	if err := authorize(ctx, a.policy, ActionCloseAccount); err != nil {
+		return err
+	}
+	account, err := a.accountRepository.GetAccountById(ctx, id)
+	if err != nil {
+		return notFound(err, ErrAccountNotFound)
+	}
+	if err = a.accountRepository.DeleteAccountById(ctx, id); err != nil {
+		return err
+	}
+	if err = a.recordAudit(models.AuditAccountDeleted, models.AuditEntityAccount, id, account, nil); err != nil {
+		return err
+	}
+	return a.publishEvent(outbox.AccountDeleted, id, account.Owner, outbox.AccountDeletedPayload{AccountID: id})
+}
+
+func (a AccountServiceImpl) UpdateAccountById(ctx context.Context, originalAccount models.Account, changedAccount models.Account) (account models.Account, err error) {
+	ctx = logger.WithFields(ctx, "account_id", originalAccount.Id)
+	logger.FromContext(ctx).Info("In func() UpdateAccountById :: SERVICE LAYER")
+	err = a.inTx(ctx, nil, func(tx AccountServiceImpl) (err error) {
+		account, err = tx.updateAccountById(ctx, originalAccount, changedAccount)
+		return err
+	})
+	return account, err
+}
+
+func (a AccountServiceImpl) updateAccountById(ctx context.Context, originalAccount models.Account, changedAccount models.Account) (models.Account, error) {
+	if err := authorize(ctx, a.policy, ActionUpdateAccount); err != nil {
+		return originalAccount, err
+	}
+	if changedAccount.Currency != "" && !util.IsSupportedCurrency(changedAccount.Currency) {
+		return originalAccount, fmt.Errorf("%w: currency %q is not supported", ErrInvalidRequest, changedAccount.Currency)
+	}
+	// the account read by the caller may be stale, the audit needs the state the transaction changes
+	originalAccount, err := a.accountRepository.GetAccountById(ctx, originalAccount.Id)
+	if err != nil {
+		return originalAccount, notFound(err, ErrAccountNotFound)
+	}
+	// Updates skips zero values, an empty currency leaves it unchanged
+	if changedAccount.Currency != "" && changedAccount.Currency != originalAccount.Currency && originalAccount.Balance != 0 {
+		return originalAccount, fmt.Errorf("%w: currency can only be changed on an account with zero balance", ErrInvalidRequest)
+	}
+	updatedAccount, err := a.accountRepository.UpdateAccountById(ctx, originalAccount, changedAccount)
+	if err != nil {
+		return updatedAccount, err
+	}
+	err = a.recordAudit(models.AuditAccountUpdated, models.AuditEntityAccount, originalAccount.Id,
+		originalAccount, updatedAccount)
+	if err != nil {
+		return updatedAccount, err
+	}
+	// Updates skips zero values, so the event is built from the original account with the changes applied
+	current := originalAccount
+	current.Owner, current.Currency = updatedAccount.Owner, updatedAccount.Currency
+	return updatedAccount, a.publishEvent(outbox.AccountUpdated, originalAccount.Id, current.Owner, outbox.NewAccountPayload(current))
+}
+
+// PatchAccountById applies a RFC 7396 merge patch to the allowlisted fields of an account
+func (a AccountServiceImpl) PatchAccountById(ctx context.Context, account models.Account, patch map[string]json.RawMessage) (patched models.Account, err error) {
+	ctx = logger.WithFields(ctx, "account_id", account.Id)
+	logger.FromContext(ctx).Info("In func() PatchAccountById :: SERVICE LAYER")
+	err = a.inTx(ctx, nil, func(tx AccountServiceImpl) (err error) {
+		patched, err = tx.patchAccountById(ctx, account, patch)
+		return err
+	})
+	return patched, err
+}
+
+func (a AccountServiceImpl) patchAccountById(ctx context.Context, account models.Account, patch map[string]json.RawMessage) (models.Account, error) {
+	if err := authorize(ctx, a.policy, ActionUpdateAccount); err != nil {
+		return account, err
+	}
+	// the account read by the caller may be stale, the audit needs the state the transaction changes
+	account, err := a.accountRepository.GetAccountById(ctx, account.Id)
+	if err != nil {
+		return account, notFound(err, ErrAccountNotFound)
+	}
+	changes := make(map[string]interface{}, len(patch))
+	for field, raw := range patch {
+		column, ok := patchableAccountFields[field]
+		if !ok {
+			return account, fmt.Errorf("%w: field %q cannot be patched", ErrInvalidPatch, field)
+		}
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			return account, fmt.Errorf("%w: field %q cannot be removed", ErrInvalidPatch, field)
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return account, fmt.Errorf("%w: field %q must be a string", ErrInvalidPatch, field)
+		}
+		if value == "" {
+			return account, fmt.Errorf("%w: field %q must not be empty", ErrInvalidPatch, field)
+		}
+		changes[column] = value
+	}
+	if currency, ok := changes["currency"]; ok && currency != account.Currency {
+		if !util.IsSupportedCurrency(currency.(string)) {
+			return account, fmt.Errorf("%w: currency %q is not supported", ErrInvalidPatch, currency)
+		}
+		if account.Balance != 0 {
+			return account, fmt.Errorf("%w: currency can only be changed on an account with zero balance", ErrInvalidPatch)
+		}
+	}
+	if len(changes) == 0 {
+		return account, nil
+	}
+	patchedAccount, err := a.accountRepository.PatchAccountById(ctx, account, changes)
+	if err != nil {
+		return patchedAccount, err
+	}
+	err = a.recordAudit(models.AuditAccountUpdated, models.AuditEntityAccount, account.Id, account, patchedAccount)
+	if err != nil {
+		return patchedAccount, err
+	}
+	return patchedAccount, a.publishEvent(outbox.AccountUpdated, account.Id, patchedAccount.Owner, outbox.NewAccountPayload(patchedAccount))
+}
+
+// SetTransferLimit changes the largest amount of one transfer out of an account, 0 removes the limit
+func (a AccountServiceImpl) SetTransferLimit(ctx context.Context, id int, req *request.TransferLimitRequest) (account models.Account, err error) {
+	ctx = logger.WithFields(ctx, "account_id", id)
+	logger.FromContext(ctx).Info("In func() SetTransferLimit :: SERVICE LAYER")
+	err = a.inTx(ctx, nil, func(tx AccountServiceImpl) (err error) {
+		account, err = tx.setTransferLimit(ctx, id, req)
+		return err
+	})
+	return account, err
+}
+
+func (a AccountServiceImpl) setTransferLimit(ctx context.Context, id int, req *request.TransferLimitRequest) (models.Account, error) {
+	if err := authorize(ctx, a.policy, ActionChangeLimits); err != nil {
+		return models.Account{}, err
+	}
+	if req.Limit == nil || *req.Limit < 0 {
+		return models.Account{}, fmt.Errorf("%w: limit must be zero or positive", ErrInvalidRequest)
+	}
+	account, err := a.accountRepository.GetAccountById(ctx, id)
+	if err != nil {
+		return account, notFound(err, ErrAccountNotFound)
+	}
+	limited, err := a.accountRepository.PatchAccountById(ctx, account, map[string]interface{}{"transfer_limit": *req.Limit})
+	if err != nil {
+		return limited, err
+	}
+	return limited, a.recordAudit(models.AuditAccountUpdated, models.AuditEntityAccount, id, account, limited)
+}
+
+// AdjustBalance changes the balance of an account and posts a ledger entry carrying the reason code
+func (a AccountServiceImpl) AdjustBalance(ctx context.Context, id int, req *request.BalanceAdjustmentRequest) (entry models.Entry, err error) {
+	ctx = logger.WithFields(ctx, "account_id", id, "amount", req.Amount, "reason_code", req.ReasonCode)
+	logger.FromContext(ctx).Info("In func() AdjustBalance :: SERVICE LAYER")
+	err = a.inTx(ctx, a.isolated(), func(tx AccountServiceImpl) (err error) {
+		entry, err = tx.adjustBalance(ctx, id, req)
+		return err
+	})
+	return entry, err
+}
+
+func (a AccountServiceImpl) adjustBalance(ctx context.Context, id int, req *request.BalanceAdjustmentRequest) (models.Entry, error) {
+	if err := authorize(ctx, a.policy, ActionAdjustBalance); err != nil {
+		return models.Entry{}, err
+	}
+	if !util.IsSupportedReasonCode(req.ReasonCode) {
+		return models.Entry{}, fmt.Errorf("%w: reason code %q is not supported", ErrInvalidAdjustment, req.ReasonCode)
+	}
+	if req.Amount == 0 {
+		return models.Entry{}, fmt.Errorf("%w: amount must not be zero", ErrInvalidAdjustment)
+	}
+	account, err := a.accountRepository.GetAccountById(ctx, id)
+	if err != nil {
+		return models.Entry{}, notFound(err, ErrAccountNotFound)
+	}
+	if account.Balance+req.Amount < 0 {
+		return models.Entry{}, fmt.Errorf("%w: balance cannot become negative", ErrInsufficientFunds)
+	}
+	entry := &models.Entry{AccountID: id, Amount: req.Amount, ReasonCode: req.ReasonCode}
+	if err = a.entryRepository.SaveEntry(ctx, entry); err != nil {
+		return models.Entry{}, err
+	}
+	// debits are checked again by the repository, the balance may have moved since it was read
+	if req.Amount < 0 {
+		err = a.accountRepository.DecrementBalance(ctx, id, -req.Amount)
+	} else {
+		err = a.accountRepository.IncrementBalance(ctx, id, req.Amount)
+	}
+	if err != nil {
+		return models.Entry{}, insufficientFunds(err)
+	}
+	adjustedAccount := account
+	adjustedAccount.Balance += req.Amount
+	if err = a.recordAudit(models.AuditBalanceAdjusted, models.AuditEntityAccount, id, account, adjustedAccount); err != nil {
+		return models.Entry{}, err
+	}
+	err = a.publishEvent(outbox.BalanceAdjusted, id, account.Owner, outbox.BalanceAdjustedPayload{
+		AccountID:  id,
+		EntryID:    entry.Id,
+		Amount:     entry.Amount,
+		ReasonCode: entry.ReasonCode,
+		Balance:    adjustedAccount.Balance,
+	})
+	if err != nil {
+		return models.Entry{}, err
+	}
+	return *entry, nil
+}
+
+func (a AccountServiceImpl) SaveTransfer(ctx context.Context, req *request.TransferRequest) (transfer models.Transfer, err error) {
+	ctx = logger.WithFields(ctx, "from_account_id", req.FromAccountID, "to_account_id", req.ToAccountID, "amount", req.Amount,
+		"currency", req.Currency)
+	logger.FromContext(ctx).Info("In func() SaveTransfer :: SERVICE LAYER")
+	err = a.inTx(ctx, a.isolated(), func(tx AccountServiceImpl) (err error) {
+		transfer, err = tx.saveTransfer(ctx, req)
+		return err
+	})
+	if err == nil {
+		logger.FromContext(ctx).With("transfer_id", transfer.Id).Info("transfer completed")
+	}
+	return transfer, err
+}
+
+func (a AccountServiceImpl) saveTransfer(ctx context.Context, req *request.TransferRequest) (models.Transfer, error) {
+	if err := authorize(ctx, a.policy, ActionPostLedger); err != nil {
+		return models.Transfer{}, err
+	}
+	// the ledger is posted as told, the accounts are only read for the owners of the events
+	accounts, err := a.accountRepository.GetAccountsByIds(ctx, []int{req.FromAccountID, req.ToAccountID})
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	owners := make(map[int]string, len(accounts))
+	for _, account := range accounts {
+		owners[account.Id] = account.Owner
+	}
+	return a.recordTransfer(ctx, req, owners)
+}
+
+// recordTransfer saves the transfer with its audit and outbox events, without moving money. owners maps the
+// ids of the accounts of the transfer to their owners.
+func (a AccountServiceImpl) recordTransfer(ctx context.Context, req *request.TransferRequest, owners map[int]string) (models.Transfer, error) {
 	transfer := &models.Transfer{}
 	mapper.Mapper(req, transfer)
-	return a.accountRepository.SaveTransfer(transfer)
+	savedTransfer, err := a.transferRepository.SaveTransfer(ctx, transfer)
+	if err != nil {
+		return savedTransfer, err
+	}
+	err = a.recordAudit(models.AuditTransferCreated, models.AuditEntityTransfer, savedTransfer.Id, nil, savedTransfer)
+	if err != nil {
+		return savedTransfer, err
+	}
+	// the entries and balance updates of the transfer run in the same transaction, if any of them
+	// fails the events are rolled back together with the transfer
+	payload := outbox.TransferCompletedPayload{
+		TransferID:    savedTransfer.Id,
+		FromAccountID: savedTransfer.FromAccountID,
+		ToAccountID:   savedTransfer.ToAccountID,
+		Amount:        savedTransfer.Amount,
+		CreatedAt:     savedTransfer.CreatedAt,
+	}
+	// events are ordered per account, so each account of the transfer gets its own event
+	for _, accountID := range []int{savedTransfer.FromAccountID, savedTransfer.ToAccountID} {
+		if err = a.publishEvent(outbox.TransferCompleted, accountID, owners[accountID], payload); err != nil {
+			return savedTransfer, err
+		}
+	}
+	return savedTransfer, nil
+}
+
+// CreateTransfer records the transfer, both ledger entries and the balance changes in one transaction,
+// a failing step rolls back the others
+func (a AccountServiceImpl) CreateTransfer(ctx context.Context, req *request.TransferRequest) (transfer models.Transfer, err error) {
+	ctx = logger.WithFields(ctx, "from_account_id", req.FromAccountID, "to_account_id", req.ToAccountID, "amount", req.Amount,
+		"currency", req.Currency)
+	logger.FromContext(ctx).Info("In func() CreateTransfer :: SERVICE LAYER")
+	err = a.inTx(ctx, a.isolated(), func(tx AccountServiceImpl) (err error) {
+		transfer, err = tx.createTransfer(ctx, req)
+		return err
+	})
+	if err == nil {
+		logger.FromContext(ctx).With("transfer_id", transfer.Id).Info("transfer completed")
+	}
+	return transfer, err
+}
+
+func (a AccountServiceImpl) createTransfer(ctx context.Context, req *request.TransferRequest) (models.Transfer, error) {
+	from, to, err := a.checkTransfer(ctx, req)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	// the steps are authorized as a whole by checkTransfer, the exported ones are reserved to tellers
+	transfer, err := a.recordTransfer(ctx, req, map[int]string{from.Id: from.Owner, to.Id: to.Owner})
+	if err != nil {
+		return transfer, fmt.Errorf("saving transfer: %w", err)
+	}
+	if err = a.saveEntry(ctx, req, "DEBIT"); err != nil {
+		return transfer, fmt.Errorf("saving entry for debited account: %w", err)
+	}
+	if err = a.saveEntry(ctx, req, "CREDIT"); err != nil {
+		return transfer, fmt.Errorf("saving entry for credited account: %w", err)
+	}
+	if err = a.accountRepository.DecrementBalance(ctx, req.FromAccountID, req.Amount); err != nil {
+		return transfer, fmt.Errorf("decrementing balance of sender account: %w", insufficientFunds(err))
+	}
+	if err = a.accountRepository.IncrementBalance(ctx, req.ToAccountID, req.Amount); err != nil {
+		return transfer, fmt.Errorf("incrementing balance of receiver account: %w", err)
+	}
+	return transfer, nil
+}
+
+// checkTransfer rejects transfers between unknown accounts, out of an account the caller may not debit,
+// between accounts of different currencies, above the limit of the sender or from an account that cannot
+// cover the amount, and returns the sender and receiver accounts
+func (a AccountServiceImpl) checkTransfer(ctx context.Context, req *request.TransferRequest) (from, to models.Account, err error) {
+	if req.Amount <= 0 {
+		return from, to, fmt.Errorf("%w: amount must be positive", ErrInvalidTransfer)
+	}
+	if req.FromAccountID == req.ToAccountID {
+		return from, to, fmt.Errorf("%w: cannot transfer to the same account", ErrInvalidTransfer)
+	}
+	accounts, err := a.accountRepository.GetAccountsByIds(ctx, []int{req.FromAccountID, req.ToAccountID})
+	if err != nil {
+		return from, to, err
+	}
+	byId := make(map[int]models.Account, len(accounts))
+	for _, account := range accounts {
+		byId[account.Id] = account
+	}
+	var ok bool
+	if from, ok = byId[req.FromAccountID]; !ok {
+		return from, to, fmt.Errorf("%w: sender account %d does not exist", ErrAccountNotFound, req.FromAccountID)
+	}
+	if to, ok = byId[req.ToAccountID]; !ok {
+		return from, to, fmt.Errorf("%w: receiver account %d does not exist", ErrAccountNotFound, req.ToAccountID)
+	}
+	if err = authorize(ctx, a.policy, ActionTransfer, from.Owner); err != nil {
+		return from, to, err
+	}
+	if from.Currency != to.Currency || (req.Currency != "" && req.Currency != from.Currency) {
+		return from, to, fmt.Errorf("%w: currencies of the accounts and the transfer must match", ErrInvalidTransfer)
+	}
+	if from.TransferLimit > 0 && req.Amount > from.TransferLimit {
+		return from, to, fmt.Errorf("%w: transfers out of account %d are limited to %.2f", ErrLimitExceeded, from.Id, from.TransferLimit)
+	}
+	if from.Balance < req.Amount {
+		return from, to, fmt.Errorf("%w: balance of account %d is lower than %.2f", ErrInsufficientFunds, from.Id, req.Amount)
+	}
+	return from, to, nil
+}
+
+// GetEntries returns the ledger entries of an account, oldest first
+func (a AccountServiceImpl) GetEntries(ctx context.Context, accountID int) ([]models.Entry, error) {
+	ctx = logger.WithFields(ctx, "account_id", accountID)
+	logger.FromContext(ctx).Info("In func() GetEntries :: SERVICE LAYER")
+	account, err := a.accountRepository.GetAccountById(ctx, accountID)
+	if err != nil {
+		return nil, notFound(err, ErrAccountNotFound)
+	}
+	if err = authorize(ctx, a.policy, ActionReadAccount, account.Owner); err != nil {
+		return nil, err
+	}
+	entries, err := a.entryRepository.GetEntriesByAccountId(ctx, accountID)
+	if entries == nil {
+		entries = []models.Entry{}
+	}
+	return entries, err
+}
+
+// GetAccountsByIds returns the accounts of ids the caller may read, the others are left out
+func (a AccountServiceImpl) GetAccountsByIds(ctx context.Context, ids []int) ([]models.Account, error) {
+	logger.FromContext(ctx).Info("In func() GetAccountsByIds :: SERVICE LAYER")
+	owner, err := a.policy.Scope(ctx, ActionReadAccount)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := a.accountRepository.GetAccountsByIds(ctx, ids)
+	if err != nil || owner == "" {
+		return accounts, err
+	}
+	owned := accounts[:0]
+	for _, account := range accounts {
+		if account.Owner == owner {
+			owned = append(owned, account)
+		}
+	}
+	return owned, nil
+}
+
+// readableIds returns the ids of the accounts the caller may read
+func (a AccountServiceImpl) readableIds(ctx context.Context, ids []int) ([]int, error) {
+	owner, err := a.policy.Scope(ctx, ActionReadAccount)
+	if err != nil || owner == "" {
+		return ids, err
+	}
+	accounts, err := a.GetAccountsByIds(ctx, ids)
+	readable := make([]int, 0, len(accounts))
+	for _, account := range accounts {
+		readable = append(readable, account.Id)
+	}
+	return readable, err
 }
 
-func (a AccountServiceImpl) SaveEntry(req *request.TransferRequest, dc string) error {
-	logger.Log.Info("In func() SaveEntry :: SERVICE LAYER")
+// GetTransferById returns a transfer, customers may only read the transfers of the accounts they own
+func (a AccountServiceImpl) GetTransferById(ctx context.Context, id int) (models.Transfer, error) {
+	ctx = logger.WithFields(ctx, "transfer_id", id)
+	logger.FromContext(ctx).Info("In func() GetTransferById :: SERVICE LAYER")
+	transfer, err := a.transferRepository.GetTransferById(ctx, id)
+	if err != nil {
+		return transfer, notFound(err, ErrTransferNotFound)
+	}
+	readable, err := a.readableIds(ctx, []int{transfer.FromAccountID, transfer.ToAccountID})
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	if len(readable) == 0 {
+		return models.Transfer{}, fmt.Errorf("%w: transfer %d is not between accounts of the caller", ErrForbidden, id)
+	}
+	return transfer, nil
+}
+
+// GetRecentTransfers returns up to limit transfers per account, newest first, for several accounts at once
+func (a AccountServiceImpl) GetRecentTransfers(ctx context.Context, accountIDs []int, limit int, beforeID int) (map[int][]models.Transfer, error) {
+	logger.FromContext(ctx).Info("In func() GetRecentTransfers :: SERVICE LAYER")
+	accountIDs, err := a.readableIds(ctx, accountIDs)
+	if err != nil {
+		return nil, err
+	}
+	return a.transferRepository.GetRecentTransfers(ctx, accountIDs, limit, beforeID)
+}
+
+// GetRecentEntries returns up to limit entries per account, newest first, for several accounts at once
+func (a AccountServiceImpl) GetRecentEntries(ctx context.Context, accountIDs []int, limit int, beforeID int) (map[int][]models.Entry, error) {
+	logger.FromContext(ctx).Info("In func() GetRecentEntries :: SERVICE LAYER")
+	accountIDs, err := a.readableIds(ctx, accountIDs)
+	if err != nil {
+		return nil, err
+	}
+	return a.entryRepository.GetRecentEntries(ctx, accountIDs, limit, beforeID)
+}
+
+// Reconcile checks every account balance against its ledger entries and returns the accounts that disagree
+func (a AccountServiceImpl) Reconcile(ctx context.Context) ([]models.BalanceMismatch, error) {
+	logger.FromContext(ctx).Info("In func() Reconcile :: SERVICE LAYER")
+	if err := authorize(ctx, a.policy, ActionReconcile); err != nil {
+		return nil, err
+	}
+	return a.entryRepository.GetBalanceMismatches(ctx)
+}
+
+func (a AccountServiceImpl) SaveEntry(ctx context.Context, req *request.TransferRequest, dc string) error {
+	logger.FromContext(ctx).Info("In func() SaveEntry :: SERVICE LAYER")
+	if err := authorize(ctx, a.policy, ActionPostLedger); err != nil {
+		return err
+	}
+	return a.saveEntry(ctx, req, dc)
+}
+
+func (a AccountServiceImpl) saveEntry(ctx context.Context, req *request.TransferRequest, dc string) error {
 	entry := &models.Entry{}
 	if dc == "DEBIT" {
 		(*entry).AccountID = req.FromAccountID
@@ -87,16 +784,24 @@ func (a AccountServiceImpl) SaveEntry(req *request.TransferRequest, dc string) e
 		(*entry).AccountID = req.ToAccountID
 		(*entry).Amount = req.Amount
 	}
-	err := a.accountRepository.SaveEntry(entry)
+	err := a.entryRepository.SaveEntry(ctx, entry)
 	return err
 }
 
-func (a AccountServiceImpl) IncrementBalance(receiver int, amount float64) error {
-	logger.Log.Info("In func() IncrementBalance :: SERVICE LAYER")
-	return a.accountRepository.IncrementBalance(receiver, amount)
+func (a AccountServiceImpl) IncrementBalance(ctx context.Context, receiver int, amount float64) error {
+	ctx = logger.WithFields(ctx, "account_id", receiver, "amount", amount)
+	logger.FromContext(ctx).Info("In func() IncrementBalance :: SERVICE LAYER")
+	if err := authorize(ctx, a.policy, ActionPostLedger); err != nil {
+		return err
+	}
+	return a.accountRepository.IncrementBalance(ctx, receiver, amount)
 }
 
-func (a AccountServiceImpl) DecrementBalance(giver int, amount float64) error {
-	logger.Log.Info("In func() DecrementBalance :: SERVICE LAYER")
-	return a.accountRepository.DecrementBalance(giver, amount)
+func (a AccountServiceImpl) DecrementBalance(ctx context.Context, giver int, amount float64) error {
+	ctx = logger.WithFields(ctx, "account_id", giver, "amount", amount)
+	logger.FromContext(ctx).Info("In func() DecrementBalance :: SERVICE LAYER")
+	if err := authorize(ctx, a.policy, ActionPostLedger); err != nil {
+		return err
+	}
+	return a.accountRepository.DecrementBalance(ctx, giver, amount)
 }
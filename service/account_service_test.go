@@ -1,146 +1,659 @@
 package service_test
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/auth"
 	"github.com/rahul-024/fund-transfer-poc/logger"
 	mock "github.com/rahul-024/fund-transfer-poc/mocks"
 
 	"github.com/rahul-024/fund-transfer-poc/models"
 	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/outbox"
 	"github.com/rahul-024/fund-transfer-poc/repository"
 	"github.com/rahul-024/fund-transfer-poc/service"
+	"gopkg.in/go-playground/assert.v1"
 	"gorm.io/gorm"
 )
 
 func TestSaveAccount(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
+	expectTx(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo)
 	mockLogger := mock.NewMockLogger(mockCtrl)
 	logger.SetLogger(mockLogger)
 	mockLogger.EXPECT().Info("In func() SaveAccount :: SERVICE LAYER")
 	account := models.Account{Currency: "USD", Owner: "rahul", Balance: 24}
-	mockAccountRepo.EXPECT().SaveAccount(account).Return(models.Account{Currency: "USD", Owner: "rahul", Balance: 24}, nil).Times(1)
-	accountServiceImpl := service.NewAccountService(mockAccountRepo)
-	accountServiceImpl.SaveAccount(account)
+	mockAccountRepo.EXPECT().SaveAccount(gomock.Any(), models.Account{Currency: "USD", Owner: "rahul", Balance: 24, Status: "ACTIVE"}).
+		Return(models.Account{Id: 3, Currency: "USD", Owner: "rahul", Balance: 24, Status: "ACTIVE"}, nil).Times(1)
+	mockAuditRepo.EXPECT().SaveAuditEvent(gomock.Any()).DoAndReturn(func(event *models.AuditEvent) error {
+		assert.Equal(t, event.Operation, models.AuditAccountCreated)
+		assert.Equal(t, event.EntityID, 3)
+		assert.Equal(t, len(event.Before), 0)
+		return nil
+	}).Times(1)
+	mockOutboxRepo.EXPECT().SaveOutboxEvent(gomock.Any()).DoAndReturn(func(event *models.OutboxEvent) error {
+		assert.Equal(t, event.EventType, outbox.AccountCreated)
+		assert.Equal(t, event.SchemaVersion, 1)
+		assert.Equal(t, event.AggregateID, 3)
+		return nil
+	}).Times(1)
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+	accountServiceImpl.SaveAccount(asSystem(), account)
+
+	//the currencies are checked for every API
+	mockLogger.EXPECT().Info("In func() SaveAccount :: SERVICE LAYER")
+	_, err := accountServiceImpl.SaveAccount(asSystem(), models.Account{Currency: "XYZ", Owner: "rahul"})
+	if !errors.Is(err, service.ErrInvalidRequest) {
+		t.Errorf("Expected ErrInvalidRequest, got %v", err)
+	}
 }
 
 func TestGetAll(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
 	mockLogger := mock.NewMockLogger(mockCtrl)
 	logger.SetLogger(mockLogger)
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+	accounts := []models.Account{{Id: 4, Owner: "a"}, {Id: 5, Owner: "b"}, {Id: 6, Owner: "c"}}
+
+	//offset mode computes the offset from the page id
+	mockLogger.EXPECT().Info("In func() GetAll :: SERVICE LAYER")
+	mockAccountRepo.EXPECT().GetAll(gomock.Any(), repository.AccountQuery{SortColumn: "id", Limit: 3, Offset: 2}).
+		Return(accounts, nil).Times(1)
+	page, err := accountServiceImpl.GetAll(asSystem(), &request.ListAccountsRequest{PageID: 2, PageSize: 2})
+	if err != nil || len(page.Data) != 2 || page.NextPageID != 3 || page.NextCursor != "" {
+		t.Errorf("Unexpected page: %+v, %v", page, err)
+	}
+
+	//cursor mode hands out a cursor after the last row and accepts it back
 	mockLogger.EXPECT().Info("In func() GetAll :: SERVICE LAYER")
-	mockAccountRepo.EXPECT().GetAll(0, 5).Return(nil, nil).Times(1)
-	accountServiceImpl := service.NewAccountService(mockAccountRepo)
-	accountServiceImpl.GetAll(0, 5)
+	mockAccountRepo.EXPECT().GetAll(gomock.Any(), repository.AccountQuery{SortColumn: "owner", SortDesc: true, Limit: 3}).
+		Return(accounts, nil).Times(1)
+	mockAccountRepo.EXPECT().CountAll(gomock.Any(), gomock.Any()).Return(int64(9), nil).Times(1)
+	page, err = accountServiceImpl.GetAll(asSystem(), &request.ListAccountsRequest{PageSize: 2, Sort: "-owner", IncludeTotal: true})
+	if err != nil || page.NextCursor == "" || *page.Total != 9 {
+		t.Errorf("Unexpected page: %+v, %v", page, err)
+	}
+	cursor, _ := repository.DecodeCursor(page.NextCursor)
+	assert.Equal(t, *cursor, repository.Cursor{Sort: "-owner", Value: "b", Id: 5})
+
+	mockLogger.EXPECT().Info("In func() GetAll :: SERVICE LAYER")
+	mockAccountRepo.EXPECT().GetAll(gomock.Any(), repository.AccountQuery{SortColumn: "owner", SortDesc: true, Limit: 3, After: cursor}).
+		Return(accounts[2:], nil).Times(1)
+	page, err = accountServiceImpl.GetAll(asSystem(), &request.ListAccountsRequest{PageSize: 2, Sort: "-owner", Cursor: page.NextCursor})
+	if err != nil || len(page.Data) != 1 || page.NextCursor != "" {
+		t.Errorf("Unexpected page: %+v, %v", page, err)
+	}
+
+	rejected := []request.ListAccountsRequest{
+		{Sort: "password"},
+		{Currency: "INR"},
+		{Status: "FROZEN"},
+		{Cursor: "not-a-cursor"},
+		{Cursor: repository.Cursor{Sort: "id", Id: 3}.Encode(), PageID: 2},
+		{Cursor: repository.Cursor{Sort: "id", Id: 3}.Encode(), Sort: "balance"},
+	}
+	for _, req := range rejected {
+		mockLogger.EXPECT().Info("In func() GetAll :: SERVICE LAYER")
+		_, err = accountServiceImpl.GetAll(asSystem(), &req)
+		if !errors.Is(err, service.ErrInvalidQuery) {
+			t.Errorf("Expected ErrInvalidQuery for %+v, got %v", req, err)
+		}
+	}
 }
 
 func TestGetAccountById(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
 	mockLogger := mock.NewMockLogger(mockCtrl)
 	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With("account_id", 1).Return(mockLogger)
 	mockLogger.EXPECT().Info("In func() GetAccountById :: SERVICE LAYER")
-	mockAccountRepo.EXPECT().GetAccountById(1).Return(models.Account{Id: 1}, nil).Times(1)
-	accountServiceImpl := service.NewAccountService(mockAccountRepo)
-	accountServiceImpl.GetAccountById(1)
+	mockAccountRepo.EXPECT().GetAccountById(gomock.Any(), 1).Return(models.Account{Id: 1}, nil).Times(1)
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+	accountServiceImpl.GetAccountById(asSystem(), 1)
 }
 
 func TestDeleteAccountById(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
+	expectTx(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo)
 	mockLogger := mock.NewMockLogger(mockCtrl)
 	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With("account_id", 1).Return(mockLogger)
 	mockLogger.EXPECT().Info("In func() DeleteAccountById :: SERVICE LAYER")
-	mockAccountRepo.EXPECT().DeleteAccountById(1).Return(nil).Times(1)
-	accountServiceImpl := service.NewAccountService(mockAccountRepo)
-	accountServiceImpl.DeleteAccountById(1)
+	mockAccountRepo.EXPECT().GetAccountById(gomock.Any(), 1).Return(models.Account{Id: 1, Owner: "rahul"}, nil).Times(1)
+	mockAccountRepo.EXPECT().DeleteAccountById(gomock.Any(), 1).Return(nil).Times(1)
+	mockAuditRepo.EXPECT().SaveAuditEvent(gomock.Any()).DoAndReturn(func(event *models.AuditEvent) error {
+		assert.Equal(t, event.Operation, models.AuditAccountDeleted)
+		assert.Equal(t, string(event.Before), `{"id":1,"currency":"","owner":"rahul","balance":0,"status":"","created_at":"0001-01-01T00:00:00Z"}`)
+		assert.Equal(t, len(event.After), 0)
+		return nil
+	}).Times(1)
+	mockOutboxRepo.EXPECT().SaveOutboxEvent(gomock.Any()).DoAndReturn(func(event *models.OutboxEvent) error {
+		assert.Equal(t, event.EventType, outbox.AccountDeleted)
+		assert.Equal(t, string(event.Payload), `{"account_id":1}`)
+		return nil
+	}).Times(1)
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+	accountServiceImpl.DeleteAccountById(asSystem(), 1)
 }
 
 func TestUpdateAccountById(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
+	expectTx(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo)
 	mockLogger := mock.NewMockLogger(mockCtrl)
 	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With("account_id", 1).Return(mockLogger)
 	mockLogger.EXPECT().Info("In func() UpdateAccountById :: SERVICE LAYER")
 	originalAccount := models.Account{Id: 1, Currency: "USD", Owner: "rahul"}
 	changedAccount := models.Account{Id: 1, Currency: "USD", Owner: "mike"}
-	mockAccountRepo.EXPECT().UpdateAccountById(originalAccount, changedAccount).
+	//the account changed since the caller read it, the audit records the state read in the transaction
+	currentAccount := models.Account{Id: 1, Currency: "USD", Owner: "john"}
+	mockAccountRepo.EXPECT().GetAccountById(gomock.Any(), 1).Return(currentAccount, nil).Times(1)
+	mockAccountRepo.EXPECT().UpdateAccountById(gomock.Any(), currentAccount, changedAccount).
 		Return(models.Account{Id: 1, Currency: "USD", Owner: "mike"}, nil).Times(1)
-	accountServiceImpl := service.NewAccountService(mockAccountRepo)
-	accountServiceImpl.UpdateAccountById(originalAccount, changedAccount)
+	mockAuditRepo.EXPECT().SaveAuditEvent(gomock.Any()).DoAndReturn(func(event *models.AuditEvent) error {
+		assert.Equal(t, event.Operation, models.AuditAccountUpdated)
+		assert.Equal(t, event.EntityType, models.AuditEntityAccount)
+		assert.Equal(t, string(event.Before), `{"id":1,"currency":"USD","owner":"john","balance":0,"status":"","created_at":"0001-01-01T00:00:00Z"}`)
+		return nil
+	}).Times(1)
+	mockOutboxRepo.EXPECT().SaveOutboxEvent(gomock.Any()).DoAndReturn(func(event *models.OutboxEvent) error {
+		assert.Equal(t, event.EventType, outbox.AccountUpdated)
+		return nil
+	}).Times(1)
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+	accountServiceImpl.UpdateAccountById(asSystem(), originalAccount, changedAccount)
+
+	//the currency of a funded account cannot change
+	mockLogger.EXPECT().With("account_id", 1).Return(mockLogger)
+	mockLogger.EXPECT().Info("In func() UpdateAccountById :: SERVICE LAYER")
+	mockAccountRepo.EXPECT().GetAccountById(gomock.Any(), 1).Return(models.Account{Id: 1, Currency: "USD", Owner: "rahul", Balance: 10}, nil).Times(1)
+	_, err := accountServiceImpl.UpdateAccountById(asSystem(), originalAccount, models.Account{Id: 1, Currency: "EUR", Owner: "rahul"})
+	if !errors.Is(err, service.ErrInvalidRequest) {
+		t.Errorf("Expected ErrInvalidRequest, got %v", err)
+	}
+
+	//unsupported currency
+	mockLogger.EXPECT().With("account_id", 1).Return(mockLogger)
+	mockLogger.EXPECT().Info("In func() UpdateAccountById :: SERVICE LAYER")
+	_, err = accountServiceImpl.UpdateAccountById(asSystem(), originalAccount, models.Account{Id: 1, Currency: "XYZ", Owner: "rahul"})
+	if !errors.Is(err, service.ErrInvalidRequest) {
+		t.Errorf("Expected ErrInvalidRequest, got %v", err)
+	}
+}
+
+func TestPatchAccountById(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
+	expectTx(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+	account := models.Account{Id: 1, Currency: "USD", Owner: "rahul", Balance: 10}
+
+	mockLogger.EXPECT().With("account_id", 1).Return(mockLogger)
+	mockLogger.EXPECT().Info("In func() PatchAccountById :: SERVICE LAYER")
+	mockAccountRepo.EXPECT().GetAccountById(gomock.Any(), 1).Return(account, nil).Times(1)
+	mockAccountRepo.EXPECT().PatchAccountById(gomock.Any(), account, map[string]interface{}{"owner": "mike"}).
+		Return(models.Account{Id: 1, Currency: "USD", Owner: "mike", Balance: 10}, nil).Times(1)
+	mockAuditRepo.EXPECT().SaveAuditEvent(gomock.Any()).Return(nil).Times(1)
+	mockOutboxRepo.EXPECT().SaveOutboxEvent(gomock.Any()).Return(nil).Times(1)
+	_, err := accountServiceImpl.PatchAccountById(asSystem(), account, map[string]json.RawMessage{"owner": json.RawMessage(`"mike"`)})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	rejected := []map[string]json.RawMessage{
+		{"balance": json.RawMessage(`100`)},
+		{"owner": json.RawMessage(`null`)},
+		{"owner": json.RawMessage(`42`)},
+		{"owner": json.RawMessage(`""`)},
+		{"currency": json.RawMessage(`"INR"`)},
+		{"currency": json.RawMessage(`"EUR"`)},
+	}
+	for _, patch := range rejected {
+		mockLogger.EXPECT().With("account_id", 1).Return(mockLogger)
+		mockLogger.EXPECT().Info("In func() PatchAccountById :: SERVICE LAYER")
+		mockAccountRepo.EXPECT().GetAccountById(gomock.Any(), 1).Return(account, nil).Times(1)
+		_, err = accountServiceImpl.PatchAccountById(asSystem(), account, patch)
+		if !errors.Is(err, service.ErrInvalidPatch) {
+			t.Errorf("Expected ErrInvalidPatch for %v, got %v", patch, err)
+		}
+	}
+}
+
+func TestAdjustBalance(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
+	expectTx(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+
+	mockLogger.EXPECT().With("account_id", 1, "amount", -4.0, "reason_code", "FEE").Return(mockLogger)
+	mockLogger.EXPECT().Info("In func() AdjustBalance :: SERVICE LAYER")
+	mockAccountRepo.EXPECT().GetAccountById(gomock.Any(), 1).Return(models.Account{Id: 1, Balance: 10}, nil).Times(1)
+	mockEntryRepo.EXPECT().SaveEntry(gomock.Any(), &models.Entry{AccountID: 1, Amount: -4, ReasonCode: "FEE"}).Return(nil).Times(1)
+	mockAccountRepo.EXPECT().DecrementBalance(gomock.Any(), 1, 4.0).Return(nil).Times(1)
+	mockAuditRepo.EXPECT().SaveAuditEvent(gomock.Any()).DoAndReturn(func(event *models.AuditEvent) error {
+		assert.Equal(t, event.Operation, models.AuditBalanceAdjusted)
+		assert.Equal(t, string(event.After), `{"id":1,"currency":"","owner":"","balance":6,"status":"","created_at":"0001-01-01T00:00:00Z"}`)
+		return nil
+	}).Times(1)
+	mockOutboxRepo.EXPECT().SaveOutboxEvent(gomock.Any()).DoAndReturn(func(event *models.OutboxEvent) error {
+		assert.Equal(t, event.EventType, outbox.BalanceAdjusted)
+		assert.Equal(t, string(event.Payload), `{"account_id":1,"entry_id":0,"amount":-4,"reason_code":"FEE","balance":6}`)
+		return nil
+	}).Times(1)
+	entry, err := accountServiceImpl.AdjustBalance(asSystem(), 1, &request.BalanceAdjustmentRequest{Amount: -4, ReasonCode: "FEE"})
+	if err != nil || entry.ReasonCode != "FEE" {
+		t.Errorf("Unexpected result: %v, %v", entry, err)
+	}
+
+	//unsupported reason code
+	mockLogger.EXPECT().With("account_id", 1, "amount", 5.0, "reason_code", "GIFT").Return(mockLogger)
+	mockLogger.EXPECT().Info("In func() AdjustBalance :: SERVICE LAYER")
+	_, err = accountServiceImpl.AdjustBalance(asSystem(), 1, &request.BalanceAdjustmentRequest{Amount: 5, ReasonCode: "GIFT"})
+	if !errors.Is(err, service.ErrInvalidAdjustment) {
+		t.Errorf("Expected ErrInvalidAdjustment, got %v", err)
+	}
+
+	//balance would become negative
+	mockLogger.EXPECT().With("account_id", 1, "amount", -11.0, "reason_code", "CORRECTION").Return(mockLogger)
+	mockLogger.EXPECT().Info("In func() AdjustBalance :: SERVICE LAYER")
+	mockAccountRepo.EXPECT().GetAccountById(gomock.Any(), 1).Return(models.Account{Id: 1, Balance: 10}, nil).Times(1)
+	_, err = accountServiceImpl.AdjustBalance(asSystem(), 1, &request.BalanceAdjustmentRequest{Amount: -11, ReasonCode: "CORRECTION"})
+	if !errors.Is(err, service.ErrInsufficientFunds) {
+		t.Errorf("Expected ErrInsufficientFunds, got %v", err)
+	}
+
+	//a concurrent debit drained the account after it was read
+	mockLogger.EXPECT().With("account_id", 1, "amount", -8.0, "reason_code", "FEE").Return(mockLogger)
+	mockLogger.EXPECT().Info("In func() AdjustBalance :: SERVICE LAYER")
+	mockAccountRepo.EXPECT().GetAccountById(gomock.Any(), 1).Return(models.Account{Id: 1, Balance: 10}, nil).Times(1)
+	mockEntryRepo.EXPECT().SaveEntry(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mockAccountRepo.EXPECT().DecrementBalance(gomock.Any(), 1, 8.0).Return(repository.ErrInsufficientBalance).Times(1)
+	_, err = accountServiceImpl.AdjustBalance(asSystem(), 1, &request.BalanceAdjustmentRequest{Amount: -8, ReasonCode: "FEE"})
+	if !errors.Is(err, service.ErrInsufficientFunds) {
+		t.Errorf("Expected ErrInsufficientFunds, got %v", err)
+	}
+
+	//the account does not exist
+	mockLogger.EXPECT().With("account_id", 3, "amount", 5.0, "reason_code", "CORRECTION").Return(mockLogger)
+	mockLogger.EXPECT().Info("In func() AdjustBalance :: SERVICE LAYER")
+	mockAccountRepo.EXPECT().GetAccountById(gomock.Any(), 3).Return(models.Account{}, gorm.ErrRecordNotFound).Times(1)
+	_, err = accountServiceImpl.AdjustBalance(asSystem(), 3, &request.BalanceAdjustmentRequest{Amount: 5, ReasonCode: "CORRECTION"})
+	if !errors.Is(err, service.ErrAccountNotFound) {
+		t.Errorf("Expected ErrAccountNotFound, got %v", err)
+	}
+}
+
+func TestSetTransferLimit(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
+	expectTx(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+	limit := 50.0
+
+	mockLogger.EXPECT().With("account_id", 1).Return(mockLogger).AnyTimes()
+	mockLogger.EXPECT().Info("In func() SetTransferLimit :: SERVICE LAYER").AnyTimes()
+	mockAccountRepo.EXPECT().GetAccountById(gomock.Any(), 1).Return(models.Account{Id: 1, Balance: 10}, nil).Times(1)
+	mockAccountRepo.EXPECT().PatchAccountById(gomock.Any(), models.Account{Id: 1, Balance: 10}, map[string]interface{}{"transfer_limit": 50.0}).
+		Return(models.Account{Id: 1, Balance: 10, TransferLimit: 50}, nil).Times(1)
+	mockAuditRepo.EXPECT().SaveAuditEvent(gomock.Any()).DoAndReturn(func(event *models.AuditEvent) error {
+		assert.Equal(t, event.Operation, models.AuditAccountUpdated)
+		return nil
+	}).Times(1)
+	account, err := accountServiceImpl.SetTransferLimit(asSystem(), 1, &request.TransferLimitRequest{Limit: &limit})
+	if err != nil || account.TransferLimit != 50 {
+		t.Errorf("Unexpected result: %v, %v", account, err)
+	}
+
+	//tellers cannot change limits
+	_, err = accountServiceImpl.SetTransferLimit(asPrincipal("carol", []interface{}{"teller"}), 1, &request.TransferLimitRequest{Limit: &limit})
+	if !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("Expected ErrForbidden, got %v", err)
+	}
+
+	//negative limit
+	negative := -1.0
+	_, err = accountServiceImpl.SetTransferLimit(asSystem(), 1, &request.TransferLimitRequest{Limit: &negative})
+	if !errors.Is(err, service.ErrInvalidRequest) {
+		t.Errorf("Expected ErrInvalidRequest, got %v", err)
+	}
 }
 
-func TestWithTrx(t *testing.T) {
+func TestRunInTx(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
 	mockLogger := mock.NewMockLogger(mockCtrl)
 	logger.SetLogger(mockLogger)
-	mockLogger.EXPECT().Info("In func() WithTrx :: SERVICE LAYER")
-	mockAccountRepo.EXPECT().WithTrx(&gorm.DB{}).
-		Return(repository.AccountRepositoryImpl{}).Times(1)
-	accountServiceImpl := service.NewAccountService(mockAccountRepo)
-	accountServiceImpl.WithTrx(&gorm.DB{})
+	tx := &gorm.DB{}
+	mockLogger.EXPECT().Info("In func() RunInTx :: SERVICE LAYER")
+	mockUnitOfWork.EXPECT().RunInTx(gomock.Any(), gomock.Nil(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *sql.TxOptions, fn func(*gorm.DB) error) error {
+			return fn(tx)
+		}).Times(1)
+	mockAccountRepo.EXPECT().WithTrx(tx).Return(mockAccountRepo).Times(1)
+	mockTransferRepo.EXPECT().WithTrx(tx).Return(mockTransferRepo).Times(1)
+	mockEntryRepo.EXPECT().WithTrx(tx).Return(mockEntryRepo).Times(1)
+	mockAuditRepo.EXPECT().WithTrx(tx).Return(mockAuditRepo).Times(1)
+	mockOutboxRepo.EXPECT().WithTrx(tx).Return(mockOutboxRepo).Times(1)
+	// the mutations called on the bound service join its transaction instead of beginning their own
+	mockLogger.EXPECT().With("account_id", 1, "amount", 5.0).Return(mockLogger)
+	mockLogger.EXPECT().Info("In func() IncrementBalance :: SERVICE LAYER")
+	mockAccountRepo.EXPECT().IncrementBalance(gomock.Any(), 1, 5.0).Return(nil).Times(1)
+	mockLogger.EXPECT().With("account_id", 2).Return(mockLogger)
+	mockLogger.EXPECT().Info("In func() DeleteAccountById :: SERVICE LAYER")
+	mockAccountRepo.EXPECT().GetAccountById(gomock.Any(), 2).Return(models.Account{}, gorm.ErrRecordNotFound).Times(1)
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+	err := accountServiceImpl.RunInTx(asSystem(), func(as service.AccountService) error {
+		if err := as.IncrementBalance(asSystem(), 1, 5); err != nil {
+			return err
+		}
+		return as.DeleteAccountById(asSystem(), 2)
+	})
+	assert.Equal(t, errors.Is(err, service.ErrAccountNotFound), true)
 }
 
 func TestSaveTransfer(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
+	expectTx(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo)
 	mockLogger := mock.NewMockLogger(mockCtrl)
 	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With("from_account_id", 1, "to_account_id", 2, "amount", 20.0, "currency", "USD").Return(mockLogger).Times(2)
 	mockLogger.EXPECT().Info("In func() SaveTransfer :: SERVICE LAYER")
+	mockLogger.EXPECT().With("transfer_id", 0).Return(mockLogger)
+	mockLogger.EXPECT().Info("transfer completed")
 	transferRequest := request.TransferRequest{FromAccountID: 1, ToAccountID: 2, Amount: 20, Currency: "USD"}
 	transfer := &models.Transfer{Id: 0, FromAccountID: 1, ToAccountID: 2, Amount: 20, CreatedAt: time.Time{}}
-	mockAccountRepo.EXPECT().SaveTransfer(transfer).
+	mockTransferRepo.EXPECT().SaveTransfer(gomock.Any(), transfer).
 		Return(*transfer, nil).Times(1)
-	accountServiceImpl := service.NewAccountService(mockAccountRepo)
-	accountServiceImpl.SaveTransfer(&transferRequest)
+	mockAuditRepo.EXPECT().SaveAuditEvent(gomock.Any()).Return(nil).Times(1)
+	mockAccountRepo.EXPECT().GetAccountsByIds(gomock.Any(), []int{1, 2}).
+		Return([]models.Account{{Id: 1, Owner: "alice"}, {Id: 2, Owner: "bob"}}, nil).Times(1)
+	//one event for each account of the transfer, in the order of the accounts, carrying the owner of the account
+	var aggregateIDs []int
+	var owners []string
+	mockOutboxRepo.EXPECT().SaveOutboxEvent(gomock.Any()).DoAndReturn(func(event *models.OutboxEvent) error {
+		assert.Equal(t, event.EventType, outbox.TransferCompleted)
+		aggregateIDs = append(aggregateIDs, event.AggregateID)
+		owners = append(owners, event.Owner)
+		return nil
+	}).Times(2)
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+	accountServiceImpl.SaveTransfer(asSystem(), &transferRequest)
+	assert.Equal(t, aggregateIDs, []int{1, 2})
+	assert.Equal(t, owners, []string{"alice", "bob"})
+}
+
+func TestCreateTransfer(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
+	expectTx(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With("from_account_id", 1, "to_account_id", 2, "amount", 20.0, "currency", "USD").Return(mockLogger).Times(2)
+	mockLogger.EXPECT().Info("In func() CreateTransfer :: SERVICE LAYER")
+	mockLogger.EXPECT().With("transfer_id", 5).Return(mockLogger)
+	mockLogger.EXPECT().Info("transfer completed")
+	transferRequest := request.TransferRequest{FromAccountID: 1, ToAccountID: 2, Amount: 20, Currency: "USD"}
+	accounts := []models.Account{{Id: 1, Currency: "USD", Balance: 50}, {Id: 2, Currency: "USD"}}
+	mockAccountRepo.EXPECT().GetAccountsByIds(gomock.Any(), []int{1, 2}).Return(accounts, nil).Times(2)
+	gomock.InOrder(
+		mockTransferRepo.EXPECT().SaveTransfer(gomock.Any(), gomock.Any()).Return(models.Transfer{Id: 5, FromAccountID: 1, ToAccountID: 2, Amount: 20}, nil),
+		mockEntryRepo.EXPECT().SaveEntry(gomock.Any(), &models.Entry{AccountID: 1, Amount: -20}).Return(nil),
+		mockEntryRepo.EXPECT().SaveEntry(gomock.Any(), &models.Entry{AccountID: 2, Amount: 20}).Return(nil),
+		mockAccountRepo.EXPECT().DecrementBalance(gomock.Any(), 1, 20.0).Return(nil),
+		mockAccountRepo.EXPECT().IncrementBalance(gomock.Any(), 2, 20.0).Return(nil),
+	)
+	mockAuditRepo.EXPECT().SaveAuditEvent(gomock.Any()).Return(nil).Times(1)
+	mockOutboxRepo.EXPECT().SaveOutboxEvent(gomock.Any()).Return(nil).Times(2)
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+	transfer, err := accountServiceImpl.CreateTransfer(asSystem(), &transferRequest)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, transfer.Id, 5)
+
+	//Failure case: the remaining steps are skipped once one fails
+	mockLogger.EXPECT().With("from_account_id", 1, "to_account_id", 2, "amount", 20.0, "currency", "USD").Return(mockLogger)
+	mockLogger.EXPECT().Info("In func() CreateTransfer :: SERVICE LAYER")
+	mockTransferRepo.EXPECT().SaveTransfer(gomock.Any(), gomock.Any()).Return(models.Transfer{Id: 6}, nil)
+	mockAuditRepo.EXPECT().SaveAuditEvent(gomock.Any()).Return(nil).Times(1)
+	mockOutboxRepo.EXPECT().SaveOutboxEvent(gomock.Any()).Return(nil).Times(2)
+	mockEntryRepo.EXPECT().SaveEntry(gomock.Any(), gomock.Any()).Return(errors.New("violates foreign key constraint"))
+	_, err = accountServiceImpl.CreateTransfer(asSystem(), &transferRequest)
+	assert.NotEqual(t, err, nil)
+}
+
+func TestCreateTransferRejected(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
+	expectTx(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With(gomock.Any()).Return(mockLogger).AnyTimes()
+	mockLogger.EXPECT().Info("In func() CreateTransfer :: SERVICE LAYER").AnyTimes()
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+	usd := []models.Account{{Id: 1, Currency: "USD", Balance: 10}, {Id: 2, Currency: "USD"}}
+	limited := []models.Account{{Id: 1, Currency: "USD", Balance: 10, TransferLimit: 4}, {Id: 2, Currency: "USD"}}
+	eur := []models.Account{{Id: 1, Currency: "USD", Balance: 10}, {Id: 2, Currency: "EUR"}}
+
+	for _, tc := range []struct {
+		req      request.TransferRequest
+		accounts []models.Account
+		expected error
+	}{
+		{request.TransferRequest{FromAccountID: 1, ToAccountID: 2, Amount: 0}, nil, service.ErrInvalidTransfer},
+		{request.TransferRequest{FromAccountID: 1, ToAccountID: 1, Amount: 5}, nil, service.ErrInvalidTransfer},
+		{request.TransferRequest{FromAccountID: 1, ToAccountID: 2, Amount: 5}, usd[:1], service.ErrAccountNotFound},
+		{request.TransferRequest{FromAccountID: 1, ToAccountID: 2, Amount: 5}, eur, service.ErrInvalidTransfer},
+		{request.TransferRequest{FromAccountID: 1, ToAccountID: 2, Amount: 5, Currency: "EUR"}, usd, service.ErrInvalidTransfer},
+		{request.TransferRequest{FromAccountID: 1, ToAccountID: 2, Amount: 15}, usd, service.ErrInsufficientFunds},
+		{request.TransferRequest{FromAccountID: 1, ToAccountID: 2, Amount: 5}, limited, service.ErrLimitExceeded},
+	} {
+		if tc.accounts != nil {
+			mockAccountRepo.EXPECT().GetAccountsByIds(gomock.Any(), []int{1, 2}).Return(tc.accounts, nil).Times(1)
+		}
+		_, err := accountServiceImpl.CreateTransfer(asSystem(), &tc.req)
+		if !errors.Is(err, tc.expected) {
+			t.Errorf("Expected %v for %+v, got %v", tc.expected, tc.req, err)
+		}
+	}
+
+	//scopes do not let a customer, nor a key acting for one, debit the account of another
+	bobs := []models.Account{{Id: 1, Owner: "bob", Currency: "USD", Balance: 10}, {Id: 2, Owner: "alice", Currency: "USD"}}
+	for _, principal := range []auth.Principal{
+		{Subject: "alice", Claims: map[string]interface{}{service.RolesClaim: "customer", service.ScopesClaim: service.ScopeTransfersWrite}},
+		{Subject: "apikey:ftk_1234", Claims: map[string]interface{}{service.OwnerClaim: "alice", service.ScopesClaim: service.ScopeTransfersWrite}},
+	} {
+		mockAccountRepo.EXPECT().GetAccountsByIds(gomock.Any(), []int{1, 2}).Return(bobs, nil).Times(1)
+		_, err := accountServiceImpl.CreateTransfer(auth.NewContext(context.Background(), principal),
+			&request.TransferRequest{FromAccountID: 1, ToAccountID: 2, Amount: 5})
+		assert.Equal(t, errors.Is(err, service.ErrForbidden), true)
+	}
 }
 
 func TestSaveEntry(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
 	mockLogger := mock.NewMockLogger(mockCtrl)
 	logger.SetLogger(mockLogger)
 	mockLogger.EXPECT().Info("In func() SaveEntry :: SERVICE LAYER")
 	transferRequest := request.TransferRequest{FromAccountID: 1, ToAccountID: 2, Amount: 20, Currency: "USD"}
 	entry := &models.Entry{Id: 0, AccountID: 1, Amount: -20}
-	mockAccountRepo.EXPECT().SaveEntry(entry).
+	mockEntryRepo.EXPECT().SaveEntry(gomock.Any(), entry).
 		Return(nil).Times(1)
-	accountServiceImpl := service.NewAccountService(mockAccountRepo)
-	accountServiceImpl.SaveEntry(&transferRequest, "DEBIT")
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+	accountServiceImpl.SaveEntry(asSystem(), &transferRequest, "DEBIT")
 
 	//test CREDIT entry
 	(*entry).Amount = 20
 	(*entry).AccountID = 2
 	mockLogger.EXPECT().Info("In func() SaveEntry :: SERVICE LAYER")
-	mockAccountRepo.EXPECT().SaveEntry(entry).Return(nil).Times(1)
-	accountServiceImpl = service.NewAccountService(mockAccountRepo)
-	accountServiceImpl.SaveEntry(&transferRequest, "CREDIT")
+	mockEntryRepo.EXPECT().SaveEntry(gomock.Any(), entry).Return(nil).Times(1)
+	accountServiceImpl = service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+	accountServiceImpl.SaveEntry(asSystem(), &transferRequest, "CREDIT")
 
 }
 
 func TestIncrementBalance(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
 	mockLogger := mock.NewMockLogger(mockCtrl)
 	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With("account_id", 1, "amount", 24.0).Return(mockLogger)
 	mockLogger.EXPECT().Info("In func() IncrementBalance :: SERVICE LAYER")
-	mockAccountRepo.EXPECT().IncrementBalance(1, 24.0).Return(nil).Times(1)
-	accountServiceImpl := service.NewAccountService(mockAccountRepo)
-	accountServiceImpl.IncrementBalance(1, 24.0)
+	mockAccountRepo.EXPECT().IncrementBalance(gomock.Any(), 1, 24.0).Return(nil).Times(1)
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+	accountServiceImpl.IncrementBalance(asSystem(), 1, 24.0)
 }
 
 func TestDecrementBalance(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockTransferRepo := mock.NewMockTransferRepository(mockCtrl)
+	mockEntryRepo := mock.NewMockEntryRepository(mockCtrl)
+	mockAuditRepo := mock.NewMockAuditRepository(mockCtrl)
+	mockOutboxRepo := mock.NewMockOutboxRepository(mockCtrl)
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
 	mockLogger := mock.NewMockLogger(mockCtrl)
 	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With("account_id", 1, "amount", 24.0).Return(mockLogger)
 	mockLogger.EXPECT().Info("In func() DecrementBalance :: SERVICE LAYER")
-	mockAccountRepo.EXPECT().DecrementBalance(1, 24.0).Return(nil).Times(1)
-	accountServiceImpl := service.NewAccountService(mockAccountRepo)
-	accountServiceImpl.DecrementBalance(1, 24.0)
+	mockAccountRepo.EXPECT().DecrementBalance(gomock.Any(), 1, 24.0).Return(nil).Times(1)
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mockAccountRepo, mockTransferRepo, mockEntryRepo, mockAuditRepo, mockOutboxRepo, service.NewRolePolicy(), sql.LevelSerializable)
+	accountServiceImpl.DecrementBalance(asSystem(), 1, 24.0)
+}
+
+// expectTx makes the unit of work run the functions it is given, the repositories stay the mocks once bound
+func TestOnlyTheMovesOfMoneyAreIsolated(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockUnitOfWork := mock.NewMockUnitOfWork(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With(gomock.Any()).Return(mockLogger).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+	accountServiceImpl := service.NewAccountService(mockUnitOfWork, mock.NewMockAccountRepository(mockCtrl),
+		mock.NewMockTransferRepository(mockCtrl), mock.NewMockEntryRepository(mockCtrl), mock.NewMockAuditRepository(mockCtrl),
+		mock.NewMockOutboxRepository(mockCtrl), service.NewRolePolicy(), sql.LevelSerializable)
+	var isolations []sql.IsolationLevel
+	mockUnitOfWork.EXPECT().RunInTx(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, opts *sql.TxOptions, _ func(*gorm.DB) error) error {
+			isolation := sql.LevelDefault
+			if opts != nil {
+				isolation = opts.Isolation
+			}
+			isolations = append(isolations, isolation)
+			return service.ErrTransactionConflict
+		}).Times(6)
+
+	ctx := asSystem()
+	transfer := &request.TransferRequest{FromAccountID: 1, ToAccountID: 2, Amount: 5, Currency: "USD"}
+	accountServiceImpl.CreateTransfer(ctx, transfer)
+	accountServiceImpl.SaveTransfer(ctx, transfer)
+	accountServiceImpl.AdjustBalance(ctx, 1, &request.BalanceAdjustmentRequest{Amount: 5, ReasonCode: "correction"})
+	accountServiceImpl.SaveAccount(ctx, models.Account{Owner: "alice", Currency: "USD"})
+	accountServiceImpl.DeleteAccountById(ctx, 1)
+	limit := 50.0
+	accountServiceImpl.SetTransferLimit(ctx, 1, &request.TransferLimitRequest{Limit: &limit})
+	assert.Equal(t, isolations, []sql.IsolationLevel{sql.LevelSerializable, sql.LevelSerializable, sql.LevelSerializable,
+		sql.LevelDefault, sql.LevelDefault, sql.LevelDefault})
+}
+
+func expectTx(mockUnitOfWork *mock.MockUnitOfWork, mockAccountRepo *mock.MockAccountRepository,
+	mockTransferRepo *mock.MockTransferRepository, mockEntryRepo *mock.MockEntryRepository,
+	mockAuditRepo *mock.MockAuditRepository, mockOutboxRepo *mock.MockOutboxRepository) {
+	mockUnitOfWork.EXPECT().RunInTx(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *sql.TxOptions, fn func(*gorm.DB) error) error {
+			return fn(&gorm.DB{})
+		}).AnyTimes()
+	mockAccountRepo.EXPECT().WithTrx(gomock.Any()).Return(mockAccountRepo).AnyTimes()
+	mockTransferRepo.EXPECT().WithTrx(gomock.Any()).Return(mockTransferRepo).AnyTimes()
+	mockEntryRepo.EXPECT().WithTrx(gomock.Any()).Return(mockEntryRepo).AnyTimes()
+	mockAuditRepo.EXPECT().WithTrx(gomock.Any()).Return(mockAuditRepo).AnyTimes()
+	mockOutboxRepo.EXPECT().WithTrx(gomock.Any()).Return(mockOutboxRepo).AnyTimes()
 }
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/auth"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	// apiKeyPrefix marks API keys so that leaked keys are easy to spot
+	apiKeyPrefix = "ftk_"
+	// lastUsedResolution limits the writes recording when a key was last used to one per key and minute
+	lastUsedResolution = time.Minute
+)
+
+// ErrInvalidAPIKey is returned for keys that are unknown or revoked
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+type APIKeyServiceImpl struct {
+	apiKeyRepository repository.APIKeyRepository
+	policy           Policy
+}
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, req *request.CreateAPIKeyRequest) (models.APIKey, error)
+	GetAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RevokeAPIKeyById(ctx context.Context, id int) (models.APIKey, error)
+	Authenticate(ctx context.Context, key string) (auth.Principal, error)
+}
+
+func NewAPIKeyService(r repository.APIKeyRepository, p Policy) APIKeyService {
+	return APIKeyServiceImpl{
+		apiKeyRepository: r,
+		policy:           p,
+	}
+}
+
+// CreateAPIKey issues a key granted the scopes. The key is only part of the returned value, the database
+// keeps its hash.
+func (a APIKeyServiceImpl) CreateAPIKey(ctx context.Context, req *request.CreateAPIKeyRequest) (models.APIKey, error) {
+	logger.FromContext(ctx).Info("In func() CreateAPIKey :: SERVICE LAYER")
+	if err := authorize(ctx, a.policy, ActionManageAPIKeys); err != nil {
+		return models.APIKey{}, err
+	}
+	for _, scope := range req.Scopes {
+		if !IsKnownScope(scope) {
+			return models.APIKey{}, fmt.Errorf("%w: unknown scope %q", ErrInvalidRequest, scope)
+		}
+	}
+	// keys are never admins, and a key without role nor owner could not act on any account
+	if req.Role != "" && Role(req.Role) != RoleTeller {
+		return models.APIKey{}, fmt.Errorf("%w: role of a key can only be %s", ErrInvalidRequest, RoleTeller)
+	}
+	if req.Role == "" && req.Owner == "" {
+		return models.APIKey{}, fmt.Errorf("%w: a key needs an owner or the %s role", ErrInvalidRequest, RoleTeller)
+	}
+	key, err := generateAPIKey()
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	apiKey := models.APIKey{
+		Name:    req.Name,
+		Prefix:  key[:len(apiKeyPrefix)+8],
+		KeyHash: hashAPIKey(key),
+		Scopes:  req.Scopes,
+		Owner:   req.Owner,
+		Role:    req.Role,
+	}
+	if principal, ok := auth.FromContext(ctx); ok {
+		apiKey.CreatedBy = principal.Subject
+	}
+	if err = a.apiKeyRepository.SaveAPIKey(ctx, &apiKey); err != nil {
+		return models.APIKey{}, err
+	}
+	apiKey.Key = key
+	return apiKey, nil
+}
+
+func (a APIKeyServiceImpl) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	logger.FromContext(ctx).Info("In func() GetAPIKeys :: SERVICE LAYER")
+	if err := authorize(ctx, a.policy, ActionManageAPIKeys); err != nil {
+		return nil, err
+	}
+	keys, err := a.apiKeyRepository.GetAPIKeys(ctx)
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+	return keys, err
+}
+
+// RevokeAPIKeyById revokes a key for good, requests sent with it are rejected from then on
+func (a APIKeyServiceImpl) RevokeAPIKeyById(ctx context.Context, id int) (models.APIKey, error) {
+	logger.FromContext(ctx).Info("In func() RevokeAPIKeyById :: SERVICE LAYER")
+	if err := authorize(ctx, a.policy, ActionManageAPIKeys); err != nil {
+		return models.APIKey{}, err
+	}
+	if err := a.apiKeyRepository.RevokeAPIKey(ctx, id, time.Now()); err != nil {
+		return models.APIKey{}, err
+	}
+	key, err := a.apiKeyRepository.GetAPIKeyById(ctx, id)
+	return key, notFound(err, ErrAPIKeyNotFound)
+}
+
+// Authenticate returns the principal of a valid key, its subject names the key by prefix and its scope, owner
+// and roles claims hold the scopes, owner and role of the key
+func (a APIKeyServiceImpl) Authenticate(ctx context.Context, key string) (auth.Principal, error) {
+	logger.FromContext(ctx).Info("In func() Authenticate :: SERVICE LAYER")
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return auth.Principal{}, ErrInvalidAPIKey
+	}
+	apiKey, err := a.apiKeyRepository.GetAPIKeyByHash(ctx, hashAPIKey(key))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return auth.Principal{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return auth.Principal{}, err
+	}
+	if apiKey.RevokedAt != nil {
+		return auth.Principal{}, fmt.Errorf("%w: key %s was revoked", ErrInvalidAPIKey, apiKey.Prefix)
+	}
+	now := time.Now()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedResolution {
+		// the request goes on when the timestamp cannot be written, it is informational
+		if err = a.apiKeyRepository.TouchAPIKey(ctx, apiKey.Id, now); err != nil {
+			logger.FromContext(ctx).With("api_key", apiKey.Prefix, "error", err).Warn("recording the use of the API key failed")
+		}
+	}
+	subject := "apikey:" + apiKey.Prefix
+	claims := map[string]interface{}{
+		"sub":       subject,
+		"name":      apiKey.Name,
+		ScopesClaim: apiKey.Scopes,
+	}
+	if apiKey.Owner != "" {
+		claims[OwnerClaim] = apiKey.Owner
+	}
+	if apiKey.Role != "" {
+		claims[RolesClaim] = []string{apiKey.Role}
+	}
+	return auth.Principal{Subject: subject, Claims: claims}, nil
+}
+
+// generateAPIKey returns a random key carrying the API key prefix
+func generateAPIKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + hex.EncodeToString(buf), nil
+}
+
+// hashAPIKey returns the hex SHA-256 of a key. The keys are random, so an unsalted fast hash is enough to
+// keep them out of the database.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
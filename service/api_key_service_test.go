@@ -0,0 +1,136 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/auth"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mock "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"gopkg.in/go-playground/assert.v1"
+	"gorm.io/gorm"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockAPIKeyRepo := mock.NewMockAPIKeyRepository(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info("In func() CreateAPIKey :: SERVICE LAYER").Times(5)
+	apiKeyServiceImpl := service.NewAPIKeyService(mockAPIKeyRepo, service.NewRolePolicy())
+
+	var saved models.APIKey
+	mockAPIKeyRepo.EXPECT().SaveAPIKey(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, key *models.APIKey) error {
+		key.Id = 4
+		saved = *key
+		return nil
+	})
+	admin := asPrincipal("ada", "admin")
+	key, err := apiKeyServiceImpl.CreateAPIKey(admin, &request.CreateAPIKeyRequest{Name: "batch", Scopes: []string{service.ScopeAccountsRead},
+		Owner: "acme"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, key.Id, 4)
+	assert.Equal(t, key.CreatedBy, "ada")
+	assert.Equal(t, saved.Owner, "acme")
+	assert.Equal(t, strings.HasPrefix(key.Key, "ftk_"), true)
+	assert.Equal(t, strings.HasPrefix(key.Key, key.Prefix), true)
+	//only the hash of the key is stored
+	assert.Equal(t, saved.Key, "")
+	assert.Equal(t, len(saved.KeyHash), 64)
+	assert.NotEqual(t, saved.KeyHash, key.Key)
+
+	_, err = apiKeyServiceImpl.CreateAPIKey(admin, &request.CreateAPIKeyRequest{Name: "batch", Scopes: []string{"accounts:delete"}, Owner: "acme"})
+	assert.Equal(t, errors.Is(err, service.ErrInvalidRequest), true)
+	//a key acts for an owner or as a teller, never as an admin
+	_, err = apiKeyServiceImpl.CreateAPIKey(admin, &request.CreateAPIKeyRequest{Name: "batch", Scopes: []string{service.ScopeAccountsRead}})
+	assert.Equal(t, errors.Is(err, service.ErrInvalidRequest), true)
+	_, err = apiKeyServiceImpl.CreateAPIKey(admin, &request.CreateAPIKeyRequest{Name: "batch", Scopes: []string{service.ScopeAccountsRead}, Role: "admin"})
+	assert.Equal(t, errors.Is(err, service.ErrInvalidRequest), true)
+	_, err = apiKeyServiceImpl.CreateAPIKey(asPrincipal("tina", "teller"), &request.CreateAPIKeyRequest{Name: "batch", Scopes: []string{service.ScopeAccountsRead}})
+	assert.Equal(t, errors.Is(err, service.ErrForbidden), true)
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockAPIKeyRepo := mock.NewMockAPIKeyRepository(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+	apiKeyServiceImpl := service.NewAPIKeyService(mockAPIKeyRepo, service.NewRolePolicy())
+
+	var hash string
+	mockAPIKeyRepo.EXPECT().SaveAPIKey(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, key *models.APIKey) error {
+		hash = key.KeyHash
+		return nil
+	})
+	key, _ := apiKeyServiceImpl.CreateAPIKey(asSystem(), &request.CreateAPIKeyRequest{Name: "batch",
+		Scopes: []string{service.ScopeAccountsRead, service.ScopeTransfersWrite}, Role: "teller"})
+
+	stored := models.APIKey{Id: 4, Name: "batch", Prefix: key.Prefix, KeyHash: hash, Scopes: key.Scopes, Role: key.Role}
+	mockAPIKeyRepo.EXPECT().GetAPIKeyByHash(gomock.Any(), hash).Return(stored, nil)
+	mockAPIKeyRepo.EXPECT().TouchAPIKey(gomock.Any(), 4, gomock.Any()).Return(nil)
+	principal, err := apiKeyServiceImpl.Authenticate(context.Background(), key.Key)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, principal.Subject, "apikey:"+key.Prefix)
+	assert.Equal(t, principal.Claims[service.ScopesClaim], []string{service.ScopeAccountsRead, service.ScopeTransfersWrite})
+	assert.Equal(t, service.RolesOf(principal), map[service.Role]bool{service.RoleTeller: true})
+
+	//the last use is recorded once a minute
+	recently := time.Now().Add(-time.Second)
+	stored.LastUsedAt = &recently
+	mockAPIKeyRepo.EXPECT().GetAPIKeyByHash(gomock.Any(), hash).Return(stored, nil)
+	_, err = apiKeyServiceImpl.Authenticate(context.Background(), key.Key)
+	assert.Equal(t, err, nil)
+
+	stored.RevokedAt = &recently
+	mockAPIKeyRepo.EXPECT().GetAPIKeyByHash(gomock.Any(), hash).Return(stored, nil)
+	_, err = apiKeyServiceImpl.Authenticate(context.Background(), key.Key)
+	assert.Equal(t, errors.Is(err, service.ErrInvalidAPIKey), true)
+
+	mockAPIKeyRepo.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any()).Return(models.APIKey{}, gorm.ErrRecordNotFound)
+	_, err = apiKeyServiceImpl.Authenticate(context.Background(), "ftk_unknown")
+	assert.Equal(t, errors.Is(err, service.ErrInvalidAPIKey), true)
+	_, err = apiKeyServiceImpl.Authenticate(context.Background(), "not-a-key")
+	assert.Equal(t, errors.Is(err, service.ErrInvalidAPIKey), true)
+}
+
+func TestScopesGrantActions(t *testing.T) {
+	policy := service.NewRolePolicy()
+	scopes := service.ScopeAccountsRead + " " + service.ScopeTransfersWrite
+	for _, tc := range []struct {
+		name   string
+		claims map[string]interface{}
+		// owner the granted actions are restricted to, empty for every account
+		owner string
+	}{
+		//a key acts on the accounts of its owner, or on its own without owner
+		{"owner", map[string]interface{}{service.ScopesClaim: scopes, service.OwnerClaim: "acme"}, "acme"},
+		{"no owner", map[string]interface{}{service.ScopesClaim: scopes}, "apikey:ftk_1234"},
+		//the teller role acts on every account, narrowed to the scopes
+		{"teller", map[string]interface{}{service.ScopesClaim: scopes, service.RolesClaim: "teller"}, ""},
+	} {
+		ctx := auth.NewContext(context.Background(), auth.Principal{Subject: "apikey:ftk_1234", Claims: tc.claims})
+		for action, granted := range map[service.Action]bool{
+			service.ActionReadAccount:   true,
+			service.ActionTransfer:      true,
+			service.ActionOpenAccount:   false,
+			service.ActionCloseAccount:  false,
+			service.ActionManageAPIKeys: false,
+		} {
+			owner, err := policy.Scope(ctx, action)
+			if granted {
+				assert.Equal(t, err, nil)
+				assert.Equal(t, owner, tc.owner)
+			} else if !errors.Is(err, service.ErrForbidden) {
+				t.Errorf("Expected %s to be forbidden for %s, got %v", action, tc.name, err)
+			}
+		}
+	}
+}
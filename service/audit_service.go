@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+)
+
+type AuditServiceImpl struct {
+	auditRepository repository.AuditRepository
+	policy          Policy
+}
+
+type AuditService interface {
+	GetAuditEvents(ctx context.Context, req *request.ListAuditEventsRequest) ([]models.AuditEvent, error)
+}
+
+func NewAuditService(r repository.AuditRepository, p Policy) AuditService {
+	return AuditServiceImpl{
+		auditRepository: r,
+		policy:          p,
+	}
+}
+
+func (a AuditServiceImpl) GetAuditEvents(ctx context.Context, req *request.ListAuditEventsRequest) ([]models.AuditEvent, error) {
+	logger.FromContext(ctx).Info("In func() GetAuditEvents :: SERVICE LAYER")
+	if err := authorize(ctx, a.policy, ActionReadAudit); err != nil {
+		return nil, err
+	}
+	query := repository.AuditQuery{
+		Actor:      req.Actor,
+		Operation:  req.Operation,
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+		RequestID:  req.RequestID,
+		From:       req.From,
+		To:         req.To,
+		Limit:      req.PageSize,
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultPageSize
+	}
+	if req.PageID > 0 {
+		query.Offset = (req.PageID - 1) * query.Limit
+	}
+	events, err := a.auditRepository.GetAuditEvents(ctx, query)
+	if events == nil {
+		events = []models.AuditEvent{}
+	}
+	return events, err
+}
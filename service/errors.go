@@ -0,0 +1,111 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"gorm.io/gorm"
+)
+
+// ErrorKind classifies domain errors, the REST, gRPC and GraphQL layers map each kind to their own status
+type ErrorKind int
+
+const (
+	// KindInternal is the kind of errors that are not domain errors, their details are not shown to clients
+	KindInternal ErrorKind = iota
+	KindNotFound
+	KindValidationFailed
+	KindInsufficientFunds
+	KindConflict
+	KindLimitExceeded
+	KindForbidden
+	KindUnauthenticated
+)
+
+// Error is a domain error. Code is stable so that clients can branch on it, Message is meant for humans.
+// Wrap the sentinels below with fmt.Errorf("%w: ...") to add details, errors.Is and errors.As see through it.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	// Err is the cause, it is kept for errors.Is and the logs but never shown to clients
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches domain errors by code, so that a sentinel matches its copies wrapping a cause
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// wrap returns a copy of the domain error with cause attached
+func (e *Error) wrap(cause error) *Error {
+	wrapped := *e
+	wrapped.Err = cause
+	return &wrapped
+}
+
+var (
+	// ErrInvalidRequest is returned when a request cannot be bound or a parameter is malformed
+	ErrInvalidRequest = &Error{Kind: KindValidationFailed, Code: "INVALID_REQUEST", Message: "invalid request"}
+	// ErrInvalidPatch is returned when a merge patch document cannot be applied to an account
+	ErrInvalidPatch = &Error{Kind: KindValidationFailed, Code: "INVALID_PATCH", Message: "invalid merge patch"}
+	// ErrInvalidAdjustment is returned when a balance adjustment is rejected
+	ErrInvalidAdjustment = &Error{Kind: KindValidationFailed, Code: "INVALID_ADJUSTMENT", Message: "invalid balance adjustment"}
+	// ErrInvalidQuery is returned when the pagination, filter or sort options of a listing are rejected
+	ErrInvalidQuery = &Error{Kind: KindValidationFailed, Code: "INVALID_QUERY", Message: "invalid query"}
+	// ErrInvalidTransfer is returned when a transfer is rejected before any money moves
+	ErrInvalidTransfer = &Error{Kind: KindValidationFailed, Code: "INVALID_TRANSFER", Message: "invalid transfer"}
+	// ErrInvalidSubscription is returned when a webhook subscription is rejected
+	ErrInvalidSubscription = &Error{Kind: KindValidationFailed, Code: "INVALID_SUBSCRIPTION", Message: "invalid webhook subscription"}
+
+	ErrAccountNotFound      = &Error{Kind: KindNotFound, Code: "ACCOUNT_NOT_FOUND", Message: "account not found"}
+	ErrTransferNotFound     = &Error{Kind: KindNotFound, Code: "TRANSFER_NOT_FOUND", Message: "transfer not found"}
+	ErrSubscriptionNotFound = &Error{Kind: KindNotFound, Code: "SUBSCRIPTION_NOT_FOUND", Message: "webhook subscription not found"}
+	ErrDeliveryNotFound     = &Error{Kind: KindNotFound, Code: "DELIVERY_NOT_FOUND", Message: "webhook delivery not found"}
+	ErrAPIKeyNotFound       = &Error{Kind: KindNotFound, Code: "API_KEY_NOT_FOUND", Message: "API key not found"}
+
+	// ErrInsufficientFunds is returned when a debit would make a balance negative
+	ErrInsufficientFunds = &Error{Kind: KindInsufficientFunds, Code: "INSUFFICIENT_FUNDS", Message: "insufficient funds"}
+	// ErrTransactionConflict is returned when a transaction kept losing to concurrent ones and ran out of retries
+	ErrTransactionConflict = &Error{Kind: KindConflict, Code: "TRANSACTION_CONFLICT", Message: "conflicting concurrent update, try again"}
+	// ErrLimitExceeded is returned when an operation goes beyond a configured limit
+	ErrLimitExceeded = &Error{Kind: KindLimitExceeded, Code: "LIMIT_EXCEEDED", Message: "limit exceeded"}
+	// ErrForbidden is returned when the policy does not let the caller perform an operation
+	ErrForbidden = &Error{Kind: KindForbidden, Code: "FORBIDDEN", Message: "operation not permitted"}
+	// ErrUnauthenticated is returned when an operation is requested without a caller for the policy to judge
+	ErrUnauthenticated = &Error{Kind: KindUnauthenticated, Code: "UNAUTHENTICATED", Message: "authentication required"}
+)
+
+// KindOf returns the kind of the domain error in the chain of err, KindInternal when there is none
+func KindOf(err error) ErrorKind {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Kind
+	}
+	return KindInternal
+}
+
+// insufficientFunds turns the refused debit of the repositories into the domain error, other errors are kept
+func insufficientFunds(err error) error {
+	if errors.Is(err, repository.ErrInsufficientBalance) {
+		return ErrInsufficientFunds.wrap(err)
+	}
+	return err
+}
+
+// notFound turns the record not found error of the repositories into the domain error, other errors are kept
+func notFound(err error, domainErr *Error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domainErr.wrap(err)
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/audit"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+)
+
+// defaultLogLevelTTL is how long an override lasts when the request does not say
+const defaultLogLevelTTL = 15 * time.Minute
+
+type LogLevelServiceImpl struct {
+	policy Policy
+}
+
+// LogLevelService reads and overrides the log level of the instance at runtime, an override reverts to the
+// configured level of the profile once it expires
+type LogLevelService interface {
+	GetLogLevel(ctx context.Context) (models.LogLevel, error)
+	SetLogLevel(ctx context.Context, req *request.LogLevelRequest) (models.LogLevel, error)
+	ResetLogLevel(ctx context.Context) (models.LogLevel, error)
+}
+
+func NewLogLevelService(p Policy) LogLevelService {
+	return LogLevelServiceImpl{
+		policy: p,
+	}
+}
+
+func (a LogLevelServiceImpl) GetLogLevel(ctx context.Context) (models.LogLevel, error) {
+	logger.FromContext(ctx).Info("In func() GetLogLevel :: SERVICE LAYER")
+	if err := authorize(ctx, a.policy, ActionManageLogging); err != nil {
+		return models.LogLevel{}, err
+	}
+	return logLevel(logger.CurrentLevel()), nil
+}
+
+// SetLogLevel overrides the log level for the TTL of the request, replacing the current override
+func (a LogLevelServiceImpl) SetLogLevel(ctx context.Context, req *request.LogLevelRequest) (models.LogLevel, error) {
+	logger.FromContext(ctx).Info("In func() SetLogLevel :: SERVICE LAYER")
+	if err := authorize(ctx, a.policy, ActionManageLogging); err != nil {
+		return models.LogLevel{}, err
+	}
+	ttl := time.Duration(req.TTLSeconds) * time.Second
+	if ttl == 0 {
+		ttl = defaultLogLevelTTL
+	}
+	status, err := logger.OverrideLevel(req.Level, ttl)
+	if errors.Is(err, logger.ErrLevelUnsupported) {
+		return models.LogLevel{}, err
+	}
+	if err != nil {
+		return models.LogLevel{}, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	// logged as a warning so that the change shows whatever the new level
+	logger.FromContext(ctx).With("level", req.Level, "ttl", ttl.String(), "actor", audit.FromContext(ctx).Actor).
+		Warn("log level overridden")
+	return logLevel(status), nil
+}
+
+// ResetLogLevel ends the override before it expires
+func (a LogLevelServiceImpl) ResetLogLevel(ctx context.Context) (models.LogLevel, error) {
+	logger.FromContext(ctx).Info("In func() ResetLogLevel :: SERVICE LAYER")
+	if err := authorize(ctx, a.policy, ActionManageLogging); err != nil {
+		return models.LogLevel{}, err
+	}
+	status, err := logger.ResetLevel()
+	if err != nil {
+		return models.LogLevel{}, err
+	}
+	logger.FromContext(ctx).With("level", status.Level, "actor", audit.FromContext(ctx).Actor).
+		Warn("log level override reset")
+	return logLevel(status), nil
+}
+
+func logLevel(status logger.LevelStatus) models.LogLevel {
+	level := models.LogLevel{Level: status.Level, ConfiguredLevel: status.Configured}
+	if !status.OverrideUntil.IsZero() {
+		until := status.OverrideUntil.UTC()
+		level.OverrideUntil = &until
+	}
+	return level
+}
@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/audit"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mock "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+// levelController accepts the levels of the log level requests
+type levelController struct{}
+
+func (levelController) SetLevel(level string) error {
+	switch level {
+	case "debug", "info", "warn", "error":
+		return nil
+	}
+	return fmt.Errorf("unrecognized level: %q", level)
+}
+
+func TestSetLogLevel(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	logger.SetLevelController(levelController{}, "info")
+	logLevelServiceImpl := service.NewLogLevelService(service.NewRolePolicy())
+	admin := audit.NewContext(asPrincipal("ada", "admin"), audit.Meta{Actor: "ada"})
+
+	//the override lasts 15 minutes unless the request says otherwise
+	mockLogger.EXPECT().Info("In func() SetLogLevel :: SERVICE LAYER")
+	mockLogger.EXPECT().With("level", "debug", "ttl", "15m0s", "actor", "ada").Return(mockLogger)
+	mockLogger.EXPECT().Warn("log level overridden")
+	level, err := logLevelServiceImpl.SetLogLevel(admin, &request.LogLevelRequest{Level: "debug"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, level.Level, "debug")
+	assert.Equal(t, level.ConfiguredLevel, "info")
+	assert.Equal(t, level.OverrideUntil.After(time.Now().Add(14*time.Minute)), true)
+
+	mockLogger.EXPECT().Info("In func() GetLogLevel :: SERVICE LAYER")
+	level, err = logLevelServiceImpl.GetLogLevel(admin)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, level.Level, "debug")
+
+	mockLogger.EXPECT().Info("In func() ResetLogLevel :: SERVICE LAYER")
+	mockLogger.EXPECT().With("level", "info", "actor", "ada").Return(mockLogger)
+	mockLogger.EXPECT().Warn("log level override reset")
+	level, err = logLevelServiceImpl.ResetLogLevel(admin)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, level.Level, "info")
+	assert.Equal(t, level.OverrideUntil == nil, true)
+
+	//levels unknown to the logger are invalid requests
+	mockLogger.EXPECT().Info("In func() SetLogLevel :: SERVICE LAYER")
+	_, err = logLevelServiceImpl.SetLogLevel(admin, &request.LogLevelRequest{Level: "verbose"})
+	assert.Equal(t, errors.Is(err, service.ErrInvalidRequest), true)
+
+	//only admins may change the level
+	mockLogger.EXPECT().Info("In func() SetLogLevel :: SERVICE LAYER")
+	_, err = logLevelServiceImpl.SetLogLevel(asPrincipal("tina", "teller"), &request.LogLevelRequest{Level: "debug"})
+	assert.Equal(t, errors.Is(err, service.ErrForbidden), true)
+	assert.Equal(t, logger.CurrentLevel().Level, "info")
+}
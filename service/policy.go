@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rahul-024/fund-transfer-poc/auth"
+)
+
+const (
+	// RolesClaim is the token claim granting roles to the caller, either a list or a space separated string
+	RolesClaim = "roles"
+	// ScopesClaim is the claim granting scopes to the caller, in the same formats
+	ScopesClaim = "scope"
+	// OwnerClaim names the owner whose accounts the caller acts on when it is restricted to its own, the
+	// subject when absent
+	OwnerClaim = "owner"
+)
+
+// Role is granted to callers by their token
+type Role string
+
+const (
+	// RoleCustomer may open, read and transfer out of the accounts it owns
+	RoleCustomer Role = "customer"
+	// RoleTeller may operate on every account
+	RoleTeller Role = "teller"
+	// RoleAdmin may do everything a teller does, close accounts, change their limits and manage the platform
+	RoleAdmin Role = "admin"
+)
+
+// Action is an operation of the services subject to authorization
+type Action string
+
+const (
+	ActionOpenAccount    Action = "account:open"
+	ActionReadAccount    Action = "account:read"
+	ActionUpdateAccount  Action = "account:update"
+	ActionCloseAccount   Action = "account:close"
+	ActionAdjustBalance  Action = "account:adjust"
+	ActionChangeLimits   Action = "account:limits"
+	ActionTransfer       Action = "transfer:create"
+	ActionPostLedger     Action = "ledger:post"
+	ActionReconcile      Action = "ledger:reconcile"
+	ActionReadAudit      Action = "audit:read"
+	ActionManageWebhooks Action = "webhook:manage"
+	ActionManageAPIKeys  Action = "apikey:manage"
+	ActionManageLogging  Action = "logging:manage"
+)
+
+// Scopes grant actions to callers without a role, the API keys of machine clients and the partners, on the
+// resources they own. They narrow what the roles of a caller allow and never widen it.
+const (
+	ScopeAccountsRead   = "accounts:read"
+	ScopeAccountsWrite  = "accounts:write"
+	ScopeTransfersWrite = "transfers:write"
+	ScopeAuditRead      = "audit:read"
+	ScopeWebhooksManage = "webhooks:manage"
+)
+
+// scopeGrants lists the actions granted by each scope
+var scopeGrants = map[string][]Action{
+	ScopeAccountsRead:   {ActionReadAccount},
+	ScopeAccountsWrite:  {ActionOpenAccount, ActionUpdateAccount},
+	ScopeTransfersWrite: {ActionTransfer},
+	ScopeAuditRead:      {ActionReadAudit},
+	ScopeWebhooksManage: {ActionManageWebhooks},
+}
+
+// IsKnownScope returns true for the scopes the policy grants actions for
+func IsKnownScope(scope string) bool {
+	_, ok := scopeGrants[scope]
+	return ok
+}
+
+// Policy decides what the caller of a request may do. The services consult it on every operation, so that
+// the REST, gRPC and GraphQL APIs enforce the same rules.
+type Policy interface {
+	// Scope returns the owner whose resources the caller is restricted to for action, empty when the caller
+	// may act on every resource, ErrForbidden when it may not perform the action at all and ErrUnauthenticated
+	// when there is no caller
+	Scope(ctx context.Context, action Action) (owner string, err error)
+}
+
+// grant lists the roles allowed to perform an action on every resource, and whether customers may perform
+// it on the resources they own
+type grant struct {
+	roles []Role
+	owned bool
+}
+
+// grants is the role and ownership policy of the service
+var grants = map[Action]grant{
+	ActionOpenAccount:    {roles: []Role{RoleTeller, RoleAdmin}, owned: true},
+	ActionReadAccount:    {roles: []Role{RoleTeller, RoleAdmin}, owned: true},
+	ActionUpdateAccount:  {roles: []Role{RoleTeller, RoleAdmin}},
+	ActionCloseAccount:   {roles: []Role{RoleAdmin}},
+	ActionAdjustBalance:  {roles: []Role{RoleTeller, RoleAdmin}},
+	ActionChangeLimits:   {roles: []Role{RoleAdmin}},
+	ActionTransfer:       {roles: []Role{RoleTeller, RoleAdmin}, owned: true},
+	ActionPostLedger:     {roles: []Role{RoleTeller, RoleAdmin}},
+	ActionReconcile:      {roles: []Role{RoleAdmin}},
+	ActionReadAudit:      {roles: []Role{RoleTeller, RoleAdmin}},
+	ActionManageWebhooks: {roles: []Role{RoleAdmin}},
+	ActionManageAPIKeys:  {roles: []Role{RoleAdmin}},
+	ActionManageLogging:  {roles: []Role{RoleAdmin}},
+}
+
+type rolePolicy struct{}
+
+// NewRolePolicy returns the policy granting actions by the roles and scopes claims of the caller and the owner
+// of the accounts. Scopes grant actions on their own resources to callers without a role and narrow the
+// actions of the others. Contexts without a principal are denied everything with ErrUnauthenticated, trusted callers
+// run as a SystemPrincipal.
+func NewRolePolicy() Policy {
+	return rolePolicy{}
+}
+
+// SystemPrincipal returns the admin principal of the trusted callers: the command line tools and the servers
+// running with authentication disabled
+func SystemPrincipal(subject string) auth.Principal {
+	return auth.Principal{
+		Subject: subject,
+		Claims:  map[string]interface{}{"sub": subject, RolesClaim: []string{string(RoleAdmin)}},
+	}
+}
+
+func (rolePolicy) Scope(ctx context.Context, action Action) (string, error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return "", fmt.Errorf("%w: no caller for %s", ErrUnauthenticated, action)
+	}
+	scopes := knownScopes(principal)
+	if len(scopes) > 0 && !scopesGrant(scopes, action) {
+		return "", fmt.Errorf("%w: the scopes of %s do not grant %s", ErrForbidden, principal.Subject, action)
+	}
+	roles := RolesOf(principal)
+	g := grants[action]
+	for _, role := range g.roles {
+		if roles[role] {
+			return "", nil
+		}
+	}
+	if g.owned && roles[RoleCustomer] {
+		return OwnerOf(principal), nil
+	}
+	// callers without a role act on their own resources with their scopes
+	if len(roles) == 0 && len(scopes) > 0 {
+		return OwnerOf(principal), nil
+	}
+	return "", fmt.Errorf("%w: %s cannot perform %s", ErrForbidden, principal.Subject, action)
+}
+
+// knownScopes returns the scopes of principal the policy grants actions for, the scopes of the identity
+// provider like openid are ignored
+func knownScopes(principal auth.Principal) []string {
+	var scopes []string
+	for _, scope := range claimValues(principal, ScopesClaim) {
+		if IsKnownScope(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// scopesGrant returns true when one of scopes grants action
+func scopesGrant(scopes []string, action Action) bool {
+	for _, scope := range scopes {
+		for _, granted := range scopeGrants[scope] {
+			if granted == action {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// OwnerOf returns the owner whose resources principal acts on when it is restricted to its own
+func OwnerOf(principal auth.Principal) string {
+	if owner, ok := principal.Claims[OwnerClaim].(string); ok && owner != "" {
+		return owner
+	}
+	return principal.Subject
+}
+
+// RolesOf returns the roles of the roles claim of principal, unknown roles are ignored
+func RolesOf(principal auth.Principal) map[Role]bool {
+	names := claimValues(principal, RolesClaim)
+	roles := make(map[Role]bool, len(names))
+	for _, name := range names {
+		switch role := Role(name); role {
+		case RoleCustomer, RoleTeller, RoleAdmin:
+			roles[role] = true
+		}
+	}
+	return roles
+}
+
+// claimValues returns the values of a claim holding either a list or a space separated string
+func claimValues(principal auth.Principal, claim string) []string {
+	var values []string
+	switch value := principal.Claims[claim].(type) {
+	case string:
+		values = strings.Fields(value)
+	case []string:
+		values = value
+	case []interface{}:
+		for _, v := range value {
+			if v, ok := v.(string); ok {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// authorize returns ErrForbidden unless the caller may perform action on a resource of one of owners,
+// or on every resource when no owner is given
+func authorize(ctx context.Context, policy Policy, action Action, owners ...string) error {
+	scope, err := policy.Scope(ctx, action)
+	if err != nil || scope == "" {
+		return err
+	}
+	for _, owner := range owners {
+		if owner == scope {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s does not own the resource", ErrForbidden, scope)
+}
@@ -0,0 +1,122 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rahul-024/fund-transfer-poc/auth"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func asPrincipal(subject string, roles interface{}) context.Context {
+	return auth.NewContext(context.Background(), auth.Principal{
+		Subject: subject,
+		Claims:  map[string]interface{}{"sub": subject, service.RolesClaim: roles},
+	})
+}
+
+// asSystem returns the context of a trusted caller, like the command line tools
+func asSystem() context.Context {
+	return auth.NewContext(context.Background(), service.SystemPrincipal("cli:test"))
+}
+
+func TestRolePolicyScope(t *testing.T) {
+	const (
+		all    = "all"
+		owned  = "owned"
+		denied = "denied"
+	)
+	// expected scope of customer, teller and admin
+	policy := map[service.Action][3]string{
+		service.ActionOpenAccount:    {owned, all, all},
+		service.ActionReadAccount:    {owned, all, all},
+		service.ActionUpdateAccount:  {denied, all, all},
+		service.ActionCloseAccount:   {denied, denied, all},
+		service.ActionAdjustBalance:  {denied, all, all},
+		service.ActionChangeLimits:   {denied, denied, all},
+		service.ActionTransfer:       {owned, all, all},
+		service.ActionPostLedger:     {denied, all, all},
+		service.ActionReconcile:      {denied, denied, all},
+		service.ActionReadAudit:      {denied, all, all},
+		service.ActionManageWebhooks: {denied, denied, all},
+		service.ActionManageLogging:  {denied, denied, all},
+	}
+	rolePolicy := service.NewRolePolicy()
+	for action, expected := range policy {
+		for i, role := range []service.Role{service.RoleCustomer, service.RoleTeller, service.RoleAdmin} {
+			owner, err := rolePolicy.Scope(asPrincipal("alice", []interface{}{string(role)}), action)
+			var scope string
+			switch {
+			case errors.Is(err, service.ErrForbidden):
+				scope = denied
+			case err != nil:
+				t.Fatalf("Unexpected error for %s as %s: %v", action, role, err)
+			case owner == "alice":
+				scope = owned
+			case owner == "":
+				scope = all
+			}
+			if scope != expected[i] {
+				t.Errorf("Expected %s to be %s for %s, got %s", action, expected[i], role, scope)
+			}
+		}
+		//a token without a known role is denied everything
+		_, err := rolePolicy.Scope(asPrincipal("alice", []interface{}{"auditor"}), action)
+		assert.Equal(t, errors.Is(err, service.ErrForbidden), true)
+		//a context without a principal is denied everything
+		_, err = rolePolicy.Scope(context.Background(), action)
+		assert.Equal(t, errors.Is(err, service.ErrUnauthenticated), true)
+		assert.Equal(t, service.KindOf(err), service.KindUnauthenticated)
+		//trusted callers run as the system principal
+		owner, err := rolePolicy.Scope(asSystem(), action)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, owner, "")
+	}
+}
+
+func TestScopesNarrowRoles(t *testing.T) {
+	rolePolicy := service.NewRolePolicy()
+	withScopes := func(subject string, roles string, scopes string) context.Context {
+		return auth.NewContext(context.Background(), auth.Principal{
+			Subject: subject,
+			Claims:  map[string]interface{}{"sub": subject, service.RolesClaim: roles, service.ScopesClaim: scopes},
+		})
+	}
+
+	//scopes do not let a customer act on the accounts of others
+	customer := withScopes("alice", "customer", service.ScopeTransfersWrite+" "+service.ScopeAccountsWrite)
+	owner, err := rolePolicy.Scope(customer, service.ActionTransfer)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, owner, "alice")
+	owner, err = rolePolicy.Scope(customer, service.ActionOpenAccount)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, owner, "alice")
+	//nor perform the actions the customer role does not grant
+	_, err = rolePolicy.Scope(customer, service.ActionUpdateAccount)
+	assert.Equal(t, errors.Is(err, service.ErrForbidden), true)
+	//the scopes leave out the actions they do not grant
+	_, err = rolePolicy.Scope(customer, service.ActionReadAccount)
+	assert.Equal(t, errors.Is(err, service.ErrForbidden), true)
+
+	teller := withScopes("tina", "teller", service.ScopeAccountsRead)
+	owner, err = rolePolicy.Scope(teller, service.ActionReadAccount)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, owner, "")
+	_, err = rolePolicy.Scope(teller, service.ActionTransfer)
+	assert.Equal(t, errors.Is(err, service.ErrForbidden), true)
+
+	//the scopes of the identity provider are ignored
+	owner, err = rolePolicy.Scope(withScopes("alice", "customer", "openid profile"), service.ActionReadAccount)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, owner, "alice")
+}
+
+func TestRolesOf(t *testing.T) {
+	roles := service.RolesOf(auth.Principal{Claims: map[string]interface{}{service.RolesClaim: "customer  teller unknown"}})
+	assert.Equal(t, roles, map[service.Role]bool{service.RoleCustomer: true, service.RoleTeller: true})
+	roles = service.RolesOf(auth.Principal{Claims: map[string]interface{}{service.RolesClaim: []interface{}{"admin", 42}}})
+	assert.Equal(t, roles, map[service.Role]bool{service.RoleAdmin: true})
+	assert.Equal(t, len(service.RolesOf(auth.Principal{})), 0)
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"expvar"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgconn"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"gorm.io/gorm"
+)
+
+// UnitOfWork runs a function in a database transaction, services bind their repositories to the transaction
+// handed to the function so that everything it writes commits or rolls back together
+type UnitOfWork interface {
+	// RunInTx commits when fn returns nil and rolls back when it returns an error or panics.
+	// fn may run more than once when the transaction is retried, so it must not have effects outside of it.
+	// The transaction begins with opts, with the defaults of the database when nil.
+	RunInTx(ctx context.Context, opts *sql.TxOptions, fn func(tx *gorm.DB) error) error
+}
+
+// The outcomes of the retries, published on /debug/vars of the default http mux
+var (
+	// RetriedTransactions counts the transactions run again after the database aborted them
+	RetriedTransactions = expvar.NewInt("transactions_retried")
+	// ExhaustedTransactions counts the transactions given up on once the attempts ran out
+	ExhaustedTransactions = expvar.NewInt("transactions_retries_exhausted")
+)
+
+// RetryPolicy re-runs a unit of work the database aborted in favour of a concurrent transaction
+type RetryPolicy struct {
+	// MaxAttempts counts the first run as well, retries are disabled below 2
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// jitter draws the backoffs, rand.Rand is not safe for concurrent use
+var (
+	jitter   = rand.New(rand.NewSource(time.Now().UnixNano()))
+	jitterMu sync.Mutex
+)
+
+// backoff returns a random wait between zero and the initial backoff doubled for every further attempt, capped at
+// the max backoff. The jitter keeps transactions that conflicted with each other from colliding again.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.InitialBackoff
+	for i := 1; i < attempt && ceiling < p.MaxBackoff; i++ {
+		ceiling *= 2
+	}
+	if p.MaxBackoff > 0 && ceiling > p.MaxBackoff {
+		ceiling = p.MaxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return time.Duration(jitter.Int63n(int64(ceiling) + 1))
+}
+
+type UnitOfWorkImpl struct {
+	DB    *gorm.DB
+	Retry RetryPolicy
+}
+
+func NewUnitOfWork(db *gorm.DB, retry RetryPolicy) UnitOfWork {
+	return UnitOfWorkImpl{DB: db, Retry: retry}
+}
+
+// RunInTx re-runs the whole transaction while it fails on a serialization failure or a deadlock and attempts are left.
+// Once they run out the failure is returned as ErrTransactionConflict.
+func (u UnitOfWorkImpl) RunInTx(ctx context.Context, opts *sql.TxOptions, fn func(tx *gorm.DB) error) error {
+	logger.FromContext(ctx).Info("In func() RunInTx :: SERVICE LAYER")
+	var txOpts []*sql.TxOptions
+	if opts != nil {
+		txOpts = append(txOpts, opts)
+	}
+	for attempt := 1; ; attempt++ {
+		err := u.DB.WithContext(ctx).Transaction(fn, txOpts...)
+		if err == nil || !IsRetryable(err) {
+			return err
+		}
+		if attempt >= u.Retry.MaxAttempts {
+			ExhaustedTransactions.Add(1)
+			return ErrTransactionConflict.wrap(err)
+		}
+		RetriedTransactions.Add(1)
+		wait := u.Retry.backoff(attempt)
+		logger.FromContext(ctx).With("attempt", attempt, "max_attempts", u.Retry.MaxAttempts, "backoff", wait, "error", err).
+			Warn("transaction aborted, retrying")
+		select {
+		case <-ctx.Done():
+			return ErrTransactionConflict.wrap(err)
+		case <-time.After(wait):
+		}
+	}
+}
+
+// IsRetryable reports whether the database aborted the transaction because of a concurrent one, so that running it
+// again can succeed: serialization failures and deadlocks on postgres, deadlocks on mysql
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1213
+	}
+	return false
+}
+
+// ParseIsolationLevel maps the isolation levels accepted in the profiles to database/sql, empty keeps the default
+func ParseIsolationLevel(level string) (sql.IsolationLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "":
+		return sql.LevelDefault, nil
+	case "read committed":
+		return sql.LevelReadCommitted, nil
+	case "repeatable read":
+		return sql.LevelRepeatableRead, nil
+	case "serializable":
+		return sql.LevelSerializable, nil
+	}
+	return sql.LevelDefault, fmt.Errorf("unsupported isolation level %q", level)
+}
@@ -0,0 +1,161 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgconn"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mock "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"gopkg.in/go-playground/assert.v1"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const sqlDecrementBalance = `UPDATE accounts SET balance = balance - $1 WHERE id = $2`
+
+func newUnitOfWork(t *testing.T, maxAttempts int) (service.UnitOfWork, sqlmock.Sqlmock, *mock.MockLogger) {
+	mockCtrl := gomock.NewController(t)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	db, sqlMock, _ := sqlmock.New()
+	gdb, _ := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	retry := service.RetryPolicy{MaxAttempts: maxAttempts, InitialBackoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond}
+	return service.NewUnitOfWork(gdb, retry), sqlMock, mockLogger
+}
+
+func decrementBalance(tx *gorm.DB) error {
+	return tx.Exec("UPDATE accounts SET balance = balance - ? WHERE id = ?", 10, 1).Error
+}
+
+func TestRunInTxRetriesSerializationFailuresAndDeadlocks(t *testing.T) {
+	uow, sqlMock, mockLogger := newUnitOfWork(t, 3)
+	mockLogger.EXPECT().Info("In func() RunInTx :: SERVICE LAYER")
+	mockLogger.EXPECT().With(gomock.Any()).Return(mockLogger).Times(2)
+	mockLogger.EXPECT().Warn("transaction aborted, retrying").Times(2)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta(sqlDecrementBalance)).WithArgs(10, 1).
+		WillReturnError(&pgconn.PgError{Code: "40001", Message: "could not serialize access due to concurrent update"})
+	sqlMock.ExpectRollback()
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta(sqlDecrementBalance)).WithArgs(10, 1).
+		WillReturnError(&pgconn.PgError{Code: "40P01", Message: "deadlock detected"})
+	sqlMock.ExpectRollback()
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta(sqlDecrementBalance)).WithArgs(10, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectCommit()
+
+	retried, exhausted := service.RetriedTransactions.Value(), service.ExhaustedTransactions.Value()
+	runs := 0
+	err := uow.RunInTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable}, func(tx *gorm.DB) error {
+		runs++
+		return decrementBalance(tx)
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, runs, 3)
+	assert.Equal(t, service.RetriedTransactions.Value()-retried, int64(2))
+	assert.Equal(t, service.ExhaustedTransactions.Value()-exhausted, int64(0))
+	if err = sqlMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRunInTxGivesUpAfterMaxAttempts(t *testing.T) {
+	uow, sqlMock, mockLogger := newUnitOfWork(t, 2)
+	mockLogger.EXPECT().Info("In func() RunInTx :: SERVICE LAYER")
+	mockLogger.EXPECT().With(gomock.Any()).Return(mockLogger).Times(1)
+	mockLogger.EXPECT().Warn("transaction aborted, retrying").Times(1)
+	for i := 0; i < 2; i++ {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(regexp.QuoteMeta(sqlDecrementBalance)).WithArgs(10, 1).
+			WillReturnError(&pgconn.PgError{Code: "40001"})
+		sqlMock.ExpectRollback()
+	}
+
+	retried, exhausted := service.RetriedTransactions.Value(), service.ExhaustedTransactions.Value()
+	err := uow.RunInTx(context.Background(), nil, decrementBalance)
+	var pgErr *pgconn.PgError
+	assert.Equal(t, errors.As(err, &pgErr), true)
+	assert.Equal(t, pgErr.Code, "40001")
+	assert.Equal(t, service.RetriedTransactions.Value()-retried, int64(1))
+	assert.Equal(t, service.ExhaustedTransactions.Value()-exhausted, int64(1))
+	if err = sqlMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRunInTxDoesNotRetryOtherErrors(t *testing.T) {
+	uow, sqlMock, mockLogger := newUnitOfWork(t, 3)
+	mockLogger.EXPECT().Info("In func() RunInTx :: SERVICE LAYER")
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta(sqlDecrementBalance)).WithArgs(10, 1).
+		WillReturnError(&pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"})
+	sqlMock.ExpectRollback()
+
+	err := uow.RunInTx(context.Background(), nil, decrementBalance)
+	assert.NotEqual(t, err, nil)
+
+	//a failing function is not retried either
+	mockLogger.EXPECT().Info("In func() RunInTx :: SERVICE LAYER")
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectRollback()
+	err = uow.RunInTx(context.Background(), nil, func(tx *gorm.DB) error {
+		return service.ErrInvalidAdjustment
+	})
+	assert.Equal(t, err, service.ErrInvalidAdjustment)
+	if err = sqlMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRunInTxStopsRetryingWhenContextIsDone(t *testing.T) {
+	uow, sqlMock, mockLogger := newUnitOfWork(t, 5)
+	mockLogger.EXPECT().Info("In func() RunInTx :: SERVICE LAYER")
+	ctx, cancel := context.WithCancel(context.Background())
+	//the request goes away while the transaction waits for its retry
+	mockLogger.EXPECT().With(gomock.Any()).Return(mockLogger).Times(1)
+	mockLogger.EXPECT().Warn("transaction aborted, retrying").Times(1).Do(func(...interface{}) { cancel() })
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta(sqlDecrementBalance)).WithArgs(10, 1).
+		WillReturnError(&pgconn.PgError{Code: "40P01"})
+	sqlMock.ExpectRollback()
+
+	err := uow.RunInTx(ctx, nil, decrementBalance)
+	var pgErr *pgconn.PgError
+	assert.Equal(t, errors.As(err, &pgErr), true)
+	if err = sqlMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("There were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.Equal(t, service.IsRetryable(&pgconn.PgError{Code: "40001"}), true)
+	assert.Equal(t, service.IsRetryable(&pgconn.PgError{Code: "40P01"}), true)
+	assert.Equal(t, service.IsRetryable(&pgconn.PgError{Code: "23505"}), false)
+	assert.Equal(t, service.IsRetryable(&mysql.MySQLError{Number: 1213}), true)
+	assert.Equal(t, service.IsRetryable(&mysql.MySQLError{Number: 1062}), false)
+	assert.Equal(t, service.IsRetryable(gorm.ErrRecordNotFound), false)
+}
+
+func TestParseIsolationLevel(t *testing.T) {
+	level, err := service.ParseIsolationLevel("")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, level, sql.LevelDefault)
+	level, err = service.ParseIsolationLevel("Serializable")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, level, sql.LevelSerializable)
+	level, err = service.ParseIsolationLevel("repeatable read")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, level, sql.LevelRepeatableRead)
+	_, err = service.ParseIsolationLevel("snapshot")
+	assert.NotEqual(t, err, nil)
+}
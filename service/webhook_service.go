@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/outbox"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"github.com/rahul-024/fund-transfer-poc/webhook"
+)
+
+// minSecretLength keeps partner supplied secrets strong enough for HMAC-SHA256
+const minSecretLength = 16
+
+type WebhookServiceImpl struct {
+	webhookRepository repository.WebhookRepository
+	policy            Policy
+}
+
+type WebhookService interface {
+	CreateSubscription(ctx context.Context, req *request.CreateWebhookSubscriptionRequest) (models.WebhookSubscription, error)
+	GetSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	GetSubscriptionById(ctx context.Context, id int) (models.WebhookSubscription, error)
+	DeleteSubscriptionById(ctx context.Context, id int) error
+	GetDeliveries(ctx context.Context, subscriptionID int) ([]models.WebhookDelivery, error)
+	GetDeliveryAttempts(ctx context.Context, subscriptionID int, deliveryID int) ([]models.WebhookDeliveryAttempt, error)
+	Redeliver(ctx context.Context, subscriptionID int, deliveryID int) (models.WebhookDelivery, error)
+}
+
+func NewWebhookService(r repository.WebhookRepository, p Policy) WebhookService {
+	return WebhookServiceImpl{
+		webhookRepository: r,
+		policy:            p,
+	}
+}
+
+// CreateSubscription registers a subscription, the subscriptions of callers restricted to their own accounts
+// only receive the events of those accounts
+func (w WebhookServiceImpl) CreateSubscription(ctx context.Context, req *request.CreateWebhookSubscriptionRequest) (models.WebhookSubscription, error) {
+	logger.FromContext(ctx).Info("In func() CreateSubscription :: SERVICE LAYER")
+	owner, err := w.policy.Scope(ctx, ActionManageWebhooks)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	target, err := url.Parse(req.Url)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return models.WebhookSubscription{}, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidSubscription)
+	}
+	// the dispatcher checks the address again when dialling, the host may be re-pointed later
+	if err = webhook.CheckHost(ctx, target.Hostname()); err != nil {
+		return models.WebhookSubscription{}, fmt.Errorf("%w: %v", ErrInvalidSubscription, err)
+	}
+	knownTypes := outbox.EventTypes()
+	for _, eventType := range req.EventTypes {
+		if _, ok := knownTypes[eventType]; !ok && eventType != "*" {
+			return models.WebhookSubscription{}, fmt.Errorf("%w: unknown event type %q", ErrInvalidSubscription, eventType)
+		}
+	}
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			return models.WebhookSubscription{}, err
+		}
+	} else if len(secret) < minSecretLength {
+		return models.WebhookSubscription{}, fmt.Errorf("%w: secret must have at least %d characters",
+			ErrInvalidSubscription, minSecretLength)
+	}
+	subscription := models.WebhookSubscription{
+		Url:        req.Url,
+		EventTypes: req.EventTypes,
+		Owner:      owner,
+		Secret:     secret,
+		Active:     true,
+	}
+	err = w.webhookRepository.SaveWebhookSubscription(ctx, &subscription)
+	return subscription, err
+}
+
+// GetSubscriptions lists the subscriptions the caller manages without their secrets
+func (w WebhookServiceImpl) GetSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	logger.FromContext(ctx).Info("In func() GetSubscriptions :: SERVICE LAYER")
+	owner, err := w.policy.Scope(ctx, ActionManageWebhooks)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := w.webhookRepository.GetWebhookSubscriptions(ctx)
+	managed := []models.WebhookSubscription{}
+	for _, subscription := range subscriptions {
+		if owner == "" || subscription.Owner == owner {
+			subscription.Secret = ""
+			managed = append(managed, subscription)
+		}
+	}
+	return managed, err
+}
+
+// GetSubscriptionById returns the subscription without its secret
+func (w WebhookServiceImpl) GetSubscriptionById(ctx context.Context, id int) (models.WebhookSubscription, error) {
+	logger.FromContext(ctx).Info("In func() GetSubscriptionById :: SERVICE LAYER")
+	subscription, err := w.getSubscription(ctx, id)
+	subscription.Secret = ""
+	return subscription, err
+}
+
+func (w WebhookServiceImpl) DeleteSubscriptionById(ctx context.Context, id int) error {
+	logger.FromContext(ctx).Info("In func() DeleteSubscriptionById :: SERVICE LAYER")
+	if _, err := w.getSubscription(ctx, id); err != nil {
+		return err
+	}
+	return notFound(w.webhookRepository.DeleteWebhookSubscriptionById(ctx, id), ErrSubscriptionNotFound)
+}
+
+func (w WebhookServiceImpl) GetDeliveries(ctx context.Context, subscriptionID int) ([]models.WebhookDelivery, error) {
+	logger.FromContext(ctx).Info("In func() GetDeliveries :: SERVICE LAYER")
+	if _, err := w.getSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	deliveries, err := w.webhookRepository.GetWebhookDeliveries(ctx, subscriptionID)
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+	return deliveries, err
+}
+
+func (w WebhookServiceImpl) GetDeliveryAttempts(ctx context.Context, subscriptionID int, deliveryID int) ([]models.WebhookDeliveryAttempt, error) {
+	logger.FromContext(ctx).Info("In func() GetDeliveryAttempts :: SERVICE LAYER")
+	if _, err := w.getDelivery(ctx, subscriptionID, deliveryID); err != nil {
+		return nil, err
+	}
+	attempts, err := w.webhookRepository.GetWebhookDeliveryAttempts(ctx, deliveryID)
+	if attempts == nil {
+		attempts = []models.WebhookDeliveryAttempt{}
+	}
+	return attempts, err
+}
+
+// Redeliver queues a delivery again regardless of its status, the retry budget starts over
+func (w WebhookServiceImpl) Redeliver(ctx context.Context, subscriptionID int, deliveryID int) (models.WebhookDelivery, error) {
+	logger.FromContext(ctx).Info("In func() Redeliver :: SERVICE LAYER")
+	delivery, err := w.getDelivery(ctx, subscriptionID, deliveryID)
+	if err != nil {
+		return delivery, err
+	}
+	delivery.Status = models.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	err = w.webhookRepository.UpdateWebhookDelivery(ctx, &delivery)
+	return delivery, err
+}
+
+// getSubscription loads a subscription the caller manages, the subscriptions of other owners are not found
+func (w WebhookServiceImpl) getSubscription(ctx context.Context, id int) (models.WebhookSubscription, error) {
+	owner, err := w.policy.Scope(ctx, ActionManageWebhooks)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	subscription, err := w.webhookRepository.GetWebhookSubscriptionById(ctx, id)
+	if err != nil {
+		return subscription, notFound(err, ErrSubscriptionNotFound)
+	}
+	if owner != "" && subscription.Owner != owner {
+		return models.WebhookSubscription{}, ErrSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+// getDelivery loads a delivery of a subscription the caller manages and makes sure it belongs to the subscription
+func (w WebhookServiceImpl) getDelivery(ctx context.Context, subscriptionID int, deliveryID int) (models.WebhookDelivery, error) {
+	if _, err := w.getSubscription(ctx, subscriptionID); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	delivery, err := w.webhookRepository.GetWebhookDeliveryById(ctx, deliveryID)
+	if err != nil {
+		return delivery, notFound(err, ErrDeliveryNotFound)
+	}
+	if delivery.SubscriptionID != subscriptionID {
+		return models.WebhookDelivery{}, ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+// generateSecret returns a random signing secret
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,133 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/auth"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mock "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/models/request"
+	"github.com/rahul-024/fund-transfer-poc/service"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestCreateSubscription(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockWebhookRepo := mock.NewMockWebhookRepository(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	webhookServiceImpl := service.NewWebhookService(mockWebhookRepo, service.NewRolePolicy())
+
+	mockLogger.EXPECT().Info("In func() CreateSubscription :: SERVICE LAYER")
+	mockWebhookRepo.EXPECT().SaveWebhookSubscription(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	subscription, err := webhookServiceImpl.CreateSubscription(asSystem(), &request.CreateWebhookSubscriptionRequest{
+		Url: "https://203.0.113.10/hooks", EventTypes: []string{"TransferCompleted"},
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, subscription.Active, true)
+	assert.Equal(t, strings.HasPrefix(subscription.Secret, "whsec_"), true)
+
+	rejected := []request.CreateWebhookSubscriptionRequest{
+		{Url: "partner.example/hooks", EventTypes: []string{"*"}},
+		{Url: "ftp://partner.example/hooks", EventTypes: []string{"*"}},
+		{Url: "https://203.0.113.10/hooks", EventTypes: []string{"TransferFailed"}},
+		{Url: "https://203.0.113.10/hooks", EventTypes: []string{"*"}, Secret: "short"},
+		//the internal network is out of reach
+		{Url: "http://127.0.0.1:8080/hooks", EventTypes: []string{"*"}},
+		{Url: "http://localhost/hooks", EventTypes: []string{"*"}},
+		{Url: "http://169.254.169.254/latest/meta-data", EventTypes: []string{"*"}},
+		{Url: "https://10.0.0.5/hooks", EventTypes: []string{"*"}},
+		{Url: "https://172.16.4.2/hooks", EventTypes: []string{"*"}},
+		{Url: "https://192.168.1.10/hooks", EventTypes: []string{"*"}},
+		{Url: "https://[::1]/hooks", EventTypes: []string{"*"}},
+	}
+	for _, req := range rejected {
+		mockLogger.EXPECT().Info("In func() CreateSubscription :: SERVICE LAYER")
+		_, err = webhookServiceImpl.CreateSubscription(asSystem(), &req)
+		if !errors.Is(err, service.ErrInvalidSubscription) {
+			t.Errorf("Expected ErrInvalidSubscription for %+v, got %v", req, err)
+		}
+	}
+}
+
+func TestGetSubscriptionsHidesSecrets(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockWebhookRepo := mock.NewMockWebhookRepository(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info("In func() GetSubscriptions :: SERVICE LAYER")
+	mockWebhookRepo.EXPECT().GetWebhookSubscriptions(gomock.Any()).
+		Return([]models.WebhookSubscription{{Id: 1, Secret: "whsec_0123456789abcdef"}}, nil).Times(1)
+	subscriptions, err := service.NewWebhookService(mockWebhookRepo, service.NewRolePolicy()).GetSubscriptions(asSystem())
+	assert.Equal(t, err, nil)
+	assert.Equal(t, subscriptions[0].Secret, "")
+}
+
+func TestRedeliver(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockWebhookRepo := mock.NewMockWebhookRepository(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	webhookServiceImpl := service.NewWebhookService(mockWebhookRepo, service.NewRolePolicy())
+
+	mockWebhookRepo.EXPECT().GetWebhookSubscriptionById(gomock.Any(), 1).Return(models.WebhookSubscription{Id: 1}, nil).Times(2)
+	mockLogger.EXPECT().Info("In func() Redeliver :: SERVICE LAYER")
+	mockWebhookRepo.EXPECT().GetWebhookDeliveryById(gomock.Any(), 9).
+		Return(models.WebhookDelivery{Id: 9, SubscriptionID: 1, Status: models.DeliveryFailed, Attempts: 8}, nil)
+	mockWebhookRepo.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	delivery, err := webhookServiceImpl.Redeliver(asSystem(), 1, 9)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, delivery.Status, models.DeliveryPending)
+	assert.Equal(t, delivery.Attempts, 0)
+
+	//the delivery belongs to another subscription
+	mockLogger.EXPECT().Info("In func() Redeliver :: SERVICE LAYER")
+	mockWebhookRepo.EXPECT().GetWebhookDeliveryById(gomock.Any(), 9).
+		Return(models.WebhookDelivery{Id: 9, SubscriptionID: 2}, nil)
+	_, err = webhookServiceImpl.Redeliver(asSystem(), 1, 9)
+	assert.Equal(t, err, service.ErrDeliveryNotFound)
+}
+
+func TestSubscriptionsOfOwner(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockWebhookRepo := mock.NewMockWebhookRepository(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+	webhookServiceImpl := service.NewWebhookService(mockWebhookRepo, service.NewRolePolicy())
+	partner := auth.NewContext(context.Background(), auth.Principal{
+		Subject: "partner:acme",
+		Claims:  map[string]interface{}{service.ScopesClaim: service.ScopeWebhooksManage},
+	})
+
+	//the subscription of a partner only receives the events of its accounts
+	mockWebhookRepo.EXPECT().SaveWebhookSubscription(gomock.Any(), gomock.Any()).Return(nil)
+	subscription, err := webhookServiceImpl.CreateSubscription(partner, &request.CreateWebhookSubscriptionRequest{
+		Url: "https://203.0.113.10/hooks", EventTypes: []string{"*"},
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, subscription.Owner, "partner:acme")
+
+	//the subscriptions of the others are hidden
+	mockWebhookRepo.EXPECT().GetWebhookSubscriptions(gomock.Any()).Return([]models.WebhookSubscription{
+		{Id: 1}, {Id: 2, Owner: "partner:acme"}, {Id: 3, Owner: "partner:other"},
+	}, nil)
+	subscriptions, err := webhookServiceImpl.GetSubscriptions(partner)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(subscriptions), 1)
+	assert.Equal(t, subscriptions[0].Id, 2)
+
+	mockWebhookRepo.EXPECT().GetWebhookSubscriptionById(gomock.Any(), 3).
+		Return(models.WebhookSubscription{Id: 3, Owner: "partner:other"}, nil).Times(3)
+	_, err = webhookServiceImpl.GetSubscriptionById(partner, 3)
+	assert.Equal(t, err, service.ErrSubscriptionNotFound)
+	_, err = webhookServiceImpl.GetDeliveries(partner, 3)
+	assert.Equal(t, err, service.ErrSubscriptionNotFound)
+	err = webhookServiceImpl.DeleteSubscriptionById(partner, 3)
+	assert.Equal(t, err, service.ErrSubscriptionNotFound)
+}
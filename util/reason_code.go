@@ -0,0 +1,19 @@
+package util
+
+// Constants for all supported balance adjustment reason codes
+const (
+	OpeningBalance = "OPENING_BALANCE"
+	Correction     = "CORRECTION"
+	Fee            = "FEE"
+	Interest       = "INTEREST"
+	Reversal       = "REVERSAL"
+)
+
+// IsSupportedReasonCode returns true if the reason code is supported
+func IsSupportedReasonCode(reasonCode string) bool {
+	switch reasonCode {
+	case OpeningBalance, Correction, Fee, Interest, Reversal:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"gorm.io/gorm"
+)
+
+// DispatcherConfig holds the retry policy of the dispatcher. Lease is how long the deliveries claimed by a pass
+// are hidden from the other dispatchers, it must cover sending a whole batch.
+type DispatcherConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	PollInterval   time.Duration
+	BatchSize      int
+	Lease          time.Duration
+}
+
+// Dispatcher sends due deliveries, retrying failed ones with exponential backoff
+type Dispatcher struct {
+	webhookRepository repository.WebhookRepository
+	client            *http.Client
+	config            DispatcherConfig
+	now               func() time.Time
+}
+
+func NewDispatcher(r repository.WebhookRepository, client *http.Client, config DispatcherConfig) *Dispatcher {
+	return &Dispatcher{
+		webhookRepository: r,
+		client:            client,
+		config:            config,
+		now:               time.Now,
+	}
+}
+
+// Run sends the due deliveries every poll interval until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	logger.Log.With("poll_interval", d.config.PollInterval).Info("webhook dispatcher started")
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("webhook dispatcher stopped")
+			return
+		case <-ticker.C:
+			if _, err := d.DispatchDue(ctx); err != nil {
+				logger.Log.With("error", err).Error("webhook dispatcher pass failed")
+			}
+		}
+	}
+}
+
+// DispatchDue claims the due deliveries, makes one attempt for each and returns how many succeeded.
+// Every instance runs a dispatcher, the claim keeps them from sending the same delivery.
+func (d *Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	deliveries, err := d.webhookRepository.ClaimDueWebhookDeliveries(ctx, d.now(), d.config.Lease, d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+		ok, err := d.attempt(ctx, &deliveries[i])
+		if err != nil {
+			return delivered, err
+		}
+		if ok {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// attempt sends one delivery, records the attempt and schedules the next one on failure
+func (d *Dispatcher) attempt(ctx context.Context, delivery *models.WebhookDelivery) (bool, error) {
+	subscription, err := d.webhookRepository.GetWebhookSubscriptionById(ctx, delivery.SubscriptionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !subscription.Active) {
+		delivery.Status = models.DeliveryFailed
+		delivery.LastError = "subscription is no longer active"
+		return false, d.webhookRepository.UpdateWebhookDelivery(ctx, delivery)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	started := d.now()
+	statusCode, sendErr := d.send(ctx, subscription, delivery)
+	attempt := &models.WebhookDeliveryAttempt{
+		DeliveryID: delivery.Id,
+		StatusCode: statusCode,
+		DurationMs: d.now().Sub(started).Milliseconds(),
+	}
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+	}
+	if err = d.webhookRepository.SaveWebhookDeliveryAttempt(ctx, attempt); err != nil {
+		return false, err
+	}
+
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	if sendErr == nil {
+		deliveredAt := d.now()
+		delivery.Status = models.DeliveryDelivered
+		delivery.DeliveredAt = &deliveredAt
+		delivery.LastError = ""
+		return true, d.webhookRepository.UpdateWebhookDelivery(ctx, delivery)
+	}
+	logger.Log.With("delivery_id", delivery.Id, "subscription_id", delivery.SubscriptionID, "attempt", delivery.Attempts,
+		"status_code", statusCode, "error", sendErr).Warn("webhook delivery attempt failed")
+	delivery.LastError = sendErr.Error()
+	if delivery.Attempts >= d.config.MaxAttempts {
+		delivery.Status = models.DeliveryFailed
+	} else {
+		delivery.NextAttemptAt = d.now().Add(d.Backoff(delivery.Attempts))
+	}
+	return false, d.webhookRepository.UpdateWebhookDelivery(ctx, delivery)
+}
+
+// Backoff returns the wait after the given number of failed attempts: the initial backoff
+// doubled for every further attempt, capped at the max backoff
+func (d *Dispatcher) Backoff(attempts int) time.Duration {
+	backoff := d.config.InitialBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= d.config.MaxBackoff {
+			return d.config.MaxBackoff
+		}
+	}
+	return backoff
+}
+
+// send POSTs the payload with the signature headers and returns the response status code
+func (d *Dispatcher) send(ctx context.Context, subscription models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, delivery.Payload))
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.Itoa(delivery.Id))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,195 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rahul-024/fund-transfer-poc/logger"
+	mock "github.com/rahul-024/fund-transfer-poc/mocks"
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/webhook"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+const secret = "whsec_0123456789abcdef"
+
+var config = webhook.DispatcherConfig{
+	MaxAttempts:    3,
+	InitialBackoff: time.Second,
+	MaxBackoff:     3 * time.Second,
+	BatchSize:      10,
+	Lease:          time.Minute,
+}
+
+func TestDispatchDueSignsAndRetries(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockWebhookRepo := mock.NewMockWebhookRepository(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With(gomock.Any()).Return(mockLogger).AnyTimes()
+	mockLogger.EXPECT().Warn("webhook delivery attempt failed").AnyTimes()
+
+	status := http.StatusInternalServerError
+	verified := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if webhook.Verify(secret, r.Header.Get(webhook.HeaderTimestamp), body,
+			r.Header.Get(webhook.HeaderSignature), 5*time.Minute, time.Now()) {
+			verified++
+		}
+		assert.Equal(t, r.Header.Get(webhook.HeaderEvent), "TransferCompleted")
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	subscription := models.WebhookSubscription{Id: 1, Url: receiver.URL, Secret: secret, Active: true,
+		EventTypes: []string{"TransferCompleted"}}
+	delivery := models.WebhookDelivery{Id: 9, SubscriptionID: 1, EventType: "TransferCompleted",
+		Payload: []byte(`{"id":3,"type":"TransferCompleted"}`), Status: models.DeliveryPending}
+	mockWebhookRepo.EXPECT().GetWebhookSubscriptionById(gomock.Any(), 1).Return(subscription, nil).AnyTimes()
+	mockWebhookRepo.EXPECT().SaveWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	//first attempt fails and is scheduled again after the initial backoff
+	mockWebhookRepo.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), gomock.Any(), time.Minute, 10).Return([]models.WebhookDelivery{delivery}, nil)
+	mockWebhookRepo.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, d *models.WebhookDelivery) error {
+		assert.Equal(t, d.Status, models.DeliveryPending)
+		assert.Equal(t, d.Attempts, 1)
+		assert.Equal(t, d.LastStatusCode, 500)
+		if d.NextAttemptAt.Before(time.Now()) {
+			t.Errorf("next attempt should be in the future, got %v", d.NextAttemptAt)
+		}
+		delivery = *d
+		return nil
+	})
+	dispatcher := webhook.NewDispatcher(mockWebhookRepo, receiver.Client(), config)
+	delivered, err := dispatcher.DispatchDue(context.Background())
+	assert.Equal(t, err, nil)
+	assert.Equal(t, delivered, 0)
+
+	//second attempt succeeds
+	status = http.StatusNoContent
+	mockWebhookRepo.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), gomock.Any(), time.Minute, 10).Return([]models.WebhookDelivery{delivery}, nil)
+	mockWebhookRepo.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, d *models.WebhookDelivery) error {
+		assert.Equal(t, d.Status, models.DeliveryDelivered)
+		assert.Equal(t, d.Attempts, 2)
+		assert.NotEqual(t, d.DeliveredAt, nil)
+		return nil
+	})
+	delivered, err = dispatcher.DispatchDue(context.Background())
+	assert.Equal(t, err, nil)
+	assert.Equal(t, delivered, 1)
+	assert.Equal(t, verified, 2)
+}
+
+func TestDispatchDueGivesUpAfterMaxAttempts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockWebhookRepo := mock.NewMockWebhookRepository(mockCtrl)
+	mockLogger := mock.NewMockLogger(mockCtrl)
+	logger.SetLogger(mockLogger)
+	mockLogger.EXPECT().With(gomock.Any()).Return(mockLogger).AnyTimes()
+	mockLogger.EXPECT().Warn("webhook delivery attempt failed").AnyTimes()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	delivery := models.WebhookDelivery{Id: 9, SubscriptionID: 1, Attempts: 2, Status: models.DeliveryPending}
+	mockWebhookRepo.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), gomock.Any(), time.Minute, 10).Return([]models.WebhookDelivery{delivery}, nil)
+	mockWebhookRepo.EXPECT().GetWebhookSubscriptionById(gomock.Any(), 1).
+		Return(models.WebhookSubscription{Id: 1, Url: receiver.URL, Secret: secret, Active: true}, nil)
+	mockWebhookRepo.EXPECT().SaveWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).Return(nil)
+	mockWebhookRepo.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, d *models.WebhookDelivery) error {
+		assert.Equal(t, d.Status, models.DeliveryFailed)
+		assert.Equal(t, d.Attempts, 3)
+		return nil
+	})
+	dispatcher := webhook.NewDispatcher(mockWebhookRepo, receiver.Client(), config)
+	_, err := dispatcher.DispatchDue(context.Background())
+	assert.Equal(t, err, nil)
+}
+
+func TestBackoff(t *testing.T) {
+	dispatcher := webhook.NewDispatcher(nil, nil, config)
+	assert.Equal(t, dispatcher.Backoff(1), time.Second)
+	assert.Equal(t, dispatcher.Backoff(2), 2*time.Second)
+	assert.Equal(t, dispatcher.Backoff(3), 3*time.Second)
+	assert.Equal(t, dispatcher.Backoff(10), 3*time.Second)
+}
+
+func TestSinkFansOutToMatchingSubscriptions(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockWebhookRepo := mock.NewMockWebhookRepository(mockCtrl)
+	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockWebhookRepo.EXPECT().GetActiveWebhookSubscriptions(gomock.Any()).Return([]models.WebhookSubscription{
+		{Id: 1, EventTypes: []string{"AccountCreated"}},
+		{Id: 2, EventTypes: []string{"*"}},
+		{Id: 3, EventTypes: []string{"TransferCompleted", "BalanceAdjusted"}},
+	}, nil)
+	var subscriptionIds []int
+	mockWebhookRepo.EXPECT().SaveWebhookDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, d *models.WebhookDelivery) error {
+		assert.Equal(t, d.OutboxEventID, 12)
+		assert.Equal(t, d.Status, models.DeliveryPending)
+		subscriptionIds = append(subscriptionIds, d.SubscriptionID)
+		return nil
+	}).Times(2)
+
+	sink := webhook.NewSink(mockWebhookRepo, mockAccountRepo)
+	err := sink.Publish(context.Background(), models.OutboxEvent{Id: 12, EventType: "TransferCompleted", AggregateID: 1,
+		Payload: []byte(`{"transfer_id":5,"from_account_id":1,"to_account_id":2}`)})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, subscriptionIds, []int{2, 3})
+}
+
+func TestSinkDeliversTheEventsOfTheOwnerOnce(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockWebhookRepo := mock.NewMockWebhookRepository(mockCtrl)
+	mockAccountRepo := mock.NewMockAccountRepository(mockCtrl)
+	mockWebhookRepo.EXPECT().GetActiveWebhookSubscriptions(gomock.Any()).Return([]models.WebhookSubscription{
+		{Id: 1, EventTypes: []string{"*"}},
+		{Id: 2, EventTypes: []string{"*"}, Owner: "alice"},
+		{Id: 3, EventTypes: []string{"*"}, Owner: "bob"},
+	}, nil).AnyTimes()
+	var subscriptionIds []int
+	mockWebhookRepo.EXPECT().SaveWebhookDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, d *models.WebhookDelivery) error {
+		subscriptionIds = append(subscriptionIds, d.SubscriptionID)
+		return nil
+	}).AnyTimes()
+	sink := webhook.NewSink(mockWebhookRepo, mockAccountRepo)
+	publish := func(event models.OutboxEvent) []int {
+		subscriptionIds = nil
+		err := sink.Publish(context.Background(), event)
+		assert.Equal(t, err, nil)
+		return subscriptionIds
+	}
+
+	//the events of an account only reach the subscriptions without an owner and those of its owner
+	assert.Equal(t, publish(models.OutboxEvent{EventType: "AccountDeleted", AggregateID: 1, Owner: "alice"}), []int{1, 2})
+
+	//the copy of the sender of a transfer from alice to bob
+	transfer := []byte(`{"transfer_id":5,"from_account_id":1,"to_account_id":2}`)
+	assert.Equal(t, publish(models.OutboxEvent{EventType: "TransferCompleted", AggregateID: 1, Owner: "alice", Payload: transfer}), []int{1, 2})
+
+	//the copy of the receiver only reaches the subscriptions that did not see the sender
+	mockAccountRepo.EXPECT().GetAccountById(gomock.Any(), 1).Return(models.Account{Id: 1, Owner: "alice"}, nil)
+	assert.Equal(t, publish(models.OutboxEvent{EventType: "TransferCompleted", AggregateID: 2, Owner: "bob", Payload: transfer}), []int{3})
+
+	//a transfer between two accounts of bob reaches his subscription once
+	mockAccountRepo.EXPECT().GetAccountById(gomock.Any(), 1).Return(models.Account{Id: 1, Owner: "bob"}, nil)
+	assert.Equal(t, len(publish(models.OutboxEvent{EventType: "TransferCompleted", AggregateID: 2, Owner: "bob", Payload: transfer})), 0)
+}
+
+func TestVerifyRejectsTamperingAndStaleTimestamps(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":1}`)
+	signature := webhook.Sign(secret, now.Unix(), body)
+	assert.Equal(t, webhook.Verify(secret, "1700000000", body, signature, time.Minute, now), true)
+	assert.Equal(t, webhook.Verify(secret, "1700000000", []byte(`{"id":2}`), signature, time.Minute, now), false)
+	assert.Equal(t, webhook.Verify("whsec_other", "1700000000", body, signature, time.Minute, now), false)
+	assert.Equal(t, webhook.Verify(secret, "1700000000", body, signature, time.Minute, now.Add(2*time.Minute)), false)
+}
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned when a webhook host is, or resolves to, an address of the internal network
+var ErrForbiddenAddress = errors.New("webhook address is not allowed")
+
+// allowedIP rejects the loopback, private (RFC 1918 and fc00::/7), link-local (cloud metadata included),
+// multicast and unspecified addresses, the deliveries must not reach the network of the service
+func allowedIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// CheckHost resolves the host of a webhook URL and rejects it when any of its addresses is not allowed
+func CheckHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !allowedIP(ip) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("%w: %s cannot be resolved", ErrForbiddenAddress, host)
+	}
+	for _, addr := range addrs {
+		if !allowedIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenAddress, host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// NewClient returns the HTTP client of the dispatcher. The address is checked again when it is dialled,
+// after resolution, so that a host re-pointed to the internal network since the subscription, or a
+// redirect, cannot reach it. Proxies are not used, they would dial on behalf of the client.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_ string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !allowedIP(ip) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,35 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/webhook"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestCheckHost(t *testing.T) {
+	assert.Equal(t, webhook.CheckHost(context.Background(), "203.0.113.10"), nil)
+	for _, host := range []string{"127.0.0.1", "localhost", "169.254.169.254", "10.1.2.3", "172.31.0.1", "192.168.0.1", "::1", "fe80::1", "0.0.0.0"} {
+		if err := webhook.CheckHost(context.Background(), host); !errors.Is(err, webhook.ErrForbiddenAddress) {
+			t.Errorf("Expected ErrForbiddenAddress for %s, got %v", host, err)
+		}
+	}
+}
+
+func TestNewClientRefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request to %s", r.URL)
+	}))
+	defer server.Close()
+
+	//the check runs on the dialled address, whatever the URL says
+	_, err := webhook.NewClient(time.Second).Get(server.URL)
+	if !errors.Is(err, webhook.ErrForbiddenAddress) {
+		t.Errorf("Expected ErrForbiddenAddress, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+// Package webhook delivers outbox events to the URLs partners subscribed with
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+const signaturePrefix = "sha256="
+
+// Sign computes the signature header value over the timestamp and the raw body:
+// hex(HMAC-SHA256(secret, "<unix timestamp>.<body>")), prefixed with the algorithm
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a received delivery, receivers should reject timestamps older than tolerance
+// to limit replays. It is used by the tests and documents the scheme for partners.
+func Verify(secret string, timestampHeader string, body []byte, signature string, tolerance time.Duration, now time.Time) bool {
+	timestamp, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return false
+	}
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
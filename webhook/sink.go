@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/rahul-024/fund-transfer-poc/models"
+	"github.com/rahul-024/fund-transfer-poc/outbox"
+	"github.com/rahul-024/fund-transfer-poc/repository"
+	"gorm.io/gorm"
+)
+
+// Sink is an outbox sink that fans every event out into one pending delivery per matching
+// subscription. The dispatcher sends the deliveries, so a slow partner never holds up the outbox.
+type Sink struct {
+	webhookRepository repository.WebhookRepository
+	accountRepository repository.AccountRepository
+	now               func() time.Time
+}
+
+func NewSink(r repository.WebhookRepository, ar repository.AccountRepository) *Sink {
+	return &Sink{webhookRepository: r, accountRepository: ar, now: time.Now}
+}
+
+// Publish queues the event for the subscriptions of the type that see the account of the event.
+// TransferCompleted is published once for each account of the transfer, a subscription only receives
+// the copy of the first account of the transfer it sees, so that it gets every transfer once.
+func (s *Sink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	subscriptions, err := s.webhookRepository.GetActiveWebhookSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	var payload []byte
+	var sender *models.Account
+	for _, subscription := range subscriptions {
+		if !subscription.Matches(event.EventType) || !subscription.Sees(event.Owner) {
+			continue
+		}
+		if sender == nil {
+			if sender, err = s.sender(ctx, event); err != nil {
+				return err
+			}
+		}
+		// the subscription already received the copy of the sender
+		if sender.Id != 0 && subscription.Sees(sender.Owner) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return err
+			}
+		}
+		delivery := &models.WebhookDelivery{
+			SubscriptionID: subscription.Id,
+			OutboxEventID:  event.Id,
+			EventType:      event.EventType,
+			Payload:        payload,
+			Status:         models.DeliveryPending,
+			NextAttemptAt:  s.now(),
+		}
+		if err = s.webhookRepository.SaveWebhookDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sender returns the sender account of a transfer when event is the TransferCompleted copy of the receiver,
+// an empty account otherwise or when the sender no longer exists
+func (s *Sink) sender(ctx context.Context, event models.OutboxEvent) (*models.Account, error) {
+	if event.EventType != outbox.TransferCompleted {
+		return &models.Account{}, nil
+	}
+	var transfer outbox.TransferCompletedPayload
+	if err := json.Unmarshal(event.Payload, &transfer); err != nil {
+		return nil, err
+	}
+	if event.AggregateID != transfer.ToAccountID {
+		return &models.Account{}, nil
+	}
+	account, err := s.accountRepository.GetAccountById(ctx, transfer.FromAccountID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.Account{}, nil
+	}
+	return &account, err
+}